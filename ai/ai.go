@@ -1,8 +1,10 @@
 package ai
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"discord-bot/config"
 )
@@ -14,36 +16,47 @@ type AIProvider interface {
 }
 
 var AvailableProviders = map[string]AIProvider{
-	"gemini":  &GeminiProvider{},
-	"grok":    &GrokProvider{},
-	"chatgpt": &ChatGPTProvider{},
-	"qwen":    &QwenProvider{},
-	"claude":  &ClaudeProvider{},
+	"gemini":            &GeminiProvider{},
+	"grok":              &GrokProvider{},
+	"chatgpt":           &ChatGPTProvider{},
+	"qwen":              &QwenProvider{},
+	"claude":            &ClaudeProvider{},
+	"openai_compatible": &OpenAICompatibleProvider{},
 }
 
 var DefaultProvider AIProvider
 
 func Initialize() error {
-	if _, err := config.Load(); err != nil {
+	cfg, err := config.Load()
+	if err != nil {
 		return fmt.Errorf("ошибка загрузки конфигурации: %w", err)
 	}
 
+	initialized := make(map[string]bool)
 	for name, provider := range AvailableProviders {
-		err := provider.Initialize()
-		if err == nil {
-			if DefaultProvider == nil {
-				DefaultProvider = provider
-				fmt.Printf("Установлен провайдер AI по умолчанию: %s\n", provider.GetName())
-			}
-		} else {
+		if err := provider.Initialize(); err != nil {
 			fmt.Printf("Ошибка инициализации провайдера %s: %v\n", name, err)
+			continue
 		}
+		initialized[name] = true
 	}
 
-	if DefaultProvider == nil {
+	if cfg.DefaultAI != "" && !initialized[cfg.DefaultAI] {
+		fmt.Printf("Провайдер AI по умолчанию %s недоступен, будет использован следующий в цепочке\n", cfg.DefaultAI)
+	}
+
+	names := buildChain(cfg.DefaultAI, cfg.AIFallback.Chain, initialized)
+	if len(names) == 0 {
 		return errors.New("не удалось инициализировать ни один провайдер AI")
 	}
 
+	providerChain = newFallbackChain(names, cfg.AIFallback)
+	DefaultProvider = AvailableProviders[names[0]]
+	fmt.Printf("Установлен провайдер AI по умолчанию: %s\n", DefaultProvider.GetName())
+	if len(names) > 1 {
+		fmt.Printf("Резервные провайдеры AI: %s\n", strings.Join(names[1:], ", "))
+	}
+
 	return nil
 }
 
@@ -56,8 +69,85 @@ func GetProvider(name string) (AIProvider, error) {
 }
 
 func GenerateResponse(prompt string) (string, error) {
+	response, err := Generate(context.Background(), promptRequest(prompt))
+	return response.Text, err
+}
+
+// GetProviderV2 возвращает провайдер по имени в виде AIProviderV2
+func GetProviderV2(name string) (AIProviderV2, error) {
+	provider, err := GetProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	return Adapt(provider), nil
+}
+
+// Generate генерирует ответ с учетом контекста, перебирая цепочку провайдеров
+// до первого успешного ответа
+func Generate(ctx context.Context, req Request) (Response, error) {
+	if DefaultProvider == nil {
+		return Response{}, errors.New("провайдер AI не инициализирован")
+	}
+	return providerChain.generate(ctx, providerChain.names, req)
+}
+
+// Stream генерирует ответ в потоковом режиме, перебирая цепочку провайдеров
+// до первого, начавшего ответ
+func Stream(ctx context.Context, req Request) (<-chan Chunk, error) {
+	if DefaultProvider == nil {
+		return nil, errors.New("провайдер AI не инициализирован")
+	}
+	return providerChain.stream(ctx, providerChain.names, req)
+}
+
+// StreamPreferring генерирует ответ в потоковом режиме, начиная с указанного провайдера.
+// При его недоступности запрос переходит к остальным провайдерам цепочки.
+func StreamPreferring(ctx context.Context, name string, req Request) (<-chan Chunk, error) {
 	if DefaultProvider == nil {
-		return "", errors.New("провайдер AI не инициализирован")
+		return nil, errors.New("провайдер AI не инициализирован")
 	}
-	return DefaultProvider.GenerateResponse(prompt)
+	return providerChain.stream(ctx, preferProvider(providerChain.names, name), req)
+}
+
+// GenerateWith генерирует ответ указанным провайдером без переключения на резервные
+func GenerateWith(ctx context.Context, name string, req Request) (Response, error) {
+	return providerChain.generate(ctx, []string{name}, req)
+}
+
+// StreamWith генерирует ответ указанным провайдером в потоковом режиме без переключения на резервные
+func StreamWith(ctx context.Context, name string, req Request) (<-chan Chunk, error) {
+	return providerChain.stream(ctx, []string{name}, req)
+}
+
+// providerSettings возвращает настройки провайдера из конфигурации,
+// подставляя значения по умолчанию для незаполненных полей
+func providerSettings(cfg *config.Config, name string, defaults config.AIProviderConfig) config.AIProviderConfig {
+	settings, ok := cfg.AIProviders[name]
+	if !ok {
+		return defaults
+	}
+
+	if settings.BaseURL == "" {
+		settings.BaseURL = defaults.BaseURL
+	}
+	if settings.Model == "" {
+		settings.Model = defaults.Model
+	}
+	if settings.Temperature == nil {
+		settings.Temperature = defaults.Temperature
+	}
+	if settings.MaxTokens == 0 {
+		settings.MaxTokens = defaults.MaxTokens
+	}
+	if settings.SystemPrompt == "" {
+		settings.SystemPrompt = defaults.SystemPrompt
+	}
+	if len(settings.StopSequences) == 0 {
+		settings.StopSequences = defaults.StopSequences
+	}
+	if settings.Vision == nil {
+		settings.Vision = defaults.Vision
+	}
+
+	return settings
 }
@@ -0,0 +1,334 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"discord-bot/config"
+)
+
+// anthropicVersion задает версию Messages API, передаваемую в заголовке
+const anthropicVersion = "2023-06-01"
+
+// claudeDefaults содержит настройки Claude по умолчанию
+var claudeDefaults = config.AIProviderConfig{
+	BaseURL:     "https://api.anthropic.com",
+	Model:       "claude-3-5-sonnet-latest",
+	Temperature: floatPtr(0.7),
+	MaxTokens:   1024,
+	Vision:      boolPtr(true),
+}
+
+// ClaudeProvider реализует интерфейс AIProvider для Claude
+type ClaudeProvider struct {
+	apiKey      string
+	settings    config.AIProviderConfig
+	client      *http.Client
+	initialized bool
+}
+
+// claudeRequest представляет тело запроса к Messages API
+type claudeRequest struct {
+	Model         string          `json:"model"`
+	MaxTokens     int             `json:"max_tokens"`
+	System        string          `json:"system,omitempty"`
+	Messages      []claudeMessage `json:"messages"`
+	Temperature   float64         `json:"temperature"`
+	StopSequences []string        `json:"stop_sequences,omitempty"`
+	Stream        bool            `json:"stream,omitempty"`
+}
+
+// claudeMessage представляет сообщение запроса. Content - строка
+// или список блоков, если к сообщению приложены изображения.
+type claudeMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// claudeContentBlock представляет блок содержимого сообщения с изображениями
+type claudeContentBlock struct {
+	Type   string             `json:"type"`
+	Text   string             `json:"text,omitempty"`
+	Source *claudeImageSource `json:"source,omitempty"`
+}
+
+// claudeImageSource содержит изображение в кодировке base64
+type claudeImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+// claudeResponse представляет ответ Messages API
+type claudeResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// claudeStreamEvent представляет событие потокового ответа Messages API
+type claudeStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Model string `json:"model"`
+		Usage struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// claudeErrorResponse представляет ошибку Messages API
+type claudeErrorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Initialize инициализирует клиент Claude API
+func (p *ClaudeProvider) Initialize() error {
+	// Загружаем конфигурацию
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки конфигурации: %w", err)
+	}
+
+	// Проверяем наличие API ключа
+	if cfg.ClaudeAPIKey == "" {
+		return fmt.Errorf("API ключ Claude не указан в конфигурации")
+	}
+
+	p.apiKey = cfg.ClaudeAPIKey
+	p.settings = providerSettings(cfg, "claude", claudeDefaults)
+	p.client = newHTTPClient()
+	p.initialized = true
+	return nil
+}
+
+// GenerateResponse генерирует ответ на запрос пользователя
+func (p *ClaudeProvider) GenerateResponse(prompt string) (string, error) {
+	response, err := p.Generate(context.Background(), promptRequest(prompt))
+	return response.Text, err
+}
+
+// Generate генерирует ответ с учетом контекста и истории диалога
+func (p *ClaudeProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	if !p.initialized {
+		return Response{}, fmt.Errorf("сервис Claude не инициализирован")
+	}
+	if hasImages(req) && !p.SupportsImages() {
+		return Response{}, imagesUnsupported(p.GetName())
+	}
+
+	status, body, err := postJSON(ctx, p.client, p.endpoint(), p.headers(), p.buildRequest(req, false))
+	if err != nil {
+		return Response{}, fmt.Errorf("ошибка запроса к Claude: %w", err)
+	}
+
+	if status != http.StatusOK {
+		return Response{}, p.parseError(status, body)
+	}
+
+	var response claudeResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Response{}, fmt.Errorf("ошибка декодирования ответа Claude: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+
+	if text.Len() == 0 {
+		return Response{}, fmt.Errorf("Claude вернул пустой ответ (%s)", response.StopReason)
+	}
+
+	return Response{
+		Text:         text.String(),
+		Model:        response.Model,
+		FinishReason: response.StopReason,
+		Usage: Usage{
+			PromptTokens:     response.Usage.InputTokens,
+			CompletionTokens: response.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// Stream генерирует ответ в потоковом режиме
+func (p *ClaudeProvider) Stream(ctx context.Context, req Request) (<-chan Chunk, error) {
+	if !p.initialized {
+		return nil, fmt.Errorf("сервис Claude не инициализирован")
+	}
+	if hasImages(req) && !p.SupportsImages() {
+		return nil, imagesUnsupported(p.GetName())
+	}
+
+	resp, errBody, err := openStream(ctx, p.client, p.endpoint(), p.headers(), p.buildRequest(req, true))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к Claude: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.parseError(resp.StatusCode, errBody)
+	}
+
+	chunks := make(chan Chunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+
+		final := Chunk{Done: true, Model: p.settings.Model}
+		done := false
+		err := readSSE(resp.Body, func(event, data string) error {
+			// Ошибки посреди потока (например, overloaded_error) приходят отдельным событием
+			if event == "error" {
+				return p.parseError(0, []byte(data))
+			}
+
+			var streamEvent claudeStreamEvent
+			if err := json.Unmarshal([]byte(data), &streamEvent); err != nil {
+				return fmt.Errorf("ошибка декодирования фрагмента Claude: %w", err)
+			}
+
+			switch streamEvent.Type {
+			case "message_start":
+				final.Model = streamEvent.Message.Model
+				final.Usage.PromptTokens = streamEvent.Message.Usage.InputTokens
+			case "content_block_delta":
+				if streamEvent.Delta.Type == "text_delta" && !sendChunk(ctx, chunks, Chunk{Text: streamEvent.Delta.Text}) {
+					return ctx.Err()
+				}
+			case "message_delta":
+				final.FinishReason = streamEvent.Delta.StopReason
+				final.Usage.CompletionTokens = streamEvent.Usage.OutputTokens
+			case "message_stop":
+				done = true
+				return errStreamDone
+			}
+			return nil
+		})
+		// Поток, оборванный до message_stop, - незавершенный ответ, а не успех
+		if err == nil && !done {
+			err = fmt.Errorf("%s: %w", p.GetName(), ErrStreamInterrupted)
+		}
+		if err != nil {
+			sendChunk(ctx, chunks, Chunk{Err: err})
+			return
+		}
+		sendChunk(ctx, chunks, final)
+	}()
+
+	return chunks, nil
+}
+
+// buildRequest формирует тело запроса Messages API
+func (p *ClaudeProvider) buildRequest(req Request, stream bool) claudeRequest {
+	req = withDefaults(req, p.settings)
+
+	messages := make([]claudeMessage, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		messages = append(messages, newClaudeMessage(msg))
+	}
+
+	return claudeRequest{
+		Model:         p.settings.Model,
+		MaxTokens:     req.MaxTokens,
+		System:        req.System,
+		Messages:      messages,
+		Temperature:   *req.Temperature,
+		StopSequences: p.settings.StopSequences,
+		Stream:        stream,
+	}
+}
+
+// newClaudeMessage преобразует сообщение диалога в формат Messages API.
+// Изображения передаются перед текстом, как рекомендует документация Anthropic.
+func newClaudeMessage(msg Message) claudeMessage {
+	if len(msg.Images) == 0 {
+		return claudeMessage{Role: msg.Role, Content: msg.Content}
+	}
+
+	var blocks []claudeContentBlock
+	for _, image := range msg.Images {
+		blocks = append(blocks, claudeContentBlock{
+			Type: "image",
+			Source: &claudeImageSource{
+				Type:      "base64",
+				MediaType: image.MIMEType,
+				Data:      base64.StdEncoding.EncodeToString(image.Data),
+			},
+		})
+	}
+	blocks = append(blocks, claudeContentBlock{Type: "text", Text: msg.Content})
+	return claudeMessage{Role: msg.Role, Content: blocks}
+}
+
+// SupportsImages сообщает, принимает ли модель изображения
+func (p *ClaudeProvider) SupportsImages() bool {
+	return visionEnabled(p.settings)
+}
+
+// endpoint возвращает адрес Messages API
+func (p *ClaudeProvider) endpoint() string {
+	return strings.TrimRight(p.settings.BaseURL, "/") + "/v1/messages"
+}
+
+// headers возвращает заголовки авторизации и версии API
+func (p *ClaudeProvider) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+}
+
+// parseError преобразует ответ с ошибкой в ProviderError
+func (p *ClaudeProvider) parseError(status int, body []byte) error {
+	providerErr := &ProviderError{
+		Provider:   p.GetName(),
+		StatusCode: status,
+	}
+
+	var errResponse claudeErrorResponse
+	if err := json.Unmarshal(body, &errResponse); err == nil {
+		providerErr.Code = errResponse.Error.Type
+		providerErr.Message = errResponse.Error.Message
+	}
+	if providerErr.Message == "" {
+		providerErr.Message = http.StatusText(status)
+	}
+
+	// overloaded_error и rate_limit_error - временные ошибки, запрос можно повторить
+	switch providerErr.Code {
+	case "overloaded_error":
+		providerErr.Kind = ErrOverloaded
+	case "rate_limit_error":
+		providerErr.Kind = ErrRateLimited
+	}
+
+	return providerErr
+}
+
+// GetName возвращает название AI модели
+func (p *ClaudeProvider) GetName() string {
+	return "Claude"
+}
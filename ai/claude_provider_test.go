@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestClaude создает провайдер Claude, направленный на тестовый сервер
+func newTestClaude(t *testing.T, handler http.HandlerFunc) *ClaudeProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	settings := claudeDefaults
+	settings.BaseURL = server.URL
+	settings.SystemPrompt = "Ты - Lapidar"
+	settings.StopSequences = []string{"###"}
+	return &ClaudeProvider{
+		apiKey:      "test-key",
+		settings:    settings,
+		client:      server.Client(),
+		initialized: true,
+	}
+}
+
+func TestClaudeGenerateResponse(t *testing.T) {
+	p := newTestClaude(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("Неожиданный путь запроса: %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("Не переданы заголовки авторизации или версии API")
+		}
+
+		var request claudeRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Ошибка декодирования запроса: %v", err)
+		}
+		if request.System != "Ты - Lapidar" || request.MaxTokens == 0 || len(request.StopSequences) != 1 {
+			t.Errorf("Параметры запроса не переданы: %+v", request)
+		}
+
+		w.Write([]byte(`{"model":"claude","content":[{"type":"text","text":"Привет!"}],"stop_reason":"end_turn"}`))
+	})
+
+	response, err := p.GenerateResponse("Привет")
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if response != "Привет!" {
+		t.Errorf("Неверный ответ: %q", response)
+	}
+}
+
+func TestClaudeRetryableErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		kind      error
+		retryable bool
+	}{
+		{"overloaded", 529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, ErrOverloaded, true},
+		{"rate limit", 429, `{"type":"error","error":{"type":"rate_limit_error","message":"Rate limited"}}`, ErrRateLimited, true},
+		{"invalid request", 400, `{"type":"error","error":{"type":"invalid_request_error","message":"Bad"}}`, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestClaude(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := p.GenerateResponse("запрос")
+			if err == nil {
+				t.Fatal("Ожидалась ошибка")
+			}
+			if tt.kind != nil && !errors.Is(err, tt.kind) {
+				t.Errorf("Ожидалась ошибка %v, получено: %v", tt.kind, err)
+			}
+			if IsRetryable(err) != tt.retryable {
+				t.Errorf("IsRetryable = %v, ожидалось %v", IsRetryable(err), tt.retryable)
+			}
+		})
+	}
+}
+
+func TestClaudeStream(t *testing.T) {
+	p := newTestClaude(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`event: message_start
+data: {"type":"message_start","message":{"model":"claude-test","usage":{"input_tokens":12}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"Привет"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","delta":{"type":"text_delta","text":", мир"}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`))
+	})
+
+	chunks, err := p.Stream(context.Background(), promptRequest("Привет"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+
+	text, final := collectChunks(t, chunks)
+	if text != "Привет, мир" {
+		t.Errorf("Неверный текст потока: %q", text)
+	}
+	if final.Model != "claude-test" || final.FinishReason != "end_turn" ||
+		final.Usage.PromptTokens != 12 || final.Usage.CompletionTokens != 5 {
+		t.Errorf("Неверный завершающий фрагмент: %+v", final)
+	}
+}
+
+func TestClaudeStreamWithoutMessageStop(t *testing.T) {
+	p := newTestClaude(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`event: message_start
+data: {"type":"message_start","message":{"model":"claude-test","usage":{"input_tokens":12}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"Привет"}}
+
+`))
+	})
+
+	chunks, err := p.Stream(context.Background(), promptRequest("Привет"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if err := streamError(t, chunks); !errors.Is(err, ErrStreamInterrupted) {
+		t.Errorf("Поток без message_stop должен завершаться ошибкой обрыва, получено %v", err)
+	}
+}
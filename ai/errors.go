@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Категории ошибок AI провайдеров. Проверяются через errors.Is.
+var (
+	ErrSafetyBlocked = errors.New("ответ заблокирован фильтрами безопасности")
+	ErrQuotaExceeded = errors.New("превышена квота API")
+	ErrRateLimited   = errors.New("превышен лимит запросов к API")
+	ErrOverloaded    = errors.New("сервис AI перегружен")
+
+	// ErrImagesUnsupported - модель провайдера не принимает изображения
+	ErrImagesUnsupported = errors.New("модель не поддерживает изображения")
+
+	// ErrStreamInterrupted - провайдер закрыл поток, не завершив ответ
+	ErrStreamInterrupted = errors.New("поток ответа прерван провайдером")
+
+	// ErrProviderUnavailable - провайдер временно отключен после серии ошибок
+	ErrProviderUnavailable = errors.New("провайдер временно отключен после серии ошибок")
+)
+
+// ProviderError описывает ошибку, полученную от API провайдера
+type ProviderError struct {
+	Provider   string // Название провайдера
+	StatusCode int    // HTTP статус ответа (0, если ошибка не связана с HTTP)
+	Code       string // Код или тип ошибки из ответа API
+	Message    string // Сообщение об ошибке из ответа API
+	Kind       error  // Категория ошибки (ErrSafetyBlocked, ErrQuotaExceeded и т.д.)
+}
+
+// Error возвращает текстовое описание ошибки
+func (e *ProviderError) Error() string {
+	msg := e.Message
+	if msg == "" && e.Kind != nil {
+		msg = e.Kind.Error()
+	}
+	if e.Code != "" {
+		return fmt.Sprintf("%s: %s (%s)", e.Provider, msg, e.Code)
+	}
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s: %s (HTTP %d)", e.Provider, msg, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s", e.Provider, msg)
+}
+
+// Unwrap позволяет сравнивать ошибку с категорией через errors.Is
+func (e *ProviderError) Unwrap() error {
+	return e.Kind
+}
+
+// IsRetryable сообщает, является ли ошибка временной. Такой запрос имеет смысл
+// повторить позже, в отличие от постоянных ошибок (неверный ключ, фильтры и т.д.)
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrOverloaded) ||
+		errors.Is(err, ErrProviderUnavailable) || errors.Is(err, ErrStreamInterrupted) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.StatusCode >= 500
+	}
+
+	return false
+}
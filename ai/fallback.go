@@ -0,0 +1,386 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"discord-bot/config"
+)
+
+// Значения по умолчанию для цепочки резервных провайдеров
+const (
+	defaultFailureThreshold = 3
+	defaultBreakerCooldown  = 60 * time.Second
+	defaultAttemptTimeout   = 45 * time.Second
+)
+
+// providerOrder задает порядок провайдеров, не перечисленных в цепочке явно
+var providerOrder = []string{"gemini", "claude", "chatgpt", "grok", "qwen", "openai_compatible"}
+
+// circuitBreaker отслеживает состояние провайдера. После серии ошибок подряд
+// провайдер временно исключается из цепочки, а по истечении паузы получает пробный запрос.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	threshold int
+	cooldown  time.Duration
+}
+
+// allow сообщает, можно ли отправлять запросы провайдеру
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.openUntil)
+}
+
+// success сбрасывает счетчик ошибок после успешного ответа
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+// failure учитывает ошибку и при достижении порога отключает провайдера.
+// Неудачный пробный запрос после паузы сразу отключает провайдера снова.
+func (b *circuitBreaker) failure(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
+
+// fallbackChain перебирает провайдеры по порядку, пока один из них не ответит
+type fallbackChain struct {
+	mu             sync.Mutex
+	names          []string
+	breakers       map[string]*circuitBreaker
+	threshold      int
+	cooldown       time.Duration
+	attemptTimeout time.Duration
+	now            func() time.Time
+}
+
+// providerChain - цепочка провайдеров, построенная при инициализации
+var providerChain = newFallbackChain(nil, config.AIFallbackConfig{})
+
+// newFallbackChain создает цепочку с настройками из конфигурации
+func newFallbackChain(names []string, settings config.AIFallbackConfig) *fallbackChain {
+	chain := &fallbackChain{
+		names:          names,
+		breakers:       make(map[string]*circuitBreaker),
+		threshold:      defaultFailureThreshold,
+		cooldown:       defaultBreakerCooldown,
+		attemptTimeout: defaultAttemptTimeout,
+		now:            time.Now,
+	}
+	if settings.FailureThreshold > 0 {
+		chain.threshold = settings.FailureThreshold
+	}
+	if settings.CooldownSeconds > 0 {
+		chain.cooldown = time.Duration(settings.CooldownSeconds) * time.Second
+	}
+	if settings.AttemptTimeoutSeconds > 0 {
+		chain.attemptTimeout = time.Duration(settings.AttemptTimeoutSeconds) * time.Second
+	}
+	return chain
+}
+
+// buildChain формирует порядок опроса провайдеров: сначала default_ai, затем цепочка
+// из конфигурации, затем остальные инициализированные провайдеры
+func buildChain(defaultAI string, configured []string, initialized map[string]bool) []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if initialized[name] && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	add(defaultAI)
+	for _, name := range configured {
+		add(name)
+	}
+	for _, name := range providerOrder {
+		add(name)
+	}
+
+	// Провайдеры, отсутствующие в providerOrder, добавляем в алфавитном порядке
+	var rest []string
+	for name := range initialized {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		add(name)
+	}
+
+	return names
+}
+
+// preferProvider переносит указанный провайдер в начало цепочки.
+// Провайдер, отсутствующий в цепочке (не инициализирован), не добавляется.
+func preferProvider(names []string, preferred string) []string {
+	ordered := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == preferred {
+			ordered = append(ordered, name)
+		}
+	}
+	for _, name := range names {
+		if name != preferred {
+			ordered = append(ordered, name)
+		}
+	}
+	return ordered
+}
+
+// Chain возвращает порядок опроса провайдеров
+func Chain() []string {
+	return append([]string(nil), providerChain.names...)
+}
+
+// breaker возвращает состояние указанного провайдера
+func (c *fallbackChain) breaker(name string) *circuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.breakers[name]
+	if !ok {
+		b = &circuitBreaker{threshold: c.threshold, cooldown: c.cooldown}
+		c.breakers[name] = b
+	}
+	return b
+}
+
+// candidates возвращает провайдеры, к которым можно обратиться с запросом.
+// Запросы с изображениями получают только провайдеры с их поддержкой.
+// Единственный провайдер (явный выбор модели пользователем) опрашивается независимо от состояния.
+func (c *fallbackChain) candidates(names []string, req Request) ([]string, []error) {
+	withImages := hasImages(req)
+
+	var available []string
+	var skipped []error
+	for _, name := range names {
+		switch {
+		case withImages && !supportsImages(AvailableProviders[name]):
+			skipped = append(skipped, fmt.Errorf("%s: %w", name, ErrImagesUnsupported))
+		case len(names) > 1 && !c.breaker(name).allow(c.now()):
+			skipped = append(skipped, fmt.Errorf("%s: %w", name, ErrProviderUnavailable))
+		default:
+			available = append(available, name)
+		}
+	}
+	return available, skipped
+}
+
+// generate запрашивает ответ у провайдеров по очереди
+func (c *fallbackChain) generate(ctx context.Context, names []string, req Request) (Response, error) {
+	available, errs := c.candidates(names, req)
+
+	for _, name := range available {
+		provider, err := GetProviderV2(name)
+		if err != nil {
+			return Response{}, err
+		}
+
+		start := c.now()
+		attemptCtx, cancel := context.WithTimeout(ctx, c.attemptTimeout)
+		response, err := provider.Generate(attemptCtx, req)
+		cancel()
+		c.record(ctx, name, start, response.Model, response.Usage, err)
+
+		if err == nil {
+			c.breaker(name).success()
+			response.Provider = provider.GetName()
+			return response, nil
+		}
+		if !c.shouldFailover(ctx, name, err) {
+			return Response{}, err
+		}
+		errs = append(errs, err)
+	}
+
+	return Response{}, chainError(errs)
+}
+
+// stream открывает поток ответа у первого провайдера, успевшего начать ответ.
+// Переключение возможно только до получения первого фрагмента, а результат попытки
+// учитывается в состоянии провайдера по окончании потока.
+func (c *fallbackChain) stream(ctx context.Context, names []string, req Request) (<-chan Chunk, error) {
+	available, errs := c.candidates(names, req)
+
+	for _, name := range available {
+		provider, err := GetProviderV2(name)
+		if err != nil {
+			return nil, err
+		}
+
+		start := c.now()
+		attemptCtx, cancel := context.WithCancel(ctx)
+		first, chunks, err := c.openStream(attemptCtx, provider, req)
+		if err == nil {
+			finish := func(final Chunk, err error) {
+				c.record(ctx, name, start, final.Model, final.Usage, err)
+				switch {
+				case err == nil:
+					c.breaker(name).success()
+				case ctx.Err() == nil && isProviderFailure(err):
+					// Обрыв потока после начала ответа - сбой провайдера, хотя переключиться уже нельзя
+					c.breaker(name).failure(c.now())
+				}
+			}
+			return forwardStream(attemptCtx, cancel, provider.GetName(), first, chunks, finish), nil
+		}
+		cancel()
+		c.record(ctx, name, start, "", Usage{}, err)
+
+		if !c.shouldFailover(ctx, name, err) {
+			return nil, err
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, chainError(errs)
+}
+
+// openStream открывает поток и дожидается первого фрагмента в пределах времени попытки
+func (c *fallbackChain) openStream(ctx context.Context, provider AIProviderV2, req Request) (Chunk, <-chan Chunk, error) {
+	chunks, err := provider.Stream(ctx, req)
+	if err != nil {
+		return Chunk{}, nil, err
+	}
+
+	timer := time.NewTimer(c.attemptTimeout)
+	defer timer.Stop()
+
+	select {
+	case first, ok := <-chunks:
+		if !ok {
+			// Поток закрыт без фрагментов - провайдер прервал ответ, пробуем следующий
+			return Chunk{}, nil, streamInterrupted(ctx)
+		}
+		if first.Err != nil {
+			return Chunk{}, nil, first.Err
+		}
+		return first, chunks, nil
+	case <-timer.C:
+		return Chunk{}, nil, fmt.Errorf("%s не начал ответ за %v: %w", provider.GetName(), c.attemptTimeout, context.DeadlineExceeded)
+	case <-ctx.Done():
+		return Chunk{}, nil, ctx.Err()
+	}
+}
+
+// forwardStream передает фрагменты потока, указывая провайдера в завершающем фрагменте.
+// По окончании потока вызывает finish с завершающим фрагментом или ошибкой.
+func forwardStream(ctx context.Context, cancel context.CancelFunc, provider string, first Chunk, chunks <-chan Chunk, finish func(final Chunk, err error)) <-chan Chunk {
+	out := make(chan Chunk)
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		chunk := first
+		for {
+			if chunk.Done {
+				chunk.Provider = provider
+			}
+			if !sendChunk(ctx, out, chunk) {
+				finish(Chunk{}, ctx.Err())
+				return
+			}
+			if chunk.Done || chunk.Err != nil {
+				finish(chunk, chunk.Err)
+				return
+			}
+			if chunks == nil {
+				finish(Chunk{}, nil)
+				return
+			}
+
+			next, ok := <-chunks
+			if !ok {
+				finish(Chunk{}, streamInterrupted(ctx))
+				return
+			}
+			chunk = next
+		}
+	}()
+	return out
+}
+
+// streamInterrupted возвращает причину закрытия потока без завершающего фрагмента
+func streamInterrupted(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return ErrStreamInterrupted
+}
+
+// record передает сведения о попытке обращения к провайдеру в учет использования
+func (c *fallbackChain) record(ctx context.Context, name string, start time.Time, model string, usage Usage, err error) {
+	recordUsage(ctx, UsageRecord{
+		Provider: name,
+		Model:    model,
+		Latency:  c.now().Sub(start),
+		Usage:    usage,
+		Err:      err,
+		Time:     start,
+	})
+}
+
+// shouldFailover решает, переключаться ли на следующий провайдер после ошибки.
+// Сбои провайдера (таймауты, лимиты, недоступность) учитываются в его состоянии.
+func (c *fallbackChain) shouldFailover(ctx context.Context, name string, err error) bool {
+	// Запрос отменен вызывающей стороной - продолжать бессмысленно
+	if ctx.Err() != nil {
+		return false
+	}
+	if !isProviderFailure(err) {
+		return false
+	}
+
+	c.breaker(name).failure(c.now())
+	fmt.Printf("Провайдер AI %s не ответил, переключаемся на следующий: %v\n", name, err)
+	return true
+}
+
+// isProviderFailure отличает сбои провайдера от ошибок, связанных с самим запросом
+func isProviderFailure(err error) bool {
+	if errors.Is(err, ErrSafetyBlocked) || errors.Is(err, ErrImagesUnsupported) {
+		return false
+	}
+	if IsRetryable(err) || errors.Is(err, ErrQuotaExceeded) {
+		return true
+	}
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		// Неверный или отозванный ключ - провайдер неработоспособен
+		return providerErr.StatusCode == 401 || providerErr.StatusCode == 403
+	}
+
+	// Сетевые ошибки и ошибки разбора ответа
+	return true
+}
+
+// chainError объединяет ошибки всех опрошенных провайдеров
+func chainError(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return errors.New("провайдер AI не инициализирован")
+	case 1:
+		return errs[0]
+	default:
+		return fmt.Errorf("все провайдеры AI недоступны: %w", errors.Join(errs...))
+	}
+}
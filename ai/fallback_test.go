@@ -0,0 +1,269 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"discord-bot/config"
+)
+
+// chainStub - провайдер для проверки цепочки, отвечающий заданной ошибкой или текстом
+type chainStub struct {
+	name      string
+	err       error
+	streamErr error // Ошибка, которой обрывается поток после первого фрагмента
+	empty     bool  // Поток закрывается без фрагментов
+	calls     int
+}
+
+func (p *chainStub) Initialize() error { return nil }
+func (p *chainStub) GetName() string   { return p.name }
+func (p *chainStub) GenerateResponse(string) (string, error) {
+	return "", errors.New("не используется")
+}
+
+func (p *chainStub) Generate(ctx context.Context, req Request) (Response, error) {
+	p.calls++
+	if p.err != nil {
+		return Response{}, p.err
+	}
+	return Response{Text: "ответ " + p.name, Model: p.name + "-model"}, nil
+}
+
+func (p *chainStub) Stream(ctx context.Context, req Request) (<-chan Chunk, error) {
+	response, err := p.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make(chan Chunk, 2)
+	if p.empty {
+		close(chunks)
+		return chunks, nil
+	}
+	chunks <- Chunk{Text: response.Text}
+	if p.streamErr != nil {
+		chunks <- Chunk{Err: p.streamErr}
+	} else {
+		chunks <- Chunk{Done: true, Model: response.Model}
+	}
+	close(chunks)
+	return chunks, nil
+}
+
+// useChainStubs временно подменяет доступные провайдеры заглушками
+func useChainStubs(t *testing.T, stubs ...*chainStub) *fallbackChain {
+	t.Helper()
+	saved := AvailableProviders
+	AvailableProviders = map[string]AIProvider{}
+	var names []string
+	for _, stub := range stubs {
+		AvailableProviders[stub.name] = stub
+		names = append(names, stub.name)
+	}
+	t.Cleanup(func() { AvailableProviders = saved })
+
+	return newFallbackChain(names, config.AIFallbackConfig{FailureThreshold: 2, CooldownSeconds: 60})
+}
+
+func TestBuildChainHonoursDefault(t *testing.T) {
+	initialized := map[string]bool{"gemini": true, "claude": true, "qwen": true, "custom": true}
+
+	got := buildChain("qwen", []string{"claude", "grok"}, initialized)
+	want := []string{"qwen", "claude", "gemini", "custom"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Неверный порядок цепочки: %v, ожидалось %v", got, want)
+	}
+}
+
+func TestFallbackOnRateLimit(t *testing.T) {
+	first := &chainStub{name: "first", err: &ProviderError{Provider: "first", StatusCode: 429, Kind: ErrRateLimited}}
+	second := &chainStub{name: "second"}
+	chain := useChainStubs(t, first, second)
+
+	response, err := chain.generate(context.Background(), chain.names, promptRequest("вопрос"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if response.Text != "ответ second" || response.Provider != "second" {
+		t.Errorf("Ответ должен прийти от резервного провайдера: %+v", response)
+	}
+}
+
+func TestFallbackStreamReportsProvider(t *testing.T) {
+	first := &chainStub{name: "first", err: context.DeadlineExceeded}
+	second := &chainStub{name: "second"}
+	chain := useChainStubs(t, first, second)
+
+	chunks, err := chain.stream(context.Background(), chain.names, promptRequest("вопрос"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	text, final := collectChunks(t, chunks)
+	if text != "ответ second" || final.Provider != "second" || final.Model != "second-model" {
+		t.Errorf("Неверный поток резервного провайдера: %q, %+v", text, final)
+	}
+}
+
+func TestFallbackStopsOnSafetyBlock(t *testing.T) {
+	first := &chainStub{name: "first", err: &ProviderError{Provider: "first", Kind: ErrSafetyBlocked}}
+	second := &chainStub{name: "second"}
+	chain := useChainStubs(t, first, second)
+
+	_, err := chain.generate(context.Background(), chain.names, promptRequest("вопрос"))
+	if !errors.Is(err, ErrSafetyBlocked) || second.calls != 0 {
+		t.Errorf("Блокировка фильтрами не должна переключать провайдера: %v, вызовов: %d", err, second.calls)
+	}
+}
+
+func TestCircuitBreakerSkipsFailingProvider(t *testing.T) {
+	first := &chainStub{name: "first", err: &ProviderError{Provider: "first", StatusCode: 503}}
+	second := &chainStub{name: "second"}
+	chain := useChainStubs(t, first, second)
+
+	now := time.Unix(0, 0)
+	chain.now = func() time.Time { return now }
+
+	for n := 0; n < 3; n++ {
+		if _, err := chain.generate(context.Background(), chain.names, promptRequest("вопрос")); err != nil {
+			t.Fatalf("Неожиданная ошибка: %v", err)
+		}
+	}
+	if first.calls != 2 {
+		t.Errorf("После двух ошибок провайдер должен быть отключен, вызовов: %d", first.calls)
+	}
+
+	// По истечении паузы провайдер получает пробный запрос
+	now = now.Add(2 * time.Minute)
+	first.err = nil
+	response, err := chain.generate(context.Background(), chain.names, promptRequest("вопрос"))
+	if err != nil || response.Provider != "first" {
+		t.Errorf("Провайдер должен вернуться в цепочку после паузы: %+v, %v", response, err)
+	}
+}
+
+func TestStreamErrorTripsCircuitBreaker(t *testing.T) {
+	first := &chainStub{name: "first", streamErr: &ProviderError{Provider: "first", StatusCode: 503}}
+	second := &chainStub{name: "second"}
+	chain := useChainStubs(t, first, second)
+
+	for n := 0; n < 2; n++ {
+		chunks, err := chain.stream(context.Background(), chain.names, promptRequest("вопрос"))
+		if err != nil {
+			t.Fatalf("Неожиданная ошибка: %v", err)
+		}
+		for chunk := range chunks {
+			if chunk.Done {
+				t.Fatalf("Оборванный поток не должен завершаться успешно: %+v", chunk)
+			}
+		}
+	}
+
+	if chain.breaker("first").allow(chain.now()) {
+		t.Error("Ошибки посреди потока должны отключать провайдера")
+	}
+	chunks, err := chain.stream(context.Background(), chain.names, promptRequest("вопрос"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if _, final := collectChunks(t, chunks); final.Provider != "second" || first.calls != 2 {
+		t.Errorf("Отключенный провайдер не должен получать запросы: %+v, вызовов: %d", final, first.calls)
+	}
+}
+
+func TestEmptyStreamFailsOver(t *testing.T) {
+	first := &chainStub{name: "first", empty: true}
+	second := &chainStub{name: "second"}
+	chain := useChainStubs(t, first, second)
+
+	for n := 0; n < 2; n++ {
+		chunks, err := chain.stream(context.Background(), chain.names, promptRequest("вопрос"))
+		if err != nil {
+			t.Fatalf("Неожиданная ошибка: %v", err)
+		}
+		if text, final := collectChunks(t, chunks); text != "ответ second" || final.Provider != "second" {
+			t.Errorf("Пустой поток должен переключать на резервного провайдера: %q, %+v", text, final)
+		}
+	}
+	if chain.breaker("first").allow(chain.now()) {
+		t.Error("Пустые потоки должны отключать провайдера")
+	}
+}
+
+func TestFallbackAllProvidersFail(t *testing.T) {
+	first := &chainStub{name: "first", err: &ProviderError{Provider: "first", StatusCode: 429, Kind: ErrRateLimited}}
+	second := &chainStub{name: "second", err: &ProviderError{Provider: "second", StatusCode: 529, Kind: ErrOverloaded}}
+	chain := useChainStubs(t, first, second)
+
+	_, err := chain.generate(context.Background(), chain.names, promptRequest("вопрос"))
+	if !errors.Is(err, ErrRateLimited) || !errors.Is(err, ErrOverloaded) || !IsRetryable(err) {
+		t.Errorf("Ошибка должна содержать причины отказа всех провайдеров: %v", err)
+	}
+}
+
+func TestFallbackRecordsUsagePerAttempt(t *testing.T) {
+	first := &chainStub{name: "first", err: &ProviderError{Provider: "first", StatusCode: 429, Kind: ErrRateLimited}}
+	second := &chainStub{name: "second"}
+	chain := useChainStubs(t, first, second)
+
+	var records []UsageRecord
+	UsageRecorder = func(record UsageRecord) { records = append(records, record) }
+	t.Cleanup(func() { UsageRecorder = nil })
+
+	ctx := WithRequester(context.Background(), "user", "guild")
+	chunks, err := chain.stream(ctx, chain.names, promptRequest("вопрос"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	collectChunks(t, chunks)
+
+	if len(records) != 2 {
+		t.Fatalf("Ожидалось две записи об обращениях, получено %d: %+v", len(records), records)
+	}
+	if records[0].Provider != "first" || !errors.Is(records[0].Err, ErrRateLimited) {
+		t.Errorf("Неверная запись о неудачной попытке: %+v", records[0])
+	}
+	if records[1].Provider != "second" || records[1].Err != nil || records[1].Model != "second-model" ||
+		records[1].UserID != "user" || records[1].GuildID != "guild" {
+		t.Errorf("Неверная запись об успешном ответе: %+v", records[1])
+	}
+}
+
+func TestPreferProvider(t *testing.T) {
+	names := []string{"gemini", "claude", "qwen"}
+
+	if got := preferProvider(names, "qwen"); !reflect.DeepEqual(got, []string{"qwen", "gemini", "claude"}) {
+		t.Errorf("Предпочтительный провайдер должен быть первым: %v", got)
+	}
+	if got := preferProvider(names, "grok"); !reflect.DeepEqual(got, names) {
+		t.Errorf("Неинициализированный провайдер не должен менять цепочку: %v", got)
+	}
+}
+
+// visionStub - заглушка провайдера, принимающего изображения
+type visionStub struct {
+	chainStub
+}
+
+func (p *visionStub) SupportsImages() bool { return true }
+
+func TestFallbackSkipsProvidersWithoutVision(t *testing.T) {
+	text := &chainStub{name: "text"}
+	vision := &visionStub{chainStub{name: "vision"}}
+	chain := useChainStubs(t, text)
+	AvailableProviders[vision.name] = vision
+	chain.names = append(chain.names, vision.name)
+
+	request := Request{Messages: []Message{{Role: RoleUser, Content: "?", Images: []Image{{MIMEType: "image/png"}}}}}
+	response, err := chain.generate(context.Background(), chain.names, request)
+	if err != nil || response.Provider != "vision" || text.calls != 0 {
+		t.Errorf("Запрос с изображением должен получить провайдер с их поддержкой: %+v, %v", response, err)
+	}
+
+	_, err = chain.generate(context.Background(), []string{"text"}, request)
+	if !errors.Is(err, ErrImagesUnsupported) || text.calls != 0 {
+		t.Errorf("Провайдер без поддержки изображений должен отклонить запрос: %v", err)
+	}
+}
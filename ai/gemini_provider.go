@@ -1,17 +1,91 @@
 package ai
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"discord-bot/config"
 )
 
+// geminiDefaults содержит настройки Gemini по умолчанию
+var geminiDefaults = config.AIProviderConfig{
+	BaseURL:     "https://generativelanguage.googleapis.com",
+	Model:       "gemini-1.5-flash",
+	Temperature: floatPtr(0.7),
+	MaxTokens:   2048,
+	Vision:      boolPtr(true),
+}
+
 // GeminiProvider реализует интерфейс AIProvider для Gemini
 type GeminiProvider struct {
 	apiKey      string
+	settings    config.AIProviderConfig
+	client      *http.Client
 	initialized bool
 }
 
+// geminiPart представляет часть содержимого сообщения Gemini
+type geminiPart struct {
+	Text       string            `json:"text,omitempty"`
+	InlineData *geminiInlineData `json:"inlineData,omitempty"`
+}
+
+// geminiInlineData содержит изображение в кодировке base64
+type geminiInlineData struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// geminiContent представляет сообщение в формате Gemini
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+// geminiGenerationConfig содержит параметры генерации
+type geminiGenerationConfig struct {
+	Temperature     float64  `json:"temperature"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+}
+
+// geminiRequest представляет тело запроса generateContent
+type geminiRequest struct {
+	SystemInstruction *geminiContent         `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent        `json:"contents"`
+	GenerationConfig  geminiGenerationConfig `json:"generationConfig"`
+}
+
+// geminiResponse представляет ответ generateContent (и каждый фрагмент streamGenerateContent)
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+}
+
+// geminiErrorResponse представляет ошибку API Gemini
+type geminiErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
 // Initialize инициализирует клиент Gemini API
 func (p *GeminiProvider) Initialize() error {
 	// Загружаем конфигурацию
@@ -26,18 +100,247 @@ func (p *GeminiProvider) Initialize() error {
 	}
 
 	p.apiKey = cfg.GeminiAPIKey
+	p.settings = providerSettings(cfg, "gemini", geminiDefaults)
+	p.client = newHTTPClient()
 	p.initialized = true
 	return nil
 }
 
 // GenerateResponse генерирует ответ на запрос пользователя
 func (p *GeminiProvider) GenerateResponse(prompt string) (string, error) {
+	response, err := p.Generate(context.Background(), promptRequest(prompt))
+	return response.Text, err
+}
+
+// Generate генерирует ответ с учетом контекста и истории диалога
+func (p *GeminiProvider) Generate(ctx context.Context, req Request) (Response, error) {
 	if !p.initialized {
-		return "", fmt.Errorf("сервис Gemini не инициализирован")
+		return Response{}, fmt.Errorf("сервис Gemini не инициализирован")
+	}
+	if hasImages(req) && !p.SupportsImages() {
+		return Response{}, imagesUnsupported(p.GetName())
+	}
+
+	status, body, err := postJSON(ctx, p.client, p.endpoint("generateContent"), p.headers(), p.buildRequest(req))
+	if err != nil {
+		return Response{}, fmt.Errorf("ошибка запроса к Gemini: %w", err)
+	}
+
+	if status != http.StatusOK {
+		return Response{}, p.parseError(status, body)
+	}
+
+	var response geminiResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Response{}, fmt.Errorf("ошибка декодирования ответа Gemini: %w", err)
+	}
+
+	text, finishReason, err := p.extractText(response)
+	if err != nil {
+		return Response{}, err
+	}
+	if text == "" {
+		if finishReason == "SAFETY" || finishReason == "PROHIBITED_CONTENT" {
+			return Response{}, p.safetyError(finishReason)
+		}
+		return Response{}, fmt.Errorf("Gemini вернул пустой ответ (%s)", finishReason)
+	}
+
+	result := Response{
+		Text:         text,
+		Model:        p.settings.Model,
+		FinishReason: finishReason,
+	}
+	if response.ModelVersion != "" {
+		result.Model = response.ModelVersion
+	}
+	if response.UsageMetadata != nil {
+		result.Usage = Usage{
+			PromptTokens:     response.UsageMetadata.PromptTokenCount,
+			CompletionTokens: response.UsageMetadata.CandidatesTokenCount,
+		}
+	}
+
+	return result, nil
+}
+
+// Stream генерирует ответ в потоковом режиме через streamGenerateContent
+func (p *GeminiProvider) Stream(ctx context.Context, req Request) (<-chan Chunk, error) {
+	if !p.initialized {
+		return nil, fmt.Errorf("сервис Gemini не инициализирован")
+	}
+	if hasImages(req) && !p.SupportsImages() {
+		return nil, imagesUnsupported(p.GetName())
+	}
+
+	endpoint := p.endpoint("streamGenerateContent") + "?alt=sse"
+	resp, errBody, err := openStream(ctx, p.client, endpoint, p.headers(), p.buildRequest(req))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к Gemini: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.parseError(resp.StatusCode, errBody)
+	}
+
+	chunks := make(chan Chunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+
+		final := Chunk{Done: true, Model: p.settings.Model}
+		received := false
+		err := readSSE(resp.Body, func(_, data string) error {
+			var response geminiResponse
+			if err := json.Unmarshal([]byte(data), &response); err != nil {
+				return fmt.Errorf("ошибка декодирования фрагмента Gemini: %w", err)
+			}
+
+			text, finishReason, err := p.extractText(response)
+			if err != nil {
+				return err
+			}
+			if finishReason != "" {
+				final.FinishReason = finishReason
+			}
+			if response.ModelVersion != "" {
+				final.Model = response.ModelVersion
+			}
+			if response.UsageMetadata != nil {
+				final.Usage = Usage{
+					PromptTokens:     response.UsageMetadata.PromptTokenCount,
+					CompletionTokens: response.UsageMetadata.CandidatesTokenCount,
+				}
+			}
+			if text != "" {
+				received = true
+				if !sendChunk(ctx, chunks, Chunk{Text: text}) {
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+		if err == nil && !received && (final.FinishReason == "SAFETY" || final.FinishReason == "PROHIBITED_CONTENT") {
+			err = p.safetyError(final.FinishReason)
+		}
+		if err != nil {
+			sendChunk(ctx, chunks, Chunk{Err: err})
+			return
+		}
+		sendChunk(ctx, chunks, final)
+	}()
+
+	return chunks, nil
+}
+
+// buildRequest формирует тело запроса Gemini из общего запроса
+func (p *GeminiProvider) buildRequest(req Request) geminiRequest {
+	req = withDefaults(req, p.settings)
+
+	request := geminiRequest{
+		GenerationConfig: geminiGenerationConfig{
+			Temperature:     *req.Temperature,
+			MaxOutputTokens: req.MaxTokens,
+			StopSequences:   p.settings.StopSequences,
+		},
+	}
+
+	if req.System != "" {
+		request.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: req.System}}}
+	}
+
+	for _, msg := range req.Messages {
+		// Gemini называет ответы модели ролью "model"
+		role := "user"
+		if msg.Role == RoleAssistant {
+			role = "model"
+		}
+		// Gemini отклоняет пустые части, поэтому текст добавляется, только если он есть
+		var parts []geminiPart
+		if msg.Content != "" {
+			parts = append(parts, geminiPart{Text: msg.Content})
+		}
+		for _, image := range msg.Images {
+			parts = append(parts, geminiPart{InlineData: &geminiInlineData{
+				MimeType: image.MIMEType,
+				Data:     base64.StdEncoding.EncodeToString(image.Data),
+			}})
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		request.Contents = append(request.Contents, geminiContent{Role: role, Parts: parts})
+	}
+
+	return request
+}
+
+// SupportsImages сообщает, принимает ли модель изображения
+func (p *GeminiProvider) SupportsImages() bool {
+	return visionEnabled(p.settings)
+}
+
+// endpoint возвращает адрес метода модели Gemini
+func (p *GeminiProvider) endpoint(method string) string {
+	return fmt.Sprintf("%s/v1beta/models/%s:%s",
+		strings.TrimRight(p.settings.BaseURL, "/"), url.PathEscape(p.settings.Model), method)
+}
+
+// headers возвращает заголовки авторизации.
+// Ключ передается в заголовке, чтобы он не попадал в тексты ошибок с URL
+func (p *GeminiProvider) headers() map[string]string {
+	return map[string]string{"x-goog-api-key": p.apiKey}
+}
+
+// extractText извлекает текст и причину завершения из ответа Gemini
+func (p *GeminiProvider) extractText(response geminiResponse) (string, string, error) {
+	// Запрос целиком отклонен фильтрами безопасности
+	if response.PromptFeedback != nil && response.PromptFeedback.BlockReason != "" {
+		return "", "", p.safetyError(response.PromptFeedback.BlockReason)
+	}
+
+	if len(response.Candidates) == 0 {
+		return "", "", nil
+	}
+
+	candidate := response.Candidates[0]
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		text.WriteString(part.Text)
+	}
+
+	return text.String(), candidate.FinishReason, nil
+}
+
+// safetyError создает ошибку блокировки фильтрами безопасности
+func (p *GeminiProvider) safetyError(reason string) error {
+	return &ProviderError{
+		Provider: p.GetName(),
+		Code:     reason,
+		Kind:     ErrSafetyBlocked,
+	}
+}
+
+// parseError преобразует ответ с ошибкой в ProviderError
+func (p *GeminiProvider) parseError(status int, body []byte) error {
+	providerErr := &ProviderError{
+		Provider:   p.GetName(),
+		StatusCode: status,
+	}
+
+	var errResponse geminiErrorResponse
+	if err := json.Unmarshal(body, &errResponse); err == nil {
+		providerErr.Code = errResponse.Error.Status
+		providerErr.Message = errResponse.Error.Message
+	}
+	if providerErr.Message == "" {
+		providerErr.Message = http.StatusText(status)
+	}
+
+	if status == http.StatusTooManyRequests || providerErr.Code == "RESOURCE_EXHAUSTED" {
+		providerErr.Kind = ErrQuotaExceeded
 	}
 
-	// Здесь будет реализация запроса к API Gemini
-	return "[Заглушка] Ответ от Gemini: " + prompt, nil
+	return providerErr
 }
 
 // GetName возвращает название AI модели
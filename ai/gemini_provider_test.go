@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestGemini создает провайдер Gemini, направленный на тестовый сервер
+func newTestGemini(t *testing.T, handler http.HandlerFunc) *GeminiProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	settings := geminiDefaults
+	settings.BaseURL = server.URL
+	return &GeminiProvider{
+		apiKey:      "test-key",
+		settings:    settings,
+		client:      server.Client(),
+		initialized: true,
+	}
+}
+
+func TestGeminiGenerateResponse(t *testing.T) {
+	p := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-1.5-flash:generateContent" {
+			t.Errorf("Неожиданный путь запроса: %s", r.URL.Path)
+		}
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			t.Errorf("API ключ не передан в заголовке")
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"text":"Привет"`) {
+			t.Errorf("Запрос не содержит текст пользователя: %s", body)
+		}
+
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Здравствуй, "},{"text":"мир"}]},"finishReason":"STOP"}]}`))
+	})
+
+	response, err := p.GenerateResponse("Привет")
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if response != "Здравствуй, мир" {
+		t.Errorf("Неверный ответ: %q", response)
+	}
+}
+
+func TestGeminiSafetyBlock(t *testing.T) {
+	p := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"promptFeedback":{"blockReason":"SAFETY"}}`))
+	})
+
+	_, err := p.GenerateResponse("запрещенный запрос")
+	if !errors.Is(err, ErrSafetyBlocked) {
+		t.Fatalf("Ожидалась ошибка ErrSafetyBlocked, получено: %v", err)
+	}
+}
+
+func TestGeminiQuotaExceeded(t *testing.T) {
+	p := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`))
+	})
+
+	_, err := p.GenerateResponse("запрос")
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Ожидалась ошибка ErrQuotaExceeded, получено: %v", err)
+	}
+
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Ожидался ProviderError со статусом 429, получено: %v", err)
+	}
+}
+
+func TestGeminiRequestSkipsEmptyText(t *testing.T) {
+	p := &GeminiProvider{settings: geminiDefaults}
+	request := p.buildRequest(Request{Messages: []Message{
+		{Role: RoleUser, Images: []Image{{MIMEType: "image/png", Data: []byte("png")}}},
+		{Role: RoleAssistant},
+		{Role: RoleUser, Content: "Что на картинке?"},
+	}})
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if strings.Contains(string(body), "{}") {
+		t.Errorf("Запрос не должен содержать пустых частей: %s", body)
+	}
+	if len(request.Contents) != 2 || request.Contents[0].Parts[0].InlineData == nil {
+		t.Errorf("Ожидались сообщение с изображением и текстовый вопрос: %s", body)
+	}
+}
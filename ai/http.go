@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout ограничивает время ожидания ответа от API провайдера
+const defaultHTTPTimeout = 60 * time.Second
+
+// errStreamDone сигнализирует о штатном завершении потока событий
+var errStreamDone = errors.New("поток завершен")
+
+// newHTTPClient создает HTTP клиент для запросов к API провайдеров
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// newJSONRequest создает POST запрос с JSON телом
+func newJSONRequest(ctx context.Context, url string, headers map[string]string, payload interface{}) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации запроса: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
+
+// postJSON отправляет POST запрос с JSON телом и возвращает статус и тело ответа
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) (int, []byte, error) {
+	req, err := newJSONRequest(ctx, url, headers, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("ошибка чтения ответа: %w", err)
+	}
+
+	return resp.StatusCode, data, nil
+}
+
+// openStream отправляет POST запрос и возвращает ответ с потоком событий.
+// При статусе, отличном от 200, тело ответа вычитывается и возвращается вместе со статусом.
+func openStream(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) (*http.Response, []byte, error) {
+	req, err := newJSONRequest(ctx, url, headers, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// Общий таймаут клиента оборвал бы длинный поток, поэтому длительность ограничивается контекстом
+	streamClient := *client
+	streamClient.Timeout = 0
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, data, nil
+	}
+
+	return resp, nil, nil
+}
+
+// readSSE читает поток Server-Sent Events и вызывает handler для каждого события.
+// Если handler возвращает errStreamDone, чтение завершается без ошибки.
+func readSSE(r io.Reader, handler func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+		err := handler(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				if errors.Is(err, errStreamDone) {
+					return nil
+				}
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Комментарий (keep-alive), пропускаем
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("ошибка чтения потока: %w", err)
+	}
+
+	// Последнее событие может не завершаться пустой строкой
+	if err := dispatch(); err != nil && !errors.Is(err, errStreamDone) {
+		return err
+	}
+	return nil
+}
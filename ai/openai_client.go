@@ -0,0 +1,334 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"discord-bot/config"
+)
+
+// OpenAIClient реализует запросы к API, совместимым с OpenAI /v1/chat/completions.
+// Используется провайдерами ChatGPT, Grok, Qwen и самостоятельно размещенными
+// серверами (vLLM, llama.cpp, Ollama).
+type OpenAIClient struct {
+	name     string
+	apiKey   string
+	settings config.AIProviderConfig
+	client   *http.Client
+}
+
+// openAIChatRequest представляет тело запроса chat/completions
+type openAIChatRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature float64         `json:"temperature"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Stop        []string        `json:"stop,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+// openAIStreamOptions - параметры потокового ответа. Без include_usage API не сообщает
+// количество токенов в потоке, и учет использования получает нули
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIMessage представляет сообщение запроса. Content - строка
+// или список частей, если к сообщению приложены изображения.
+type openAIMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// openAIContentPart представляет часть содержимого сообщения с изображениями
+type openAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+// openAIImageURL содержит изображение в формате data URL
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+// openAIChatResponse представляет ответ chat/completions
+type openAIChatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// openAIStreamChunk представляет фрагмент потокового ответа chat/completions
+type openAIStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// openAIErrorResponse представляет ошибку OpenAI-совместимого API
+type openAIErrorResponse struct {
+	Error struct {
+		Message string      `json:"message"`
+		Type    string      `json:"type"`
+		Code    interface{} `json:"code"`
+	} `json:"error"`
+}
+
+// NewOpenAIClient создает клиент для OpenAI-совместимого API.
+// BaseURL в настройках должен включать версию API, например https://api.openai.com/v1
+func NewOpenAIClient(name, apiKey string, settings config.AIProviderConfig) *OpenAIClient {
+	return &OpenAIClient{
+		name:     name,
+		apiKey:   apiKey,
+		settings: settings,
+		client:   newHTTPClient(),
+	}
+}
+
+// Generate отправляет диалог модели и возвращает ответ целиком
+func (c *OpenAIClient) Generate(ctx context.Context, req Request) (Response, error) {
+	status, body, err := postJSON(ctx, c.client, c.endpoint(), c.headers(), c.buildRequest(req, false))
+	if err != nil {
+		return Response{}, fmt.Errorf("ошибка запроса к %s: %w", c.name, err)
+	}
+
+	if status != http.StatusOK {
+		return Response{}, c.parseError(status, body)
+	}
+
+	var response openAIChatResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Response{}, fmt.Errorf("ошибка декодирования ответа %s: %w", c.name, err)
+	}
+
+	if len(response.Choices) == 0 {
+		return Response{}, fmt.Errorf("%s вернул пустой ответ", c.name)
+	}
+
+	choice := response.Choices[0]
+	if choice.Message.Content == "" && choice.FinishReason == "content_filter" {
+		return Response{}, &ProviderError{
+			Provider: c.name,
+			Code:     choice.FinishReason,
+			Kind:     ErrSafetyBlocked,
+		}
+	}
+
+	return Response{
+		Text:         choice.Message.Content,
+		Model:        response.Model,
+		FinishReason: choice.FinishReason,
+		Usage: Usage{
+			PromptTokens:     response.Usage.PromptTokens,
+			CompletionTokens: response.Usage.CompletionTokens,
+		},
+	}, nil
+}
+
+// Stream отправляет диалог модели и возвращает канал с фрагментами ответа
+func (c *OpenAIClient) Stream(ctx context.Context, req Request) (<-chan Chunk, error) {
+	resp, errBody, err := openStream(ctx, c.client, c.endpoint(), c.headers(), c.buildRequest(req, true))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к %s: %w", c.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseError(resp.StatusCode, errBody)
+	}
+
+	chunks := make(chan Chunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+
+		final := Chunk{Done: true, Model: c.settings.Model}
+		done := false
+		err := readSSE(resp.Body, func(_, data string) error {
+			if data == "[DONE]" {
+				done = true
+				return errStreamDone
+			}
+
+			var chunk openAIStreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				return fmt.Errorf("ошибка декодирования фрагмента %s: %w", c.name, err)
+			}
+			if chunk.Model != "" {
+				final.Model = chunk.Model
+			}
+			if chunk.Usage != nil {
+				final.Usage = Usage{
+					PromptTokens:     chunk.Usage.PromptTokens,
+					CompletionTokens: chunk.Usage.CompletionTokens,
+				}
+			}
+			for _, choice := range chunk.Choices {
+				if choice.FinishReason != nil {
+					final.FinishReason = *choice.FinishReason
+				}
+				if choice.Delta.Content != "" && !sendChunk(ctx, chunks, Chunk{Text: choice.Delta.Content}) {
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+		// Поток, оборванный до [DONE], - незавершенный ответ, а не успех
+		if err == nil && !done {
+			err = fmt.Errorf("%s: %w", c.name, ErrStreamInterrupted)
+		}
+		if err != nil {
+			sendChunk(ctx, chunks, Chunk{Err: err})
+			return
+		}
+		sendChunk(ctx, chunks, final)
+	}()
+
+	return chunks, nil
+}
+
+// buildRequest формирует тело запроса chat/completions
+func (c *OpenAIClient) buildRequest(req Request, stream bool) openAIChatRequest {
+	req = withDefaults(req, c.settings)
+
+	var messages []openAIMessage
+	if req.System != "" {
+		messages = append(messages, openAIMessage{Role: RoleSystem, Content: req.System})
+	}
+	for _, msg := range req.Messages {
+		messages = append(messages, newOpenAIMessage(msg))
+	}
+
+	request := openAIChatRequest{
+		Model:       c.settings.Model,
+		Messages:    messages,
+		Temperature: *req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Stop:        c.settings.StopSequences,
+		Stream:      stream,
+	}
+	if stream {
+		// Использование приходит отдельным фрагментом без choices перед [DONE]
+		request.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+	return request
+}
+
+// newOpenAIMessage преобразует сообщение диалога в формат chat/completions
+func newOpenAIMessage(msg Message) openAIMessage {
+	if len(msg.Images) == 0 {
+		return openAIMessage{Role: msg.Role, Content: msg.Content}
+	}
+
+	parts := []openAIContentPart{{Type: "text", Text: msg.Content}}
+	for _, image := range msg.Images {
+		parts = append(parts, openAIContentPart{Type: "image_url", ImageURL: &openAIImageURL{URL: dataURL(image)}})
+	}
+	return openAIMessage{Role: msg.Role, Content: parts}
+}
+
+// SupportsImages сообщает, принимает ли модель изображения
+func (c *OpenAIClient) SupportsImages() bool {
+	return visionEnabled(c.settings)
+}
+
+// endpoint возвращает адрес chat/completions
+func (c *OpenAIClient) endpoint() string {
+	return strings.TrimRight(c.settings.BaseURL, "/") + "/chat/completions"
+}
+
+// headers возвращает заголовки авторизации
+func (c *OpenAIClient) headers() map[string]string {
+	// Локальные серверы часто работают без ключа
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+	return headers
+}
+
+// parseError преобразует ответ с ошибкой в ProviderError
+func (c *OpenAIClient) parseError(status int, body []byte) error {
+	providerErr := &ProviderError{
+		Provider:   c.name,
+		StatusCode: status,
+	}
+
+	var errResponse openAIErrorResponse
+	if err := json.Unmarshal(body, &errResponse); err == nil {
+		providerErr.Message = errResponse.Error.Message
+		providerErr.Code = errResponse.Error.Type
+		// Поле code может быть строкой или числом в зависимости от сервера
+		if code, ok := errResponse.Error.Code.(string); ok && code != "" {
+			providerErr.Code = code
+		}
+	}
+	if providerErr.Message == "" {
+		providerErr.Message = http.StatusText(status)
+	}
+
+	switch {
+	case providerErr.Code == "insufficient_quota":
+		providerErr.Kind = ErrQuotaExceeded
+	case status == http.StatusTooManyRequests:
+		providerErr.Kind = ErrRateLimited
+	}
+
+	return providerErr
+}
+
+// openAIProvider содержит общую реализацию провайдеров поверх OpenAIClient
+type openAIProvider struct {
+	client *OpenAIClient
+}
+
+// Generate генерирует ответ с учетом контекста и истории диалога
+func (p *openAIProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	if p.client == nil {
+		return Response{}, fmt.Errorf("сервис AI не инициализирован")
+	}
+	if hasImages(req) && !p.client.SupportsImages() {
+		return Response{}, imagesUnsupported(p.client.name)
+	}
+	return p.client.Generate(ctx, req)
+}
+
+// Stream генерирует ответ в потоковом режиме
+func (p *openAIProvider) Stream(ctx context.Context, req Request) (<-chan Chunk, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("сервис AI не инициализирован")
+	}
+	if hasImages(req) && !p.client.SupportsImages() {
+		return nil, imagesUnsupported(p.client.name)
+	}
+	return p.client.Stream(ctx, req)
+}
+
+// SupportsImages сообщает, принимает ли модель провайдера изображения
+func (p *openAIProvider) SupportsImages() bool {
+	return p.client != nil && p.client.SupportsImages()
+}
+
+// GenerateResponse генерирует ответ на запрос пользователя
+func (p *openAIProvider) GenerateResponse(prompt string) (string, error) {
+	response, err := p.Generate(context.Background(), promptRequest(prompt))
+	return response.Text, err
+}
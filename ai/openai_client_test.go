@@ -0,0 +1,207 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"discord-bot/config"
+)
+
+// newTestOpenAIClient создает OpenAI-совместимый клиент, направленный на тестовый сервер
+func newTestOpenAIClient(t *testing.T, apiKey string, handler http.HandlerFunc) *OpenAIClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewOpenAIClient("Test", apiKey, config.AIProviderConfig{
+		BaseURL:     server.URL + "/v1",
+		Model:       "test-model",
+		Temperature: floatPtr(0.5),
+		MaxTokens:   128,
+	})
+}
+
+func TestOpenAIClientComplete(t *testing.T) {
+	client := newTestOpenAIClient(t, "secret", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Неожиданный путь запроса: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Неверный заголовок авторизации: %q", r.Header.Get("Authorization"))
+		}
+
+		var request openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Ошибка декодирования запроса: %v", err)
+		}
+		if request.Model != "test-model" || request.MaxTokens != 128 || request.Temperature != 0.5 {
+			t.Errorf("Параметры модели не переданы: %+v", request)
+		}
+
+		w.Write([]byte(`{"model":"test-model","choices":[{"message":{"role":"assistant","content":"Ответ"},"finish_reason":"stop"}]}`))
+	})
+
+	response, err := client.Generate(context.Background(), promptRequest("Вопрос"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if response.Text != "Ответ" || response.Model != "test-model" || response.FinishReason != "stop" {
+		t.Errorf("Неверный ответ: %+v", response)
+	}
+}
+
+func TestOpenAIClientWithoutKey(t *testing.T) {
+	client := newTestOpenAIClient(t, "", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Заголовок авторизации не должен отправляться без ключа")
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	})
+
+	if _, err := client.Generate(context.Background(), promptRequest("ping")); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+}
+
+func TestOpenAIClientQuotaError(t *testing.T) {
+	client := newTestOpenAIClient(t, "secret", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`))
+	})
+
+	_, err := client.Generate(context.Background(), promptRequest("ping"))
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Ожидалась ошибка ErrQuotaExceeded, получено: %v", err)
+	}
+}
+
+func TestOpenAIClientStream(t *testing.T) {
+	client := newTestOpenAIClient(t, "secret", func(w http.ResponseWriter, r *http.Request) {
+		var request openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Ошибка декодирования запроса: %v", err)
+		}
+		if !request.Stream || request.StreamOptions == nil || !request.StreamOptions.IncludeUsage {
+			t.Errorf("Флаг stream или stream_options.include_usage не передан: %+v", request)
+		}
+		if len(request.Messages) != 2 || request.Messages[0].Role != RoleSystem {
+			t.Errorf("Системный промпт не передан первым сообщением: %+v", request.Messages)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"model\":\"test-model\",\"choices\":[{\"delta\":{\"content\":\"При\"}}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"вет\"},\"finish_reason\":\"stop\"}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3}}\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	request := promptRequest("Привет")
+	request.System = "Будь краток"
+	chunks, err := client.Stream(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+
+	text, final := collectChunks(t, chunks)
+	if text != "Привет" {
+		t.Errorf("Неверный текст потока: %q", text)
+	}
+	if !final.Done || final.FinishReason != "stop" || final.Model != "test-model" {
+		t.Errorf("Неверный завершающий фрагмент: %+v", final)
+	}
+	if final.Usage.PromptTokens != 12 || final.Usage.CompletionTokens != 3 {
+		t.Errorf("Использование из потока не получено: %+v", final.Usage)
+	}
+}
+
+func TestOpenAIClientStreamWithoutDone(t *testing.T) {
+	client := newTestOpenAIClient(t, "secret", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"model\":\"test-model\",\"choices\":[{\"delta\":{\"content\":\"При\"}}]}\n\n"))
+	})
+
+	chunks, err := client.Stream(context.Background(), promptRequest("Привет"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if err := streamError(t, chunks); !errors.Is(err, ErrStreamInterrupted) {
+		t.Errorf("Поток без [DONE] должен завершаться ошибкой обрыва, получено %v", err)
+	}
+}
+
+// streamError читает поток до конца и возвращает ошибку из него. Оборванный поток
+// не должен завершаться успешным фрагментом
+func streamError(t *testing.T, chunks <-chan Chunk) error {
+	t.Helper()
+	var err error
+	for chunk := range chunks {
+		if chunk.Done {
+			t.Errorf("Оборванный поток не должен завершаться успешно: %+v", chunk)
+		}
+		if chunk.Err != nil {
+			err = chunk.Err
+		}
+	}
+	return err
+}
+
+// collectChunks собирает текст потока и возвращает завершающий фрагмент
+func collectChunks(t *testing.T, chunks <-chan Chunk) (string, Chunk) {
+	t.Helper()
+	var text string
+	var final Chunk
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			t.Fatalf("Ошибка в потоке: %v", chunk.Err)
+		}
+		if chunk.Done {
+			final = chunk
+			continue
+		}
+		text += chunk.Text
+	}
+	return text, final
+}
+
+func TestOpenAIClientImageContent(t *testing.T) {
+	client := newTestOpenAIClient(t, "secret", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Messages []struct {
+				Content []openAIContentPart `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Ошибка декодирования запроса: %v", err)
+		}
+
+		parts := request.Messages[0].Content
+		if len(parts) != 2 || parts[0].Text != "Что на картинке?" || parts[1].ImageURL == nil ||
+			parts[1].ImageURL.URL != "data:image/png;base64,iVBORw==" {
+			t.Errorf("Изображение не передано в запросе: %+v", parts)
+		}
+
+		w.Write([]byte(`{"model":"test-model","choices":[{"message":{"role":"assistant","content":"Кот"},"finish_reason":"stop"}]}`))
+	})
+
+	request := Request{Messages: []Message{{
+		Role:    RoleUser,
+		Content: "Что на картинке?",
+		Images:  []Image{{MIMEType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}}},
+	}}}
+	if _, err := client.Generate(context.Background(), request); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+}
+
+func TestOpenAIProviderRejectsImagesWithoutVision(t *testing.T) {
+	provider := &openAIProvider{client: NewOpenAIClient("Test", "", config.AIProviderConfig{})}
+
+	request := Request{Messages: []Message{{Role: RoleUser, Content: "?", Images: []Image{{MIMEType: "image/png"}}}}}
+	if _, err := provider.Generate(context.Background(), request); !errors.Is(err, ErrImagesUnsupported) {
+		t.Errorf("Ожидалась ошибка ErrImagesUnsupported, получено: %v", err)
+	}
+}
@@ -6,10 +6,36 @@ import (
 	"discord-bot/config"
 )
 
+// Настройки по умолчанию для провайдеров с OpenAI-совместимым API
+var (
+	grokDefaults = config.AIProviderConfig{
+		BaseURL:     "https://api.x.ai/v1",
+		Model:       "grok-2-latest",
+		Temperature: floatPtr(0.7),
+		MaxTokens:   2048,
+	}
+	chatGPTDefaults = config.AIProviderConfig{
+		BaseURL:     "https://api.openai.com/v1",
+		Model:       "gpt-4o-mini",
+		Temperature: floatPtr(0.7),
+		MaxTokens:   2048,
+		Vision:      boolPtr(true),
+	}
+	qwenDefaults = config.AIProviderConfig{
+		BaseURL:     "https://dashscope-intl.aliyuncs.com/compatible-mode/v1",
+		Model:       "qwen-plus",
+		Temperature: floatPtr(0.7),
+		MaxTokens:   2048,
+	}
+	openAICompatibleDefaults = config.AIProviderConfig{
+		Temperature: floatPtr(0.7),
+		MaxTokens:   2048,
+	}
+)
+
 // GrokProvider реализует интерфейс AIProvider для Grok AI
 type GrokProvider struct {
-	apiKey      string
-	initialized bool
+	openAIProvider
 }
 
 // Initialize инициализирует клиент Grok API
@@ -25,21 +51,10 @@ func (p *GrokProvider) Initialize() error {
 		return fmt.Errorf("API ключ Grok не указан в конфигурации")
 	}
 
-	p.apiKey = cfg.GrokAPIKey
-	p.initialized = true
+	p.client = NewOpenAIClient(p.GetName(), cfg.GrokAPIKey, providerSettings(cfg, "grok", grokDefaults))
 	return nil
 }
 
-// GenerateResponse генерирует ответ на запрос пользователя
-func (p *GrokProvider) GenerateResponse(prompt string) (string, error) {
-	if !p.initialized {
-		return "", fmt.Errorf("сервис Grok не инициализирован")
-	}
-
-	// Здесь будет реализация запроса к API Grok
-	return "[Заглушка] Ответ от Grok AI: " + prompt, nil
-}
-
 // GetName возвращает название AI модели
 func (p *GrokProvider) GetName() string {
 	return "Grok"
@@ -47,8 +62,7 @@ func (p *GrokProvider) GetName() string {
 
 // ChatGPTProvider реализует интерфейс AIProvider для ChatGPT
 type ChatGPTProvider struct {
-	apiKey      string
-	initialized bool
+	openAIProvider
 }
 
 // Initialize инициализирует клиент ChatGPT API
@@ -64,21 +78,10 @@ func (p *ChatGPTProvider) Initialize() error {
 		return fmt.Errorf("API ключ ChatGPT не указан в конфигурации")
 	}
 
-	p.apiKey = cfg.ChatGPTAPIKey
-	p.initialized = true
+	p.client = NewOpenAIClient(p.GetName(), cfg.ChatGPTAPIKey, providerSettings(cfg, "chatgpt", chatGPTDefaults))
 	return nil
 }
 
-// GenerateResponse генерирует ответ на запрос пользователя
-func (p *ChatGPTProvider) GenerateResponse(prompt string) (string, error) {
-	if !p.initialized {
-		return "", fmt.Errorf("сервис ChatGPT не инициализирован")
-	}
-
-	// Здесь будет реализация запроса к API ChatGPT
-	return "[Заглушка] Ответ от ChatGPT: " + prompt, nil
-}
-
 // GetName возвращает название AI модели
 func (p *ChatGPTProvider) GetName() string {
 	return "ChatGPT"
@@ -86,8 +89,7 @@ func (p *ChatGPTProvider) GetName() string {
 
 // QwenProvider реализует интерфейс AIProvider для Qwen
 type QwenProvider struct {
-	apiKey      string
-	initialized bool
+	openAIProvider
 }
 
 // Initialize инициализирует клиент Qwen API
@@ -103,61 +105,43 @@ func (p *QwenProvider) Initialize() error {
 		return fmt.Errorf("API ключ Qwen не указан в конфигурации")
 	}
 
-	p.apiKey = cfg.QwenAPIKey
-	p.initialized = true
+	p.client = NewOpenAIClient(p.GetName(), cfg.QwenAPIKey, providerSettings(cfg, "qwen", qwenDefaults))
 	return nil
 }
 
-// GenerateResponse генерирует ответ на запрос пользователя
-func (p *QwenProvider) GenerateResponse(prompt string) (string, error) {
-	if !p.initialized {
-		return "", fmt.Errorf("сервис Qwen не инициализирован")
-	}
-
-	// Здесь будет реализация запроса к API Qwen
-	return "[Заглушка] Ответ от Qwen: " + prompt, nil
-}
-
 // GetName возвращает название AI модели
 func (p *QwenProvider) GetName() string {
 	return "Qwen"
 }
 
-// ClaudeProvider реализует интерфейс AIProvider для Claude
-type ClaudeProvider struct {
-	apiKey      string
-	initialized bool
+// OpenAICompatibleProvider реализует интерфейс AIProvider для самостоятельно
+// размещенных серверов с OpenAI-совместимым API (vLLM, llama.cpp, Ollama)
+type OpenAICompatibleProvider struct {
+	openAIProvider
 }
 
-// Initialize инициализирует клиент Claude API
-func (p *ClaudeProvider) Initialize() error {
+// Initialize инициализирует клиент OpenAI-совместимого сервера
+func (p *OpenAICompatibleProvider) Initialize() error {
 	// Загружаем конфигурацию
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("ошибка загрузки конфигурации: %w", err)
 	}
 
-	// Проверяем наличие API ключа
-	if cfg.ClaudeAPIKey == "" {
-		return fmt.Errorf("API ключ Claude не указан в конфигурации")
+	// Для собственного сервера обязательны адрес и модель, ключ - опционален
+	settings := providerSettings(cfg, "openai_compatible", openAICompatibleDefaults)
+	if settings.BaseURL == "" {
+		return fmt.Errorf("base_url для openai_compatible не указан в конфигурации")
 	}
-
-	p.apiKey = cfg.ClaudeAPIKey
-	p.initialized = true
-	return nil
-}
-
-// GenerateResponse генерирует ответ на запрос пользователя
-func (p *ClaudeProvider) GenerateResponse(prompt string) (string, error) {
-	if !p.initialized {
-		return "", fmt.Errorf("сервис Claude не инициализирован")
+	if settings.Model == "" {
+		return fmt.Errorf("model для openai_compatible не указана в конфигурации")
 	}
 
-	// Здесь будет реализация запроса к API Claude
-	return "[Заглушка] Ответ от Claude: " + prompt, nil
+	p.client = NewOpenAIClient(p.GetName(), settings.APIKey, settings)
+	return nil
 }
 
 // GetName возвращает название AI модели
-func (p *ClaudeProvider) GetName() string {
-	return "Claude"
+func (p *OpenAICompatibleProvider) GetName() string {
+	return "OpenAI-compatible"
 }
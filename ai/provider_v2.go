@@ -0,0 +1,240 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"discord-bot/config"
+)
+
+// Роли участников диалога
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message представляет одно сообщение диалога с моделью
+type Message struct {
+	Role    string  `json:"role"`    // Роль автора: system, user или assistant
+	Content string  `json:"content"` // Текст сообщения
+	Images  []Image `json:"-"`       // Изображения, приложенные к сообщению пользователя
+}
+
+// Image - изображение, передаваемое модели вместе с текстом
+type Image struct {
+	MIMEType string // image/png, image/jpeg, image/gif или image/webp
+	Data     []byte
+}
+
+// ImageProvider реализуется провайдерами, способными принимать изображения
+type ImageProvider interface {
+	SupportsImages() bool
+}
+
+// Request описывает запрос к модели
+type Request struct {
+	System      string    // Системный промпт (пусто - из настроек провайдера)
+	Messages    []Message // История диалога, последнее сообщение - текущий запрос
+	Temperature *float64  // Температура (nil - из настроек провайдера)
+	MaxTokens   int       // Максимум токенов ответа (0 - из настроек провайдера)
+}
+
+// Usage содержит количество использованных токенов
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// Response описывает ответ модели
+type Response struct {
+	Text         string // Текст ответа
+	Provider     string // Провайдер, сформировавший ответ (заполняется цепочкой провайдеров)
+	Model        string // Модель, сформировавшая ответ
+	FinishReason string // Причина завершения генерации
+	Usage        Usage  // Использованные токены
+}
+
+// Chunk представляет фрагмент потокового ответа.
+// Последний фрагмент имеет Done = true и содержит итоговые сведения об ответе.
+// При ошибке поток завершается фрагментом с заполненным Err.
+type Chunk struct {
+	Text         string // Новый фрагмент текста
+	Done         bool   // Признак завершения потока
+	Provider     string // Провайдер (в последнем фрагменте, заполняется цепочкой провайдеров)
+	Model        string // Модель (в последнем фрагменте)
+	FinishReason string // Причина завершения (в последнем фрагменте)
+	Usage        Usage  // Использованные токены (в последнем фрагменте, если известны)
+	Err          error  // Ошибка, прервавшая поток
+}
+
+// AIProviderV2 - интерфейс провайдера с поддержкой контекста, истории диалога и потоковой генерации
+type AIProviderV2 interface {
+	Generate(ctx context.Context, req Request) (Response, error)
+	Stream(ctx context.Context, req Request) (<-chan Chunk, error)
+	GetName() string
+}
+
+// Adapt возвращает реализацию AIProviderV2 для провайдера.
+// Провайдеры без нативной поддержки оборачиваются в адаптер поверх GenerateResponse.
+func Adapt(provider AIProvider) AIProviderV2 {
+	if v2, ok := provider.(AIProviderV2); ok {
+		return v2
+	}
+	return &legacyAdapter{provider: provider}
+}
+
+// legacyAdapter позволяет использовать провайдеры AIProvider через интерфейс AIProviderV2
+type legacyAdapter struct {
+	provider AIProvider
+}
+
+// Generate выполняет блокирующий запрос, прерывая ожидание при отмене контекста
+func (a *legacyAdapter) Generate(ctx context.Context, req Request) (Response, error) {
+	type result struct {
+		text string
+		err  error
+	}
+
+	if hasImages(req) {
+		return Response{}, imagesUnsupported(a.provider.GetName())
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		text, err := a.provider.GenerateResponse(flattenRequest(req))
+		done <- result{text: text, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return Response{}, ctx.Err()
+	case r := <-done:
+		if r.err != nil {
+			return Response{}, r.err
+		}
+		return Response{Text: r.text, Model: a.provider.GetName(), FinishReason: "stop"}, nil
+	}
+}
+
+// Stream возвращает весь ответ одним фрагментом
+func (a *legacyAdapter) Stream(ctx context.Context, req Request) (<-chan Chunk, error) {
+	chunks := make(chan Chunk, 2)
+	go func() {
+		defer close(chunks)
+		response, err := a.Generate(ctx, req)
+		if err != nil {
+			chunks <- Chunk{Err: err}
+			return
+		}
+		chunks <- Chunk{Text: response.Text}
+		chunks <- Chunk{Done: true, Model: response.Model, FinishReason: response.FinishReason}
+	}()
+	return chunks, nil
+}
+
+// SupportsImages сообщает, что провайдеры без нативной поддержки не принимают изображения
+func (a *legacyAdapter) SupportsImages() bool {
+	return false
+}
+
+// GetName возвращает название обернутого провайдера
+func (a *legacyAdapter) GetName() string {
+	return a.provider.GetName()
+}
+
+// flattenRequest собирает системный промпт и историю диалога в один текстовый промпт
+func flattenRequest(req Request) string {
+	// Одиночный запрос без контекста передаем как есть
+	if req.System == "" && len(req.Messages) == 1 {
+		return req.Messages[0].Content
+	}
+
+	var prompt strings.Builder
+	if req.System != "" {
+		prompt.WriteString(req.System)
+		prompt.WriteString("\n\n")
+	}
+	for _, msg := range req.Messages {
+		prompt.WriteString(msg.Role)
+		prompt.WriteString(": ")
+		prompt.WriteString(msg.Content)
+		prompt.WriteString("\n")
+	}
+	return prompt.String()
+}
+
+// withDefaults заполняет незаданные параметры запроса значениями из настроек провайдера
+func withDefaults(req Request, settings config.AIProviderConfig) Request {
+	if req.System == "" {
+		req.System = settings.SystemPrompt
+	}
+	if req.Temperature == nil {
+		var temperature float64
+		if settings.Temperature != nil {
+			temperature = *settings.Temperature
+		}
+		req.Temperature = &temperature
+	}
+	if req.MaxTokens == 0 {
+		req.MaxTokens = settings.MaxTokens
+	}
+	return req
+}
+
+// promptRequest создает запрос из одиночного промпта пользователя
+func promptRequest(prompt string) Request {
+	return Request{Messages: []Message{{Role: RoleUser, Content: prompt}}}
+}
+
+// hasImages проверяет, приложены ли к запросу изображения
+func hasImages(req Request) bool {
+	for _, msg := range req.Messages {
+		if len(msg.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// supportsImages проверяет, принимает ли провайдер изображения
+func supportsImages(provider interface{}) bool {
+	imageProvider, ok := provider.(ImageProvider)
+	return ok && imageProvider.SupportsImages()
+}
+
+// visionEnabled возвращает настройку поддержки изображений провайдера
+func visionEnabled(settings config.AIProviderConfig) bool {
+	return settings.Vision != nil && *settings.Vision
+}
+
+// boolPtr возвращает указатель на значение (для настроек по умолчанию)
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+// floatPtr возвращает указатель на значение (для настроек по умолчанию)
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+// imagesUnsupported возвращает ошибку провайдера, не принимающего изображения
+func imagesUnsupported(provider string) error {
+	return &ProviderError{Provider: provider, Kind: ErrImagesUnsupported}
+}
+
+// dataURL кодирует изображение в data URL
+func dataURL(image Image) string {
+	return "data:" + image.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(image.Data)
+}
+
+// sendChunk отправляет фрагмент в канал, если контекст еще не отменен
+func sendChunk(ctx context.Context, chunks chan<- Chunk, chunk Chunk) bool {
+	select {
+	case chunks <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
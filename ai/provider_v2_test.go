@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"discord-bot/config"
+)
+
+// stubProvider - провайдер, реализующий только интерфейс AIProvider
+type stubProvider struct {
+	delay  time.Duration
+	prompt string
+}
+
+func (p *stubProvider) Initialize() error { return nil }
+func (p *stubProvider) GetName() string   { return "Stub" }
+func (p *stubProvider) GenerateResponse(prompt string) (string, error) {
+	time.Sleep(p.delay)
+	p.prompt = prompt
+	return "ответ", nil
+}
+
+func TestAdaptLegacyProvider(t *testing.T) {
+	stub := &stubProvider{}
+	provider := Adapt(stub)
+
+	response, err := provider.Generate(context.Background(), promptRequest("вопрос"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if response.Text != "ответ" || stub.prompt != "вопрос" {
+		t.Errorf("Адаптер исказил запрос или ответ: %+v, промпт %q", response, stub.prompt)
+	}
+
+	chunks, err := provider.Stream(context.Background(), promptRequest("вопрос"))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	text, final := collectChunks(t, chunks)
+	if text != "ответ" || !final.Done {
+		t.Errorf("Неверный поток адаптера: %q, %+v", text, final)
+	}
+}
+
+func TestAdaptLegacyProviderTimeout(t *testing.T) {
+	provider := Adapt(&stubProvider{delay: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := provider.Generate(ctx, promptRequest("вопрос")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Ожидалась ошибка таймаута, получено: %v", err)
+	}
+}
+
+func TestAdaptNativeProvider(t *testing.T) {
+	if _, ok := Adapt(&ClaudeProvider{}).(*ClaudeProvider); !ok {
+		t.Error("Провайдер с нативной поддержкой не должен оборачиваться в адаптер")
+	}
+}
+
+func TestProviderSettingsZeroTemperature(t *testing.T) {
+	cfg := &config.Config{AIProviders: map[string]config.AIProviderConfig{
+		"grok": {Temperature: floatPtr(0)},
+		"qwen": {Model: "qwen-max"},
+	}}
+
+	if settings := providerSettings(cfg, "grok", grokDefaults); settings.Temperature == nil || *settings.Temperature != 0 {
+		t.Errorf("Явно заданная нулевая температура не должна заменяться значением по умолчанию: %v", settings.Temperature)
+	}
+	if settings := providerSettings(cfg, "qwen", qwenDefaults); settings.Temperature == nil || *settings.Temperature != 0.7 {
+		t.Errorf("Незаданная температура должна браться из настроек по умолчанию: %v", settings.Temperature)
+	}
+}
@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// UsageRecord описывает одно обращение к провайдеру AI
+type UsageRecord struct {
+	UserID   string        // Пользователь, от имени которого выполнялся запрос
+	GuildID  string        // Сервер Discord (пусто для личных сообщений)
+	Provider string        // Провайдер из конфигурации (gemini, claude и т.д.)
+	Model    string        // Модель, сформировавшая ответ
+	Latency  time.Duration // Время от отправки запроса до завершения ответа
+	Usage    Usage         // Использованные токены (если провайдер их сообщил)
+	Err      error         // Ошибка (nil при успешном ответе)
+	Time     time.Time     // Время отправки запроса
+}
+
+// UsageRecorder получает сведения о каждом обращении к провайдеру, включая неудачные попытки
+// перед переключением на резервный провайдер. Вызывается синхронно.
+var UsageRecorder func(record UsageRecord)
+
+// requesterKey - ключ контекста для сведений об авторе запроса
+type requesterKey struct{}
+
+// requester - автор запроса к AI
+type requester struct {
+	userID  string
+	guildID string
+}
+
+// WithRequester добавляет в контекст пользователя и сервер, от имени которых выполняется запрос
+func WithRequester(ctx context.Context, userID, guildID string) context.Context {
+	return context.WithValue(ctx, requesterKey{}, requester{userID: userID, guildID: guildID})
+}
+
+// recordUsage передает сведения об обращении к провайдеру в UsageRecorder
+func recordUsage(ctx context.Context, record UsageRecord) {
+	if UsageRecorder == nil {
+		return
+	}
+	if r, ok := ctx.Value(requesterKey{}).(requester); ok {
+		record.UserID = r.userID
+		record.GuildID = r.guildID
+	}
+	UsageRecorder(record)
+}
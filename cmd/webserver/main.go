@@ -3,6 +3,7 @@ package main
 import (
 	"discord-bot/config"
 	"discord-bot/db"
+	"discord-bot/localization"
 	"discord-bot/web"
 	"fmt"
 	"time"
@@ -23,6 +24,12 @@ func main() {
 		return
 	}
 
+	// Инициализация системы локализации для описаний команд
+	if err := localization.Initialize(); err != nil {
+		fmt.Println("Ошибка инициализации системы локализации:", err)
+		return
+	}
+
 	// Инициализация базы данных
 	err = db.InitializeDB()
 	if err != nil {
@@ -30,6 +37,16 @@ func main() {
 		return
 	}
 
+	// Инициализация провайдера базы данных для API учета AI и персон
+	dbConfig := db.DatabaseConfig{
+		Type:     "sqlite",
+		Database: "data/bot.db",
+	}
+	if err := db.Initialize(dbConfig); err != nil {
+		fmt.Println("Ошибка инициализации базы данных:", err)
+		return
+	}
+
 	// Инициализация таблиц для аутентификации
 	err = db.InitAuthTables()
 	if err != nil {
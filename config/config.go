@@ -13,22 +13,118 @@ type WebInterfaceConfig struct {
 	AltPorts []int  `json:"alt_ports"` // Альтернативные порты для веб-интерфейса
 }
 
+// AIProviderConfig содержит настройки отдельного AI провайдера
+type AIProviderConfig struct {
+	APIKey          string   `json:"api_key,omitempty"`          // API ключ (для провайдеров без отдельного поля ключа)
+	BaseURL         string   `json:"base_url"`                   // Базовый URL API (пусто - официальный endpoint)
+	Model           string   `json:"model"`                      // Название модели
+	Temperature     *float64 `json:"temperature,omitempty"`      // Температура генерации (nil - по умолчанию для провайдера)
+	MaxTokens       int      `json:"max_tokens"`                 // Максимальное количество токенов в ответе
+	SystemPrompt    string   `json:"system_prompt,omitempty"`    // Системный промпт
+	StopSequences   []string `json:"stop_sequences,omitempty"`   // Последовательности, останавливающие генерацию
+	PromptPrice     float64  `json:"prompt_price,omitempty"`     // Стоимость 1 млн токенов запроса в USD (для оценки расходов)
+	CompletionPrice float64  `json:"completion_price,omitempty"` // Стоимость 1 млн токенов ответа в USD (для оценки расходов)
+	Vision          *bool    `json:"vision,omitempty"`           // Модель принимает изображения (nil - по умолчанию для провайдера)
+}
+
+// AIMemoryConfig содержит настройки памяти диалогов с AI
+type AIMemoryConfig struct {
+	Disabled    bool `json:"disabled"`     // Отключить историю диалогов
+	MaxTokens   int  `json:"max_tokens"`   // Бюджет токенов истории, передаваемой модели (0 - 2000)
+	MaxMessages int  `json:"max_messages"` // Максимум сообщений истории, загружаемых из базы (0 - 40)
+}
+
+// AIFallbackConfig содержит настройки цепочки резервных AI провайдеров
+type AIFallbackConfig struct {
+	Chain                 []string `json:"chain,omitempty"`         // Провайдеры, опрашиваемые после default_ai, по порядку
+	FailureThreshold      int      `json:"failure_threshold"`       // Ошибок подряд до временного отключения провайдера (0 - 3)
+	CooldownSeconds       int      `json:"cooldown_seconds"`        // Время отключения провайдера в секундах (0 - 60)
+	AttemptTimeoutSeconds int      `json:"attempt_timeout_seconds"` // Время ожидания ответа провайдера до переключения (0 - 45)
+}
+
+// AIRateLimitConfig содержит ограничения частоты запросов к AI.
+// Нулевое значение означает значение по умолчанию, -1 отключает ограничение.
+type AIRateLimitConfig struct {
+	UserPerMinute   int      `json:"user_per_minute"`        // Запросов в минуту от пользователя (по умолчанию 5)
+	GuildPerMinute  int      `json:"guild_per_minute"`       // Запросов в минуту с сервера (по умолчанию 30)
+	GlobalPerMinute int      `json:"global_per_minute"`      // Запросов в минуту ко всему боту (по умолчанию 60)
+	DailyQuota      int      `json:"daily_quota"`            // Запросов в сутки от пользователя (по умолчанию 100)
+	ExemptRoles     []string `json:"exempt_roles,omitempty"` // Роли, на которые ограничения не распространяются
+}
+
+// AIModerationConfig содержит настройки проверки сообщений AI классификатором.
+// Классификатор только создает репорты для модераторов и никого не наказывает сам.
+type AIModerationConfig struct {
+	Enabled         bool               `json:"enabled"`                     // Включить проверку сообщений
+	Channels        []string           `json:"channels,omitempty"`          // Проверяемые каналы
+	Provider        string             `json:"provider,omitempty"`          // Провайдер классификатора (пусто - цепочка по умолчанию)
+	Thresholds      map[string]float64 `json:"thresholds,omitempty"`        // Пороги категорий от 0 до 1 (пусто - toxicity, spam и nsfw по 0.8)
+	ReportChannelID string             `json:"report_channel_id,omitempty"` // Канал для репортов (пусто - очередь репортов сервера или канал сообщения)
+	MinLength       int                `json:"min_length"`                  // Сообщения короче не проверяются (0 - 5 символов)
+	ReportCooldown  int                `json:"report_cooldown_minutes"`     // Интервал между репортами на одного пользователя в минутах (0 - 10)
+}
+
+// CommandsConfig содержит настройки регистрации слеш-команд
+type CommandsConfig struct {
+	Scope    string   `json:"scope"`               // global - глобальные команды, guild - команды серверов (для разработки)
+	GuildIDs []string `json:"guild_ids,omitempty"` // Серверы для режима guild (пусто - все серверы бота); в режиме global с них удаляются команды
+}
+
+// ModerationConfig содержит каналы модерации сервера
+type ModerationConfig struct {
+	QueueChannelID string `json:"queue_channel_id"` // Закрытый канал очереди репортов (пусто - канал, в котором отправлен репорт)
+	LogChannelID   string `json:"log_channel_id"`   // Канал журнала действий модераторов (пусто - журнал не ведется)
+}
+
+// Режимы применения банов
+const (
+	BanModeMessages = "messages" // Бот удаляет сообщения забаненного пользователя
+	BanModeDiscord  = "discord"  // Бан или тайм-аут через Discord с удалением сообщений при нехватке прав
+)
+
+// BanEnforcementConfig содержит настройки применения банов
+type BanEnforcementConfig struct {
+	Mode              string `json:"mode"`                // messages или discord (пусто - messages)
+	DeleteMessageDays int    `json:"delete_message_days"` // За сколько дней удалить сообщения при бане на сервере (0-7)
+	TimeoutTemporary  bool   `json:"timeout_temporary"`   // Временные баны до 28 дней применять тайм-аутом вместо бана на сервере
+}
+
+// EscalationStep - ступень лестницы наказаний: действие, применяемое автоматически
+// при достижении числа предупреждений пользователя на сервере
+type EscalationStep struct {
+	Warnings int    `json:"warnings"`           // Число предупреждений, при котором применяется действие
+	Action   string `json:"action"`             // timeout, kick или ban
+	Duration string `json:"duration,omitempty"` // Длительность тайм-аута или бана, например 1h, 7d или 1w2d (пусто или permanent - бан навсегда)
+}
+
+// DefaultGuildKey - ключ настроек, применяемых к серверам без собственных настроек
+const DefaultGuildKey = "default"
+
 // Config contains bot settings
 type Config struct {
-	Token           string             `json:"token"`            // Discord bot token
-	Prefix          string             `json:"prefix"`           // Command prefix
-	GeminiAPIKey    string             `json:"gemini_api_key"`   // API key for Gemini
-	GrokAPIKey      string             `json:"grok_api_key"`     // API key for Grok
-	ChatGPTAPIKey   string             `json:"chatgpt_api_key"`  // API key for ChatGPT
-	QwenAPIKey      string             `json:"qwen_api_key"`     // API key for Qwen
-	ClaudeAPIKey    string             `json:"claude_api_key"`   // API key for Claude
-	DefaultAI       string             `json:"default_ai"`       // Default AI provider
-	ReportThreshold int                `json:"report_threshold"` // Report threshold for auto-ban
-	AdminRoleID     string             `json:"admin_role_id"`    // Administrator role ID
-	ModRoleID       string             `json:"mod_role_id"`      // Moderator role ID
-	DefaultLanguage string             `json:"default_language"` // Default bot language (ru, en, uk, de, zh)
-	BotName         string             `json:"bot_name"`         // Discord bot name
-	WebInterface    WebInterfaceConfig `json:"web_interface"`    // Web interface settings
+	Token           string                      `json:"token"`                  // Discord bot token
+	Prefix          string                      `json:"prefix"`                 // Command prefix
+	GeminiAPIKey    string                      `json:"gemini_api_key"`         // API key for Gemini
+	GrokAPIKey      string                      `json:"grok_api_key"`           // API key for Grok
+	ChatGPTAPIKey   string                      `json:"chatgpt_api_key"`        // API key for ChatGPT
+	QwenAPIKey      string                      `json:"qwen_api_key"`           // API key for Qwen
+	ClaudeAPIKey    string                      `json:"claude_api_key"`         // API key for Claude
+	DefaultAI       string                      `json:"default_ai"`             // Default AI provider
+	AIProviders     map[string]AIProviderConfig `json:"ai_providers,omitempty"` // Per-provider AI settings
+	AIFallback      AIFallbackConfig            `json:"ai_fallback"`            // AI provider fallback chain settings
+	AIMemory        AIMemoryConfig              `json:"ai_memory"`              // AI conversation memory settings
+	AIRateLimit     AIRateLimitConfig           `json:"ai_rate_limit"`          // AI rate limits and daily quotas
+	AIModeration    AIModerationConfig          `json:"ai_moderation"`          // AI message moderation settings
+	Commands        CommandsConfig              `json:"commands"`               // Slash command registration settings
+	Moderation      map[string]ModerationConfig `json:"moderation,omitempty"`   // Moderation channels by guild ID ("default" applies to other guilds)
+	BanEnforcement  BanEnforcementConfig        `json:"ban_enforcement"`        // Ban enforcement settings
+	Escalation      []EscalationStep            `json:"escalation,omitempty"`   // Automatic punishments by warning count (empty - 7 day ban at report_threshold warnings)
+	ReportThreshold int                         `json:"report_threshold"`       // Warning count for the default 7 day auto-ban
+	AdminRoleID     string                      `json:"admin_role_id"`          // Administrator role ID
+	ModRoleID       string                      `json:"mod_role_id"`            // Moderator role ID
+	DefaultLanguage string                      `json:"default_language"`       // Default bot language (ru, en, uk, de, zh)
+	BotName         string                      `json:"bot_name"`               // Discord bot name
+	WebInterface    WebInterfaceConfig          `json:"web_interface"`          // Web interface settings
 }
 
 // Load loads configuration from config.json file
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// GetAIPersonas возвращает персоны AI сервера, упорядоченные по имени
+func GetAIPersonas(guildID string) ([]AIPersona, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetAIPersonas(guildID)
+}
+
+// GetAIPersona возвращает персону AI сервера по имени или nil, если она не найдена
+func GetAIPersona(guildID, name string) (*AIPersona, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetAIPersona(guildID, name)
+}
+
+// SaveAIPersona создает или обновляет персону AI сервера.
+// Если персона отмечена как персона по умолчанию, отметка снимается с остальных персон сервера.
+func SaveAIPersona(persona AIPersona) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.SaveAIPersona(persona)
+}
+
+// DeleteAIPersona удаляет персону AI сервера
+func DeleteAIPersona(guildID, name string) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.DeleteAIPersona(guildID, name)
+}
+
+// joinChannels преобразует список каналов персоны в строку для хранения
+func joinChannels(channels []string) string {
+	return strings.Join(channels, ",")
+}
+
+// splitChannels восстанавливает список каналов персоны из строки
+func splitChannels(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// rowScanner - общий интерфейс sql.Row и sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAIPersona читает персону из строки результата SQL запроса
+func scanAIPersona(row rowScanner) (AIPersona, error) {
+	var persona AIPersona
+	var provider, channels sql.NullString
+	var temperature sql.NullFloat64
+	var isDefault int
+
+	err := row.Scan(&persona.GuildID, &persona.Name, &persona.SystemPrompt, &provider, &temperature, &channels, &isDefault)
+	if err != nil {
+		return persona, err
+	}
+
+	persona.Provider = provider.String
+	if temperature.Valid {
+		value := temperature.Float64
+		persona.Temperature = &value
+	}
+	persona.AllowedChannels = splitChannels(channels.String)
+	persona.IsDefault = isDefault != 0
+	return persona, nil
+}
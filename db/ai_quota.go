@@ -0,0 +1,37 @@
+package db
+
+// GetAIQuota возвращает использование AI пользователем за день и его индивидуальный лимит
+func GetAIQuota(userID, day string) (*AIQuota, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetAIQuota(userID, day)
+}
+
+// IncrementAIQuota увеличивает счетчик запросов пользователя к AI за день
+func IncrementAIQuota(userID, day string) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.IncrementAIQuota(userID, day)
+}
+
+// ResetAIQuota обнуляет счетчик запросов пользователя к AI за день
+func ResetAIQuota(userID, day string) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.ResetAIQuota(userID, day)
+}
+
+// SetAIQuotaLimit устанавливает индивидуальный дневной лимит пользователя (nil - лимит из конфигурации)
+func SetAIQuotaLimit(userID string, limit *int) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.SetAIQuotaLimit(userID, limit)
+}
@@ -0,0 +1,19 @@
+package db
+
+// RecordAIUsage сохраняет сведения об обращении к провайдеру AI
+func RecordAIUsage(record AIUsageRecord) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.RecordAIUsage(record)
+}
+
+// GetAIUsageSummary возвращает использование AI по дням и провайдерам начиная с указанной даты (2006-01-02)
+func GetAIUsageSummary(since string) ([]AIUsageSummary, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetAIUsageSummary(since)
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"database/sql"
 	"discord-bot/config"
 	"fmt"
 	"time"
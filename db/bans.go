@@ -0,0 +1,118 @@
+package db
+
+import "database/sql"
+
+// BanEnforcement - способ применения бана в Discord
+type BanEnforcement string
+
+const (
+	BanEnforcementMessages BanEnforcement = "messages" // Бот удаляет сообщения забаненного пользователя
+	BanEnforcementGuildBan BanEnforcement = "ban"      // Пользователь забанен на сервере через Discord
+	BanEnforcementTimeout  BanEnforcement = "timeout"  // Пользователю выдан тайм-аут в Discord
+)
+
+// RecordBan сохраняет бан вместе с сервером и способом его применения и возвращает ID записи
+func RecordBan(ban Ban) (int64, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return 0, err
+	}
+	return provider.AddBan(ban)
+}
+
+// GetActiveBans возвращает действующие баны, начиная с последнего
+func GetActiveBans() ([]Ban, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetActiveBans()
+}
+
+// GetBansByUser возвращает все баны пользователя, включая истекшие и снятые, начиная с последнего
+func GetBansByUser(userID string) ([]Ban, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetBansByUser(userID)
+}
+
+// GetExpiredBans возвращает истекшие баны, которые еще не сняты в Discord и не отмечены снятыми
+func GetExpiredBans() ([]Ban, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetExpiredBans()
+}
+
+// LiftBan отмечает бан снятым. liftedBy - модератор, снявший бан (пусто - бан истек)
+func LiftBan(banID int64, liftedBy string) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.LiftBan(banID, liftedBy)
+}
+
+// activeBanCondition возвращает SQL условие действующего бана. now - плейсхолдер текущего времени
+func activeBanCondition(now string) string {
+	return "lifted_at IS NULL AND (expires_at IS NULL OR expires_at > " + now + ")"
+}
+
+// banColumns - столбцы таблицы bans в порядке, ожидаемом scanBan
+const banColumns = "id, user_id, reason, admin_id, timestamp, expires_at, guild_id, enforcement, lifted_at, lifted_by"
+
+// scanBan читает бан из строки результата SQL запроса
+func scanBan(row rowScanner) (Ban, error) {
+	var ban Ban
+	var timestamp, expiresAt, liftedAt timestampValue
+	var guildID, enforcement, liftedBy sql.NullString
+
+	err := row.Scan(&ban.ID, &ban.UserID, &ban.Reason, &ban.AdminID, &timestamp, &expiresAt,
+		&guildID, &enforcement, &liftedAt, &liftedBy)
+	if err != nil {
+		return ban, err
+	}
+
+	ban.Timestamp = timestamp.Time
+	if !expiresAt.IsZero() {
+		t := expiresAt.Time
+		ban.ExpiresAt = &t
+	}
+	if !liftedAt.IsZero() {
+		t := liftedAt.Time
+		ban.LiftedAt = &t
+	}
+	ban.LiftedBy = liftedBy.String
+	ban.GuildID = guildID.String
+	ban.Enforcement = BanEnforcement(enforcement.String)
+	if ban.Enforcement == "" {
+		ban.Enforcement = BanEnforcementMessages
+	}
+	return ban, nil
+}
+
+// banEnforcement возвращает способ применения бана для сохранения (пусто - удаление сообщений)
+func banEnforcement(ban Ban) string {
+	if ban.Enforcement == "" {
+		return string(BanEnforcementMessages)
+	}
+	return string(ban.Enforcement)
+}
+
+// scanBans читает все баны из результата SQL запроса и закрывает его
+func scanBans(rows *sql.Rows) ([]Ban, error) {
+	defer rows.Close()
+
+	var bans []Ban
+	for rows.Next() {
+		ban, err := scanBan(rows)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, ban)
+	}
+	return bans, rows.Err()
+}
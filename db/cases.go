@@ -0,0 +1,164 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+// CaseAction - действие модератора, сохраняемое как модерационный случай
+type CaseAction string
+
+const (
+	CaseWarn          CaseAction = "warn"           // Предупреждение
+	CaseTimeout       CaseAction = "timeout"        // Тайм-аут в Discord
+	CaseKick          CaseAction = "kick"           // Исключение с сервера
+	CaseBan           CaseAction = "ban"            // Бан
+	CaseUnban         CaseAction = "unban"          // Снятие бана модератором или по истечении срока
+	CaseReportConfirm CaseAction = "report_confirm" // Подтверждение репорта
+)
+
+// maxCaseNumberAttempts - попыток сохранить случай, если его номер одновременно занял другой процесс
+const maxCaseNumberAttempts = 5
+
+// errCaseNumberTaken возвращается провайдером, если номер случая на сервере уже занят
+var errCaseNumberTaken = errors.New("номер модерационного случая уже занят")
+
+// caseNumberMu не дает двум случаям одного процесса получить одинаковый номер на сервере.
+// Между процессами (второй экземпляр бота, веб-интерфейс) номер защищает уникальный индекс
+var caseNumberMu sync.Mutex
+
+// AddCase сохраняет модерационный случай и возвращает его номер на сервере. Если номер занят
+// другим процессом, случай сохраняется со следующим номером
+func AddCase(c ModerationCase) (int64, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return 0, err
+	}
+
+	caseNumberMu.Lock()
+	defer caseNumberMu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		number, err := provider.AddCase(c)
+		if !errors.Is(err, errCaseNumberTaken) || attempt == maxCaseNumberAttempts {
+			return number, err
+		}
+	}
+}
+
+// isUniqueViolation проверяет, отклонила ли СУБД запись из-за нарушения уникального индекса
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505" // unique_violation
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1062 // ER_DUP_ENTRY
+	}
+
+	// Драйвер Firebird возвращает только текст ошибки
+	return strings.Contains(err.Error(), "violation of PRIMARY or UNIQUE KEY constraint")
+}
+
+// GetCase возвращает модерационный случай по номеру на сервере или nil, если он не найден
+func GetCase(guildID string, number int64) (*ModerationCase, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetCase(guildID, number)
+}
+
+// UpdateCaseReason меняет причину модерационного случая и запоминает, кто и когда ее изменил
+func UpdateCaseReason(guildID string, number int64, reason, moderatorID string) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.UpdateCaseReason(guildID, number, reason, moderatorID)
+}
+
+// CountCases возвращает количество случаев с действием action у пользователя на сервере
+func CountCases(guildID, userID string, action CaseAction) (int, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return 0, err
+	}
+	return provider.CountCases(guildID, userID, action)
+}
+
+// caseColumns - столбцы таблицы cases в порядке, ожидаемом scanCase
+const caseColumns = "id, guild_id, number, action_type, user_id, moderator_id, reason, duration_seconds, report_id, ban_id, evidence, created_at, updated_by, updated_at"
+
+// scanCase читает модерационный случай из строки результата SQL запроса
+func scanCase(row rowScanner) (ModerationCase, error) {
+	var c ModerationCase
+	var action string
+	var duration, reportID, banID sql.NullInt64
+	var evidence, updatedBy sql.NullString
+	var createdAt, updatedAt timestampValue
+
+	err := row.Scan(&c.ID, &c.GuildID, &c.Number, &action, &c.UserID, &c.ModeratorID, &c.Reason,
+		&duration, &reportID, &banID, &evidence, &createdAt, &updatedBy, &updatedAt)
+	if err != nil {
+		return c, err
+	}
+
+	c.Action = CaseAction(action)
+	if duration.Valid {
+		d := time.Duration(duration.Int64) * time.Second
+		c.Duration = &d
+	}
+	c.ReportID = reportID.Int64
+	c.BanID = banID.Int64
+	if evidence.String != "" {
+		c.Evidence = strings.Split(evidence.String, ",")
+	}
+	c.Timestamp = createdAt.Time
+	c.UpdatedBy = updatedBy.String
+	if !updatedAt.IsZero() {
+		t := updatedAt.Time
+		c.UpdatedAt = &t
+	}
+	return c, nil
+}
+
+// caseDuration возвращает длительность случая в секундах для сохранения (nil - без длительности)
+func caseDuration(c ModerationCase) interface{} {
+	if c.Duration == nil {
+		return nil
+	}
+	return int64(c.Duration.Seconds())
+}
+
+// caseEvidence возвращает доказательства случая для сохранения одной строкой
+func caseEvidence(c ModerationCase) interface{} {
+	if len(c.Evidence) == 0 {
+		return nil
+	}
+	return strings.Join(c.Evidence, ",")
+}
+
+// nullableID возвращает ID для сохранения (nil - связи нет)
+func nullableID(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
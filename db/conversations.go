@@ -0,0 +1,72 @@
+package db
+
+import "database/sql"
+
+// AddConversationMessage сохраняет сообщение диалога с AI в текущей базе данных
+func AddConversationMessage(msg ConversationMessage) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.AddConversationMessage(msg)
+}
+
+// AddConversationReplies связывает сообщения Discord, в которых выведен ответ модели, с диалогом,
+// чтобы ответ на любое из них продолжал диалог
+func AddConversationReplies(sessionID string, messageIDs []string) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.AddConversationReplies(sessionID, messageIDs)
+}
+
+// GetConversationMessages возвращает последние limit сообщений диалога в хронологическом порядке
+func GetConversationMessages(sessionID string, limit int) ([]ConversationMessage, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetConversationMessages(sessionID, limit)
+}
+
+// GetConversationSession возвращает диалог, к которому относится сообщение бота, или пустую строку
+func GetConversationSession(messageID string) (string, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return "", err
+	}
+	return provider.GetConversationSession(messageID)
+}
+
+// ClearConversation удаляет историю диалога
+func ClearConversation(sessionID string) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.ClearConversation(sessionID)
+}
+
+// reverseConversation переворачивает порядок сообщений диалога
+func reverseConversation(messages []ConversationMessage) {
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+}
+
+// querySession выполняет запросы поиска диалога по сообщению по порядку и возвращает первый
+// найденный диалог или пустую строку
+func querySession(db *sql.DB, messageID string, queries ...string) (string, error) {
+	for _, query := range queries {
+		var sessionID string
+		err := db.QueryRow(query, messageID).Scan(&sessionID)
+		if err == nil {
+			return sessionID, nil
+		}
+		if err != sql.ErrNoRows {
+			return "", err
+		}
+	}
+	return "", nil
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,12 +14,43 @@ import (
 type DatabaseProvider interface {
 	Initialize(config DatabaseConfig) error
 	Close() error
-	AddReport(reportedUserID, reporterID, reason string) (int64, error)
+	AddReport(report Report) (int64, error)
 	ConfirmReport(reportID int64, adminID string) error
-	GetReportsByUser(userID string) ([]Report, error)
-	GetReportCount(userID string) (int, error)
-	AddBan(userID, reason, adminID string, duration *time.Duration) error
+	GetReportsByUser(guildID, userID string) ([]Report, error)
+	GetReport(reportID int64) (*Report, error)
+	SetReportStatus(reportID int64, status ReportStatus, moderatorID string) error
+	ReopenReport(reportID int64) error
+	SetReportNote(reportID int64, note string) error
+	GetReportCount(guildID, userID string) (int, error)
+	AddBan(ban Ban) (int64, error)
 	GetActiveBan(userID string) (*Ban, error)
+	GetActiveBans() ([]Ban, error)
+	GetBansByUser(userID string) ([]Ban, error)
+	GetExpiredBans() ([]Ban, error)
+	LiftBan(banID int64, liftedBy string) error
+	AddConversationMessage(msg ConversationMessage) error
+	AddConversationReplies(sessionID string, messageIDs []string) error
+	GetConversationMessages(sessionID string, limit int) ([]ConversationMessage, error)
+	GetConversationSession(messageID string) (string, error)
+	ClearConversation(sessionID string) error
+	GetAIQuota(userID, day string) (*AIQuota, error)
+	IncrementAIQuota(userID, day string) error
+	ResetAIQuota(userID, day string) error
+	SetAIQuotaLimit(userID string, limit *int) error
+	RecordAIUsage(record AIUsageRecord) error
+	GetAIUsageSummary(since string) ([]AIUsageSummary, error)
+	GetAIPersonas(guildID string) ([]AIPersona, error)
+	GetAIPersona(guildID, name string) (*AIPersona, error)
+	SaveAIPersona(persona AIPersona) error
+	DeleteAIPersona(guildID, name string) error
+	SaveReportMessage(msg ReportMessage) error
+	GetReportMessage(messageID string) (*ReportMessage, error)
+	SetReportMessageStatus(messageID string, status ReportStatus) error
+	GetPendingReportMessages(guildID string) ([]ReportMessage, error)
+	AddCase(c ModerationCase) (int64, error)
+	GetCase(guildID string, number int64) (*ModerationCase, error)
+	UpdateCaseReason(guildID string, number int64, reason, moderatorID string) error
+	CountCases(guildID, userID string, action CaseAction) (int, error)
 	GetType() string
 }
 
@@ -35,21 +67,118 @@ type DatabaseConfig struct {
 
 type Report struct {
 	ID             int64
+	GuildID        string // Сервер, на котором создан репорт (пусто - репорт создан до сохранения сервера)
 	ReportedUserID string
 	ReporterID     string
 	Reason         string
 	Timestamp      time.Time
 	Confirmed      bool
 	ConfirmedBy    string
+	Status         ReportStatus
+	Note           string     // Заметка модератора
+	DecidedBy      string     // Модератор, принявший решение
+	DecidedAt      *time.Time // Время решения (nil - репорт не рассмотрен)
 }
 
 type Ban struct {
+	ID          int64
+	UserID      string
+	Reason      string
+	AdminID     string
+	Timestamp   time.Time
+	ExpiresAt   *time.Time
+	GuildID     string         // Сервер, на котором применен бан (пусто - бан без сервера)
+	Enforcement BanEnforcement // Способ применения бана в Discord
+	LiftedAt    *time.Time     // Время снятия бана (nil - бан не снят)
+	LiftedBy    string         // Модератор, снявший бан (пусто - бан истек)
+}
+
+// ConversationMessage - сообщение из истории диалога с AI
+type ConversationMessage struct {
 	ID        int64
-	UserID    string
-	Reason    string
-	AdminID   string
+	SessionID string // Канал или ветка Discord, к которой относится диалог
+	Role      string // user или assistant
+	Content   string
+	MessageID string // ID сообщения Discord (для ответов бота)
 	Timestamp time.Time
-	ExpiresAt *time.Time
+}
+
+// AIQuota - использование AI пользователем за день
+type AIQuota struct {
+	UserID     string
+	Day        string // Дата в формате 2006-01-02 (UTC)
+	Used       int    // Количество запросов за день
+	DailyLimit *int   // Индивидуальный лимит (nil - лимит из конфигурации)
+}
+
+// AIUsageRecord - одно обращение к провайдеру AI
+type AIUsageRecord struct {
+	ID               int64
+	UserID           string
+	GuildID          string
+	Provider         string
+	Model            string
+	LatencyMs        int64
+	PromptTokens     int
+	CompletionTokens int
+	Success          bool
+	Error            string
+	Timestamp        time.Time
+}
+
+// AIUsageSummary - использование провайдера AI за день
+type AIUsageSummary struct {
+	Day              string // Дата в формате 2006-01-02 (UTC)
+	Provider         string
+	Requests         int64
+	Failures         int64
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalLatencyMs   int64
+}
+
+// AIPersona - персона AI сервера: системный промпт и настройки генерации
+type AIPersona struct {
+	GuildID         string
+	Name            string
+	SystemPrompt    string
+	Provider        string   // Предпочтительный провайдер (пусто - цепочка по умолчанию)
+	Temperature     *float64 // Температура (nil - из настроек провайдера)
+	AllowedChannels []string // Каналы, в которых доступна персона (пусто - все каналы)
+	IsDefault       bool     // Персона по умолчанию для сервера
+}
+
+// ReportMessage - сообщение репорта в канале модерации. Позволяет рассматривать репорт
+// кнопками сообщения и после перезапуска бота
+type ReportMessage struct {
+	MessageID      string // ID сообщения Discord
+	ReportID       int64
+	GuildID        string
+	ChannelID      string
+	ReportedUserID string
+	ReporterID     string
+	Reason         string
+	Status         ReportStatus
+	Timestamp      time.Time
+}
+
+// ModerationCase - модерационный случай: действие модератора или автоматическое наказание.
+// Номер случая последователен в пределах сервера
+type ModerationCase struct {
+	ID          int64
+	GuildID     string
+	Number      int64 // Номер случая на сервере
+	Action      CaseAction
+	UserID      string // Пользователь, к которому применено действие
+	ModeratorID string
+	Reason      string
+	Duration    *time.Duration // Длительность тайм-аута или бана (nil - без длительности или навсегда)
+	ReportID    int64          // Связанный репорт (0 - без репорта)
+	BanID       int64          // Связанный бан (0 - без бана)
+	Evidence    []string       // Сообщения-доказательства в виде channelID/messageID
+	Timestamp   time.Time
+	UpdatedBy   string     // Модератор, последним изменивший причину
+	UpdatedAt   *time.Time // Время последнего изменения причины (nil - не изменялась)
 }
 
 var AvailableProviders = map[string]DatabaseProvider{
@@ -65,6 +194,17 @@ var AvailableProviders = map[string]DatabaseProvider{
 
 var CurrentProvider DatabaseProvider
 
+// ErrNotInitialized возвращается, если база данных не была инициализирована через Initialize
+var ErrNotInitialized = errors.New("база данных не инициализирована")
+
+// currentProvider возвращает активный провайдер базы данных
+func currentProvider() (DatabaseProvider, error) {
+	if CurrentProvider == nil {
+		return nil, ErrNotInitialized
+	}
+	return CurrentProvider, nil
+}
+
 func Initialize(config DatabaseConfig) error {
 	provider, ok := AvailableProviders[config.Type]
 	if !ok {
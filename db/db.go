@@ -9,6 +9,8 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// DB - соединение с базой данных, открытое через InitializeDB.
+// Если активен провайдер (Initialize), функции репортов и банов этого файла работают через него
 var DB *sql.DB
 
 func InitializeDB() error {
@@ -80,6 +82,10 @@ func createTables() error {
 
 // AddReport добавляет новый репорт в базу данных
 func AddReport(reportedUserID, reporterID, reason string) (int64, error) {
+	if CurrentProvider != nil {
+		return CurrentProvider.AddReport(Report{ReportedUserID: reportedUserID, ReporterID: reporterID, Reason: reason})
+	}
+
 	result, err := DB.Exec(
 		"INSERT INTO reports (reported_user_id, reporter_id, reason, timestamp) VALUES (?, ?, ?, ?)",
 		reportedUserID, reporterID, reason, time.Now(),
@@ -98,6 +104,10 @@ func AddReport(reportedUserID, reporterID, reason string) (int64, error) {
 
 // ConfirmReport подтверждает репорт администратором
 func ConfirmReport(reportID int64, adminID string) error {
+	if CurrentProvider != nil {
+		return CurrentProvider.ConfirmReport(reportID, adminID)
+	}
+
 	_, err := DB.Exec(
 		"UPDATE reports SET confirmed = TRUE, confirmed_by = ? WHERE id = ?",
 		adminID, reportID,
@@ -105,12 +115,16 @@ func ConfirmReport(reportID int64, adminID string) error {
 	return err
 }
 
-// GetReportCount возвращает количество подтвержденных репортов на пользователя
-func GetReportCount(userID string) (int, error) {
+// GetReportCount возвращает количество подтвержденных репортов на пользователя на сервере
+func GetReportCount(guildID, userID string) (int, error) {
+	if CurrentProvider != nil {
+		return CurrentProvider.GetReportCount(guildID, userID)
+	}
+
 	var count int
 	err := DB.QueryRow(
-		"SELECT COUNT(*) FROM reports WHERE reported_user_id = ? AND confirmed = TRUE",
-		userID,
+		"SELECT COUNT(*) FROM reports WHERE guild_id = ? AND reported_user_id = ? AND confirmed = TRUE",
+		guildID, userID,
 	).Scan(&count)
 
 	return count, err
@@ -118,6 +132,16 @@ func GetReportCount(userID string) (int, error) {
 
 // AddBan добавляет запись о бане пользователя
 func AddBan(userID, reason, adminID string, duration *time.Duration) error {
+	if CurrentProvider != nil {
+		ban := Ban{UserID: userID, Reason: reason, AdminID: adminID}
+		if duration != nil {
+			expires := time.Now().Add(*duration)
+			ban.ExpiresAt = &expires
+		}
+		_, err := CurrentProvider.AddBan(ban)
+		return err
+	}
+
 	var expiresAt *time.Time
 	if duration != nil {
 		expires := time.Now().Add(*duration)
@@ -139,6 +163,11 @@ func AddBan(userID, reason, adminID string, duration *time.Duration) error {
 
 // IsUserBanned проверяет, забанен ли пользователь
 func IsUserBanned(userID string) (bool, error) {
+	if CurrentProvider != nil {
+		ban, err := CurrentProvider.GetActiveBan(userID)
+		return ban != nil, err
+	}
+
 	var count int
 	err := DB.QueryRow(
 		"SELECT COUNT(*) FROM bans WHERE user_id = ? AND (expires_at IS NULL OR expires_at > ?)",
@@ -64,6 +64,18 @@ func (p *MariaDBProvider) createTables() error {
 		return err
 	}
 
+	// Столбцы рассмотрения репорта
+	err = migrateReportColumns(p.db, []string{
+		"ALTER TABLE reports ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'pending'",
+		"ALTER TABLE reports ADD COLUMN note TEXT",
+		"ALTER TABLE reports ADD COLUMN decided_by VARCHAR(255)",
+		"ALTER TABLE reports ADD COLUMN decided_at DATETIME",
+		"ALTER TABLE reports ADD COLUMN guild_id VARCHAR(255)",
+	})
+	if err != nil {
+		return err
+	}
+
 	// Таблица для хранения банов
 	_, err = p.db.Exec(`
 		CREATE TABLE IF NOT EXISTS bans (
@@ -79,6 +91,152 @@ func (p *MariaDBProvider) createTables() error {
 		return err
 	}
 
+	// Столбцы сервера, способа применения и снятия бана
+	err = addMissingColumns(p.db, []string{
+		"ALTER TABLE bans ADD COLUMN guild_id VARCHAR(255)",
+		"ALTER TABLE bans ADD COLUMN enforcement VARCHAR(16) NOT NULL DEFAULT 'messages'",
+		"ALTER TABLE bans ADD COLUMN lifted_at DATETIME",
+		"ALTER TABLE bans ADD COLUMN lifted_by VARCHAR(255)",
+	})
+	if err != nil {
+		return err
+	}
+
+	// Таблица для хранения истории диалогов с AI
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_conversations (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			session_id VARCHAR(255) NOT NULL,
+			role VARCHAR(32) NOT NULL,
+			content TEXT NOT NULL,
+			message_id VARCHAR(255),
+			timestamp DATETIME NOT NULL,
+			INDEX idx_ai_conversations_session (session_id)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Таблица сообщений Discord с ответами AI: длинный ответ занимает несколько сообщений,
+	// и ответ на любое из них продолжает диалог
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_conversation_replies (
+			message_id VARCHAR(255) PRIMARY KEY,
+			session_id VARCHAR(255) NOT NULL,
+			INDEX idx_ai_conversation_replies_session (session_id)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Таблицы для учета дневных квот AI
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_quota_usage (
+			user_id VARCHAR(255) NOT NULL,
+			usage_day VARCHAR(10) NOT NULL,
+			used INT NOT NULL DEFAULT 0,
+			PRIMARY KEY (user_id, usage_day)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_quota_limits (
+			user_id VARCHAR(255) NOT NULL PRIMARY KEY,
+			daily_limit INT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Таблица для учета обращений к провайдерам AI
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_usage (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id VARCHAR(255) NOT NULL,
+			guild_id VARCHAR(255),
+			provider VARCHAR(64) NOT NULL,
+			model VARCHAR(255),
+			latency_ms INT NOT NULL,
+			prompt_tokens INT NOT NULL DEFAULT 0,
+			completion_tokens INT NOT NULL DEFAULT 0,
+			success INT NOT NULL,
+			error_message TEXT,
+			usage_day VARCHAR(10) NOT NULL,
+			timestamp DATETIME NOT NULL,
+			INDEX idx_ai_usage_day (usage_day)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Таблица для хранения персон AI серверов
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_personas (
+			guild_id VARCHAR(255) NOT NULL,
+			name VARCHAR(64) NOT NULL,
+			system_prompt TEXT NOT NULL,
+			provider VARCHAR(64),
+			temperature DOUBLE,
+			allowed_channels TEXT,
+			is_default INT NOT NULL DEFAULT 0,
+			PRIMARY KEY (guild_id, name)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Таблица для хранения сообщений репортов в каналах модерации
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS report_messages (
+			message_id VARCHAR(255) PRIMARY KEY,
+			report_id BIGINT NOT NULL,
+			guild_id VARCHAR(255),
+			channel_id VARCHAR(255) NOT NULL,
+			reported_user_id VARCHAR(255) NOT NULL,
+			reporter_id VARCHAR(255) NOT NULL,
+			reason TEXT NOT NULL,
+			status VARCHAR(16) NOT NULL DEFAULT 'pending',
+			created_at DATETIME NOT NULL,
+			INDEX idx_report_messages_status (status)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Таблица модерационных случаев с номерами, уникальными и последовательными в пределах сервера
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS cases (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			guild_id VARCHAR(255) NOT NULL,
+			number BIGINT NOT NULL,
+			action_type VARCHAR(16) NOT NULL,
+			user_id VARCHAR(255) NOT NULL,
+			moderator_id VARCHAR(255) NOT NULL,
+			reason TEXT NOT NULL,
+			duration_seconds BIGINT,
+			report_id BIGINT,
+			ban_id BIGINT,
+			evidence TEXT,
+			created_at DATETIME NOT NULL,
+			updated_by VARCHAR(255),
+			updated_at DATETIME,
+			INDEX idx_cases_user (guild_id, user_id),
+			UNIQUE INDEX idx_cases_guild_number (guild_id, number)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -91,10 +249,10 @@ func (p *MariaDBProvider) Close() error {
 }
 
 // AddReport добавляет новый репорт в базу данных
-func (p *MariaDBProvider) AddReport(reportedUserID, reporterID, reason string) (int64, error) {
+func (p *MariaDBProvider) AddReport(report Report) (int64, error) {
 	result, err := p.db.Exec(
-		"INSERT INTO reports (reported_user_id, reporter_id, reason, timestamp) VALUES (?, ?, ?, ?)",
-		reportedUserID, reporterID, reason, time.Now(),
+		"INSERT INTO reports (reported_user_id, reporter_id, reason, timestamp, guild_id) VALUES (?, ?, ?, ?, ?)",
+		report.ReportedUserID, report.ReporterID, report.Reason, time.Now(), report.GuildID,
 	)
 	if err != nil {
 		return 0, err
@@ -110,89 +268,434 @@ func (p *MariaDBProvider) AddReport(reportedUserID, reporterID, reason string) (
 
 // ConfirmReport подтверждает репорт администратором
 func (p *MariaDBProvider) ConfirmReport(reportID int64, adminID string) error {
+	return p.SetReportStatus(reportID, ReportStatusConfirmed, adminID)
+}
+
+// GetReportsByUser получает все репорты на указанного пользователя на сервере
+func (p *MariaDBProvider) GetReportsByUser(guildID, userID string) ([]Report, error) {
+	rows, err := p.db.Query(
+		"SELECT "+reportColumns+" FROM reports WHERE guild_id = ? AND reported_user_id = ? ORDER BY id DESC",
+		guildID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanReports(rows)
+}
+
+// GetReport возвращает репорт по ID или nil, если он не найден
+func (p *MariaDBProvider) GetReport(reportID int64) (*Report, error) {
+	row := p.db.QueryRow("SELECT "+reportColumns+" FROM reports WHERE id = ?", reportID)
+
+	report, err := scanReport(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// SetReportStatus сохраняет решение модератора по репорту
+func (p *MariaDBProvider) SetReportStatus(reportID int64, status ReportStatus, moderatorID string) error {
+	confirmedBy := sql.NullString{String: moderatorID, Valid: status.Confirmed()}
+	result, err := p.db.Exec(
+		"UPDATE reports SET status = ?, confirmed = ?, confirmed_by = ?, decided_by = ?, decided_at = ? WHERE id = ? AND status = 'pending'",
+		string(status), status.Confirmed(), confirmedBy, moderatorID, time.Now(), reportID,
+	)
+	if err != nil {
+		return err
+	}
+	return checkReportReviewed(result)
+}
+
+// ReopenReport возвращает эскалированный репорт к рассмотрению
+func (p *MariaDBProvider) ReopenReport(reportID int64) error {
 	_, err := p.db.Exec(
-		"UPDATE reports SET confirmed = TRUE, confirmed_by = ? WHERE id = ?",
-		adminID, reportID,
+		"UPDATE reports SET status = ?, confirmed = FALSE, confirmed_by = NULL, decided_by = NULL, decided_at = NULL WHERE id = ?",
+		string(ReportStatusPending), reportID,
 	)
 	return err
 }
 
-// GetReportsByUser получает все репорты на указанного пользователя
-func (p *MariaDBProvider) GetReportsByUser(userID string) ([]Report, error) {
+// SetReportNote сохраняет заметку модератора к репорту
+func (p *MariaDBProvider) SetReportNote(reportID int64, note string) error {
+	_, err := p.db.Exec("UPDATE reports SET note = ? WHERE id = ?", note, reportID)
+	return err
+}
+
+// GetReportCount получает количество подтвержденных репортов на пользователя на сервере
+func (p *MariaDBProvider) GetReportCount(guildID, userID string) (int, error) {
+	var count int
+	err := p.db.QueryRow(
+		"SELECT COUNT(*) FROM reports WHERE guild_id = ? AND reported_user_id = ? AND confirmed = TRUE",
+		guildID, userID,
+	).Scan(&count)
+
+	return count, err
+}
+
+// AddBan добавляет новый бан в базу данных
+func (p *MariaDBProvider) AddBan(ban Ban) (int64, error) {
+	var expiresAt interface{}
+	if ban.ExpiresAt != nil {
+		expiresAt = *ban.ExpiresAt
+	}
+
+	result, err := p.db.Exec(
+		"INSERT INTO bans (user_id, reason, admin_id, timestamp, expires_at, guild_id, enforcement) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		ban.UserID, ban.Reason, ban.AdminID, time.Now(), expiresAt, ban.GuildID, banEnforcement(ban),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// GetActiveBan проверяет, есть ли активный бан у пользователя
+func (p *MariaDBProvider) GetActiveBan(userID string) (*Ban, error) {
+	row := p.db.QueryRow(
+		"SELECT "+banColumns+" FROM bans WHERE user_id = ? AND "+activeBanCondition("?")+" ORDER BY id DESC",
+		userID, time.Now(),
+	)
+
+	ban, err := scanBan(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ban, nil
+}
+
+// GetActiveBans возвращает действующие баны, начиная с последнего
+func (p *MariaDBProvider) GetActiveBans() ([]Ban, error) {
 	rows, err := p.db.Query(
-		"SELECT id, reported_user_id, reporter_id, reason, timestamp, confirmed, confirmed_by FROM reports WHERE reported_user_id = ?",
-		userID,
+		"SELECT "+banColumns+" FROM bans WHERE "+activeBanCondition("?")+" ORDER BY id DESC",
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBans(rows)
+}
+
+// GetBansByUser возвращает все баны пользователя, начиная с последнего
+func (p *MariaDBProvider) GetBansByUser(userID string) ([]Ban, error) {
+	rows, err := p.db.Query("SELECT "+banColumns+" FROM bans WHERE user_id = ? ORDER BY id DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBans(rows)
+}
+
+// GetExpiredBans возвращает истекшие баны, которые еще не сняты
+func (p *MariaDBProvider) GetExpiredBans() ([]Ban, error) {
+	rows, err := p.db.Query(
+		"SELECT "+banColumns+" FROM bans WHERE lifted_at IS NULL AND expires_at IS NOT NULL AND expires_at <= ? ORDER BY expires_at",
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBans(rows)
+}
+
+// LiftBan отмечает бан снятым. liftedBy - модератор, снявший бан (пусто - бан истек)
+func (p *MariaDBProvider) LiftBan(banID int64, liftedBy string) error {
+	_, err := p.db.Exec("UPDATE bans SET lifted_at = ?, lifted_by = ? WHERE id = ? AND lifted_at IS NULL", time.Now(), liftedBy, banID)
+	return err
+}
+
+// AddConversationMessage сохраняет сообщение диалога с AI
+func (p *MariaDBProvider) AddConversationMessage(msg ConversationMessage) error {
+	_, err := p.db.Exec(
+		"INSERT INTO ai_conversations (session_id, role, content, message_id, timestamp) VALUES (?, ?, ?, ?, ?)",
+		msg.SessionID, msg.Role, msg.Content, msg.MessageID, time.Now(),
+	)
+	return err
+}
+
+// AddConversationReplies связывает сообщения Discord с ответом модели с диалогом
+func (p *MariaDBProvider) AddConversationReplies(sessionID string, messageIDs []string) error {
+	for _, messageID := range messageIDs {
+		_, err := p.db.Exec("INSERT INTO ai_conversation_replies (message_id, session_id) VALUES (?, ?)", messageID, sessionID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetConversationMessages возвращает последние limit сообщений диалога в хронологическом порядке
+func (p *MariaDBProvider) GetConversationMessages(sessionID string, limit int) ([]ConversationMessage, error) {
+	rows, err := p.db.Query(
+		"SELECT id, session_id, role, content, message_id, timestamp FROM ai_conversations WHERE session_id = ? ORDER BY id DESC LIMIT ?",
+		sessionID, limit,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var reports []Report
+	var messages []ConversationMessage
 	for rows.Next() {
-		var r Report
-		var timestamp time.Time
-		var confirmedBy sql.NullString
-		err := rows.Scan(&r.ID, &r.ReportedUserID, &r.ReporterID, &r.Reason, &timestamp, &r.Confirmed, &confirmedBy)
+		var m ConversationMessage
+		var messageID sql.NullString
+		err := rows.Scan(&m.ID, &m.SessionID, &m.Role, &m.Content, &messageID, &m.Timestamp)
 		if err != nil {
 			return nil, err
 		}
+		m.MessageID = messageID.String
 
-		r.Timestamp = timestamp
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-		if confirmedBy.Valid {
-			r.ConfirmedBy = confirmedBy.String
-		}
+	// Запрос возвращает новые сообщения первыми
+	reverseConversation(messages)
+	return messages, nil
+}
 
-		reports = append(reports, r)
-	}
+// GetConversationSession возвращает диалог, к которому относится сообщение бота, или пустую строку
+func (p *MariaDBProvider) GetConversationSession(messageID string) (string, error) {
+	// Ответы, сохраненные до появления ai_conversation_replies, связаны с диалогом только в ai_conversations
+	return querySession(p.db, messageID,
+		"SELECT session_id FROM ai_conversation_replies WHERE message_id = ?",
+		"SELECT session_id FROM ai_conversations WHERE message_id = ?",
+	)
+}
 
-	return reports, nil
+// ClearConversation удаляет историю диалога
+func (p *MariaDBProvider) ClearConversation(sessionID string) error {
+	if _, err := p.db.Exec("DELETE FROM ai_conversation_replies WHERE session_id = ?", sessionID); err != nil {
+		return err
+	}
+	_, err := p.db.Exec("DELETE FROM ai_conversations WHERE session_id = ?", sessionID)
+	return err
 }
 
-// GetReportCount получает количество подтвержденных репортов на пользователя
-func (p *MariaDBProvider) GetReportCount(userID string) (int, error) {
-	var count int
+// GetAIQuota возвращает использование AI пользователем за день и его индивидуальный лимит
+func (p *MariaDBProvider) GetAIQuota(userID, day string) (*AIQuota, error) {
+	quota := &AIQuota{UserID: userID, Day: day}
+
 	err := p.db.QueryRow(
-		"SELECT COUNT(*) FROM reports WHERE reported_user_id = ? AND confirmed = TRUE",
+		"SELECT used FROM ai_quota_usage WHERE user_id = ? AND usage_day = ?",
+		userID, day,
+	).Scan(&quota.Used)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	var limit sql.NullInt64
+	err = p.db.QueryRow(
+		"SELECT daily_limit FROM ai_quota_limits WHERE user_id = ?",
 		userID,
-	).Scan(&count)
+	).Scan(&limit)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if limit.Valid {
+		dailyLimit := int(limit.Int64)
+		quota.DailyLimit = &dailyLimit
+	}
 
-	return count, err
+	return quota, nil
 }
 
-// AddBan добавляет новый бан в базу данных
-func (p *MariaDBProvider) AddBan(userID, reason, adminID string, duration *time.Duration) error {
-	var expiresAt *time.Time
-	if duration != nil {
-		expires := time.Now().Add(*duration)
-		expiresAt = &expires
+// IncrementAIQuota увеличивает счетчик запросов пользователя к AI за день
+func (p *MariaDBProvider) IncrementAIQuota(userID, day string) error {
+	result, err := p.db.Exec(
+		"UPDATE ai_quota_usage SET used = used + 1 WHERE user_id = ? AND usage_day = ?",
+		userID, day,
+	)
+	if err != nil {
+		return err
 	}
 
-	var expiresAtStr interface{} = nil
-	if expiresAt != nil {
-		expiresAtStr = *expiresAt
+	// Первый запрос за день - создаем запись
+	if affected, err := result.RowsAffected(); err != nil || affected > 0 {
+		return err
 	}
+	_, err = p.db.Exec(
+		"INSERT INTO ai_quota_usage (user_id, usage_day, used) VALUES (?, ?, 1)",
+		userID, day,
+	)
+	return err
+}
 
+// ResetAIQuota обнуляет счетчик запросов пользователя к AI за день
+func (p *MariaDBProvider) ResetAIQuota(userID, day string) error {
 	_, err := p.db.Exec(
-		"INSERT INTO bans (user_id, reason, admin_id, timestamp, expires_at) VALUES (?, ?, ?, ?, ?)",
-		userID, reason, adminID, time.Now(), expiresAtStr,
+		"DELETE FROM ai_quota_usage WHERE user_id = ? AND usage_day = ?",
+		userID, day,
 	)
+	return err
+}
 
+// SetAIQuotaLimit устанавливает индивидуальный дневной лимит пользователя (nil - лимит из конфигурации)
+func (p *MariaDBProvider) SetAIQuotaLimit(userID string, limit *int) error {
+	_, err := p.db.Exec("DELETE FROM ai_quota_limits WHERE user_id = ?", userID)
+	if err != nil || limit == nil {
+		return err
+	}
+
+	_, err = p.db.Exec(
+		"INSERT INTO ai_quota_limits (user_id, daily_limit) VALUES (?, ?)",
+		userID, *limit,
+	)
 	return err
 }
 
-// GetActiveBan проверяет, есть ли активный бан у пользователя
-func (p *MariaDBProvider) GetActiveBan(userID string) (*Ban, error) {
+// RecordAIUsage сохраняет сведения об обращении к провайдеру AI
+func (p *MariaDBProvider) RecordAIUsage(record AIUsageRecord) error {
+	success := 0
+	if record.Success {
+		success = 1
+	}
+
+	_, err := p.db.Exec(
+		"INSERT INTO ai_usage (user_id, guild_id, provider, model, latency_ms, prompt_tokens, completion_tokens, success, error_message, usage_day, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		record.UserID, record.GuildID, record.Provider, record.Model, record.LatencyMs,
+		record.PromptTokens, record.CompletionTokens, success, record.Error, record.Timestamp.UTC().Format("2006-01-02"), record.Timestamp,
+	)
+	return err
+}
+
+// GetAIUsageSummary возвращает использование AI по дням и провайдерам начиная с указанной даты (2006-01-02)
+func (p *MariaDBProvider) GetAIUsageSummary(since string) ([]AIUsageSummary, error) {
+	rows, err := p.db.Query(
+		"SELECT usage_day, provider, COUNT(*), SUM(1 - success), SUM(prompt_tokens), SUM(completion_tokens), SUM(latency_ms) "+
+			"FROM ai_usage WHERE usage_day >= ? GROUP BY usage_day, provider ORDER BY usage_day, provider",
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []AIUsageSummary
+	for rows.Next() {
+		var s AIUsageSummary
+		err := rows.Scan(&s.Day, &s.Provider, &s.Requests, &s.Failures, &s.PromptTokens, &s.CompletionTokens, &s.TotalLatencyMs)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, s)
+	}
+
+	return summaries, rows.Err()
+}
+
+// GetAIPersonas возвращает персоны AI сервера, упорядоченные по имени
+func (p *MariaDBProvider) GetAIPersonas(guildID string) ([]AIPersona, error) {
+	rows, err := p.db.Query(
+		"SELECT guild_id, name, system_prompt, provider, temperature, allowed_channels, is_default FROM ai_personas WHERE guild_id = ? ORDER BY name",
+		guildID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var personas []AIPersona
+	for rows.Next() {
+		persona, err := scanAIPersona(rows)
+		if err != nil {
+			return nil, err
+		}
+		personas = append(personas, persona)
+	}
+
+	return personas, rows.Err()
+}
+
+// GetAIPersona возвращает персону AI сервера по имени или nil, если она не найдена
+func (p *MariaDBProvider) GetAIPersona(guildID, name string) (*AIPersona, error) {
 	row := p.db.QueryRow(
-		"SELECT id, user_id, reason, admin_id, timestamp, expires_at FROM bans WHERE user_id = ? AND (expires_at IS NULL OR expires_at > ?)",
-		userID, time.Now(),
+		"SELECT guild_id, name, system_prompt, provider, temperature, allowed_channels, is_default FROM ai_personas WHERE guild_id = ? AND name = ?",
+		guildID, name,
+	)
+
+	persona, err := scanAIPersona(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &persona, nil
+}
+
+// SaveAIPersona создает или обновляет персону AI сервера
+func (p *MariaDBProvider) SaveAIPersona(persona AIPersona) error {
+	// Персона по умолчанию на сервере может быть только одна
+	if persona.IsDefault {
+		_, err := p.db.Exec("UPDATE ai_personas SET is_default = 0 WHERE guild_id = ?", persona.GuildID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := p.db.Exec("DELETE FROM ai_personas WHERE guild_id = ? AND name = ?", persona.GuildID, persona.Name)
+	if err != nil {
+		return err
+	}
+
+	isDefault := 0
+	if persona.IsDefault {
+		isDefault = 1
+	}
+
+	_, err = p.db.Exec(
+		"INSERT INTO ai_personas (guild_id, name, system_prompt, provider, temperature, allowed_channels, is_default) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		persona.GuildID, persona.Name, persona.SystemPrompt, persona.Provider,
+		persona.Temperature, joinChannels(persona.AllowedChannels), isDefault,
 	)
+	return err
+}
 
-	var ban Ban
-	var expiresAt sql.NullTime
+// DeleteAIPersona удаляет персону AI сервера
+func (p *MariaDBProvider) DeleteAIPersona(guildID, name string) error {
+	_, err := p.db.Exec("DELETE FROM ai_personas WHERE guild_id = ? AND name = ?", guildID, name)
+	return err
+}
 
-	err := row.Scan(&ban.ID, &ban.UserID, &ban.Reason, &ban.AdminID, &ban.Timestamp, &expiresAt)
+// SaveReportMessage сохраняет сообщение репорта, отправленное в канал модерации
+func (p *MariaDBProvider) SaveReportMessage(msg ReportMessage) error {
+	status := msg.Status
+	if status == "" {
+		status = ReportStatusPending
+	}
+
+	_, err := p.db.Exec(
+		"INSERT INTO report_messages (message_id, report_id, guild_id, channel_id, reported_user_id, reporter_id, reason, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		msg.MessageID, msg.ReportID, msg.GuildID, msg.ChannelID,
+		msg.ReportedUserID, msg.ReporterID, msg.Reason, string(status), time.Now(),
+	)
+	return err
+}
+
+// GetReportMessage возвращает сообщение репорта по ID сообщения Discord или nil, если оно не найдено
+func (p *MariaDBProvider) GetReportMessage(messageID string) (*ReportMessage, error) {
+	row := p.db.QueryRow(
+		"SELECT message_id, report_id, guild_id, channel_id, reported_user_id, reporter_id, reason, status, created_at FROM report_messages WHERE message_id = ?",
+		messageID,
+	)
+
+	msg, err := scanReportMessage(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -200,11 +703,104 @@ func (p *MariaDBProvider) GetActiveBan(userID string) (*Ban, error) {
 		return nil, err
 	}
 
-	if expiresAt.Valid {
-		ban.ExpiresAt = &expiresAt.Time
+	return &msg, nil
+}
+
+// SetReportMessageStatus меняет статус сообщения репорта
+func (p *MariaDBProvider) SetReportMessageStatus(messageID string, status ReportStatus) error {
+	_, err := p.db.Exec("UPDATE report_messages SET status = ? WHERE message_id = ?", string(status), messageID)
+	return err
+}
+
+// GetPendingReportMessages возвращает нерассмотренные репорты сервера (пусто - всех серверов)
+func (p *MariaDBProvider) GetPendingReportMessages(guildID string) ([]ReportMessage, error) {
+	query := "SELECT message_id, report_id, guild_id, channel_id, reported_user_id, reporter_id, reason, status, created_at FROM report_messages WHERE status = ?"
+	args := []interface{}{string(ReportStatusPending)}
+	if guildID != "" {
+		query += " AND guild_id = ?"
+		args = append(args, guildID)
 	}
 
-	return &ban, nil
+	rows, err := p.db.Query(query+" ORDER BY report_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []ReportMessage
+	for rows.Next() {
+		msg, err := scanReportMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// AddCase сохраняет модерационный случай и возвращает его номер на сервере
+func (p *MariaDBProvider) AddCase(c ModerationCase) (int64, error) {
+	number, err := p.nextCaseNumber(c.GuildID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = p.db.Exec(
+		"INSERT INTO cases (guild_id, number, action_type, user_id, moderator_id, reason, duration_seconds, report_id, ban_id, evidence, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		c.GuildID, number, string(c.Action), c.UserID, c.ModeratorID, c.Reason,
+		caseDuration(c), nullableID(c.ReportID), nullableID(c.BanID), caseEvidence(c), time.Now(),
+	)
+	if isUniqueViolation(err) {
+		return 0, errCaseNumberTaken
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+// nextCaseNumber возвращает следующий номер модерационного случая на сервере
+func (p *MariaDBProvider) nextCaseNumber(guildID string) (int64, error) {
+	var number int64
+	err := p.db.QueryRow("SELECT COALESCE(MAX(number), 0) + 1 FROM cases WHERE guild_id = ?", guildID).Scan(&number)
+	return number, err
+}
+
+// GetCase возвращает модерационный случай по номеру на сервере или nil, если он не найден
+func (p *MariaDBProvider) GetCase(guildID string, number int64) (*ModerationCase, error) {
+	row := p.db.QueryRow("SELECT "+caseColumns+" FROM cases WHERE guild_id = ? AND number = ?", guildID, number)
+
+	c, err := scanCase(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// UpdateCaseReason меняет причину модерационного случая и запоминает, кто и когда ее изменил
+func (p *MariaDBProvider) UpdateCaseReason(guildID string, number int64, reason, moderatorID string) error {
+	_, err := p.db.Exec(
+		"UPDATE cases SET reason = ?, updated_by = ?, updated_at = ? WHERE guild_id = ? AND number = ?",
+		reason, moderatorID, time.Now(), guildID, number,
+	)
+	return err
+}
+
+// CountCases возвращает количество случаев с действием action у пользователя на сервере
+func (p *MariaDBProvider) CountCases(guildID, userID string, action CaseAction) (int, error) {
+	var count int
+	err := p.db.QueryRow(
+		"SELECT COUNT(*) FROM cases WHERE guild_id = ? AND user_id = ? AND action_type = ?",
+		guildID, userID, string(action),
+	).Scan(&count)
+
+	return count, err
 }
 
 // GetType возвращает тип базы данных
@@ -13,12 +13,20 @@ import (
 
 // MongoDBProvider представляет провайдер для работы с MongoDB
 type MongoDBProvider struct {
-	client     *mongo.Client
-	db         *mongo.Database
-	reports    *mongo.Collection
-	bans       *mongo.Collection
-	ctx        context.Context
-	cancelFunc context.CancelFunc
+	client        *mongo.Client
+	db            *mongo.Database
+	reports       *mongo.Collection
+	bans          *mongo.Collection
+	conversations *mongo.Collection
+	replies       *mongo.Collection
+	quotaUsage    *mongo.Collection
+	quotaLimits   *mongo.Collection
+	usage         *mongo.Collection
+	personas      *mongo.Collection
+	reportMsgs    *mongo.Collection
+	cases         *mongo.Collection
+	ctx           context.Context
+	cancelFunc    context.CancelFunc
 }
 
 // Initialize инициализирует соединение с базой данных MongoDB
@@ -57,6 +65,23 @@ func (p *MongoDBProvider) Initialize(config DatabaseConfig) error {
 	// Инициализируем коллекции
 	p.reports = p.db.Collection("reports")
 	p.bans = p.db.Collection("bans")
+	p.conversations = p.db.Collection("ai_conversations")
+	p.replies = p.db.Collection("ai_conversation_replies")
+	p.quotaUsage = p.db.Collection("ai_quota_usage")
+	p.quotaLimits = p.db.Collection("ai_quota_limits")
+	p.usage = p.db.Collection("ai_usage")
+	p.personas = p.db.Collection("ai_personas")
+	p.reportMsgs = p.db.Collection("report_messages")
+	p.cases = p.db.Collection("cases")
+
+	// Уникальный номер случая на сервере защищает от одинаковых номеров при записи из нескольких процессов
+	_, err = p.cases.Indexes().CreateOne(p.ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "guild_id", Value: 1}, {Key: "number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка создания индекса модерационных случаев: %w", err)
+	}
 
 	return nil
 }
@@ -74,17 +99,19 @@ func (p *MongoDBProvider) Close() error {
 }
 
 // AddReport добавляет новый репорт в базу данных
-func (p *MongoDBProvider) AddReport(reportedUserID, reporterID, reason string) (int64, error) {
-	report := bson.M{
-		"reported_user_id": reportedUserID,
-		"reporter_id":      reporterID,
-		"reason":           reason,
+func (p *MongoDBProvider) AddReport(report Report) (int64, error) {
+	doc := bson.M{
+		"reported_user_id": report.ReportedUserID,
+		"reporter_id":      report.ReporterID,
+		"reason":           report.Reason,
 		"timestamp":        time.Now(),
 		"confirmed":        false,
 		"confirmed_by":     "",
+		"status":           string(ReportStatusPending),
+		"guild_id":         report.GuildID,
 	}
 
-	result, err := p.reports.InsertOne(p.ctx, report)
+	result, err := p.reports.InsertOne(p.ctx, doc)
 	if err != nil {
 		return 0, err
 	}
@@ -97,25 +124,22 @@ func (p *MongoDBProvider) AddReport(reportedUserID, reporterID, reason string) (
 
 // ConfirmReport подтверждает репорт администратором
 func (p *MongoDBProvider) ConfirmReport(reportID int64, adminID string) error {
-	// В MongoDB мы используем ObjectID, но для совместимости с интерфейсом
-	// мы принимаем int64. Здесь мы ищем по временной метке.
-	filter := bson.M{"timestamp": time.Unix(reportID, 0)}
-	update := bson.M{
-		"$set": bson.M{
-			"confirmed":    true,
-			"confirmed_by": adminID,
-		},
-	}
+	return p.SetReportStatus(reportID, ReportStatusConfirmed, adminID)
+}
 
-	_, err := p.reports.UpdateOne(p.ctx, filter, update)
-	return err
+// mongoReportFilter возвращает фильтр репорта по ID. В MongoDB используется ObjectID,
+// а для совместимости с интерфейсом ID репорта - это время его создания в секундах
+func mongoReportFilter(reportID int64) bson.M {
+	created := time.Unix(reportID, 0)
+	return bson.M{"timestamp": bson.M{"$gte": created, "$lt": created.Add(time.Second)}}
 }
 
-// GetReportsByUser получает все репорты на указанного пользователя
-func (p *MongoDBProvider) GetReportsByUser(userID string) ([]Report, error) {
-	filter := bson.M{"reported_user_id": userID}
+// GetReportsByUser получает все репорты на указанного пользователя на сервере, начиная с последнего
+func (p *MongoDBProvider) GetReportsByUser(guildID, userID string) ([]Report, error) {
+	filter := bson.M{"guild_id": guildID, "reported_user_id": userID}
+	opts := options.Find().SetSort(bson.M{"timestamp": -1})
 
-	cursor, err := p.reports.Find(p.ctx, filter)
+	cursor, err := p.reports.Find(p.ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -127,30 +151,115 @@ func (p *MongoDBProvider) GetReportsByUser(userID string) ([]Report, error) {
 		if err := cursor.Decode(&doc); err != nil {
 			return nil, err
 		}
+		reports = append(reports, decodeMongoReport(doc))
+	}
 
-		var report Report
-		report.ReportedUserID = doc["reported_user_id"].(string)
-		report.ReporterID = doc["reporter_id"].(string)
-		report.Reason = doc["reason"].(string)
-		report.Timestamp = doc["timestamp"].(primitive.DateTime).Time()
-		report.Confirmed = doc["confirmed"].(bool)
+	return reports, cursor.Err()
+}
 
-		if confirmedBy, ok := doc["confirmed_by"].(string); ok && confirmedBy != "" {
-			report.ConfirmedBy = confirmedBy
-		}
+// GetReport возвращает репорт по ID или nil, если он не найден
+func (p *MongoDBProvider) GetReport(reportID int64) (*Report, error) {
+	var doc bson.M
+	err := p.reports.FindOne(p.ctx, mongoReportFilter(reportID)).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		// Используем временную метку как ID для совместимости
-		report.ID = report.Timestamp.Unix()
+	report := decodeMongoReport(doc)
+	return &report, nil
+}
+
+// SetReportStatus сохраняет решение модератора по репорту
+func (p *MongoDBProvider) SetReportStatus(reportID int64, status ReportStatus, moderatorID string) error {
+	confirmedBy := ""
+	if status.Confirmed() {
+		confirmedBy = moderatorID
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":       string(status),
+			"confirmed":    status.Confirmed(),
+			"confirmed_by": confirmedBy,
+			"decided_by":   moderatorID,
+			"decided_at":   time.Now(),
+		},
+	}
+
+	// Документы, созданные до появления статуса, не содержат поля status и считаются нерассмотренными
+	filter := mongoReportFilter(reportID)
+	filter["status"] = bson.M{"$in": bson.A{string(ReportStatusPending), "", nil}}
+
+	result, err := p.reports.UpdateOne(p.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrReportAlreadyReviewed
+	}
+	return nil
+}
+
+// ReopenReport возвращает эскалированный репорт к рассмотрению
+func (p *MongoDBProvider) ReopenReport(reportID int64) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":       string(ReportStatusPending),
+			"confirmed":    false,
+			"confirmed_by": "",
+			"decided_by":   "",
+		},
+		"$unset": bson.M{"decided_at": ""},
+	}
+
+	_, err := p.reports.UpdateOne(p.ctx, mongoReportFilter(reportID), update)
+	return err
+}
+
+// SetReportNote сохраняет заметку модератора к репорту
+func (p *MongoDBProvider) SetReportNote(reportID int64, note string) error {
+	_, err := p.reports.UpdateOne(p.ctx, mongoReportFilter(reportID), bson.M{"$set": bson.M{"note": note}})
+	return err
+}
 
-		reports = append(reports, report)
+// decodeMongoReport преобразует документ репорта MongoDB в Report
+func decodeMongoReport(doc bson.M) Report {
+	var report Report
+	report.ReportedUserID, _ = doc["reported_user_id"].(string)
+	report.ReporterID, _ = doc["reporter_id"].(string)
+	report.Reason, _ = doc["reason"].(string)
+	report.GuildID, _ = doc["guild_id"].(string)
+	if timestamp, ok := doc["timestamp"].(primitive.DateTime); ok {
+		report.Timestamp = timestamp.Time()
+	}
+	report.Confirmed, _ = doc["confirmed"].(bool)
+	report.ConfirmedBy, _ = doc["confirmed_by"].(string)
+
+	report.Status = ReportStatusPending
+	if status, ok := doc["status"].(string); ok && status != "" {
+		report.Status = ReportStatus(status)
+	} else if report.Confirmed {
+		report.Status = ReportStatusConfirmed
+	}
+	report.Note, _ = doc["note"].(string)
+	report.DecidedBy, _ = doc["decided_by"].(string)
+	if decidedAt, ok := doc["decided_at"].(primitive.DateTime); ok {
+		t := decidedAt.Time()
+		report.DecidedAt = &t
 	}
 
-	return reports, nil
+	// Используем временную метку как ID для совместимости
+	report.ID = report.Timestamp.Unix()
+	return report
 }
 
-// GetReportCount получает количество подтвержденных репортов на пользователя
-func (p *MongoDBProvider) GetReportCount(userID string) (int, error) {
+// GetReportCount получает количество подтвержденных репортов на пользователя на сервере
+func (p *MongoDBProvider) GetReportCount(guildID, userID string) (int, error) {
 	filter := bson.M{
+		"guild_id":         guildID,
 		"reported_user_id": userID,
 		"confirmed":        true,
 	}
@@ -164,37 +273,45 @@ func (p *MongoDBProvider) GetReportCount(userID string) (int, error) {
 }
 
 // AddBan добавляет новый бан в базу данных
-func (p *MongoDBProvider) AddBan(userID, reason, adminID string, duration *time.Duration) error {
-	var expiresAt *time.Time
-	if duration != nil {
-		expires := time.Now().Add(*duration)
-		expiresAt = &expires
+func (p *MongoDBProvider) AddBan(ban Ban) (int64, error) {
+	timestamp := time.Now()
+	doc := bson.M{
+		"user_id":     ban.UserID,
+		"reason":      ban.Reason,
+		"admin_id":    ban.AdminID,
+		"timestamp":   timestamp,
+		"expires_at":  ban.ExpiresAt,
+		"guild_id":    ban.GuildID,
+		"enforcement": banEnforcement(ban),
 	}
 
-	ban := bson.M{
-		"user_id":    userID,
-		"reason":     reason,
-		"admin_id":   adminID,
-		"timestamp":  time.Now(),
-		"expires_at": expiresAt,
+	if _, err := p.bans.InsertOne(p.ctx, doc); err != nil {
+		return 0, err
 	}
 
-	_, err := p.bans.InsertOne(p.ctx, ban)
-	return err
+	// Используем временную метку как ID для совместимости
+	return timestamp.Unix(), nil
 }
 
-// GetActiveBan проверяет, есть ли активный бан у пользователя
-func (p *MongoDBProvider) GetActiveBan(userID string) (*Ban, error) {
-	filter := bson.M{
-		"user_id": userID,
+// mongoActiveBanFilter возвращает фильтр действующих банов
+func mongoActiveBanFilter() bson.M {
+	return bson.M{
+		"lifted_at": nil,
 		"$or": []bson.M{
 			{"expires_at": nil},
 			{"expires_at": bson.M{"$gt": time.Now()}},
 		},
 	}
+}
+
+// GetActiveBan проверяет, есть ли активный бан у пользователя
+func (p *MongoDBProvider) GetActiveBan(userID string) (*Ban, error) {
+	filter := mongoActiveBanFilter()
+	filter["user_id"] = userID
 
 	var doc bson.M
-	err := p.bans.FindOne(p.ctx, filter).Decode(&doc)
+	opts := options.FindOne().SetSort(bson.M{"timestamp": -1})
+	err := p.bans.FindOne(p.ctx, filter, opts).Decode(&doc)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -202,11 +319,68 @@ func (p *MongoDBProvider) GetActiveBan(userID string) (*Ban, error) {
 		return nil, err
 	}
 
+	ban := decodeMongoBan(doc)
+	return &ban, nil
+}
+
+// GetActiveBans возвращает действующие баны, начиная с последнего
+func (p *MongoDBProvider) GetActiveBans() ([]Ban, error) {
+	return p.findBans(mongoActiveBanFilter(), options.Find().SetSort(bson.M{"timestamp": -1}))
+}
+
+// GetBansByUser возвращает все баны пользователя, начиная с последнего
+func (p *MongoDBProvider) GetBansByUser(userID string) ([]Ban, error) {
+	return p.findBans(bson.M{"user_id": userID}, options.Find().SetSort(bson.M{"timestamp": -1}))
+}
+
+// GetExpiredBans возвращает истекшие баны, которые еще не сняты
+func (p *MongoDBProvider) GetExpiredBans() ([]Ban, error) {
+	filter := bson.M{
+		"lifted_at":  nil,
+		"expires_at": bson.M{"$ne": nil, "$lte": time.Now()},
+	}
+	return p.findBans(filter, options.Find().SetSort(bson.M{"expires_at": 1}))
+}
+
+// LiftBan отмечает бан снятым. liftedBy - модератор, снявший бан (пусто - бан истек)
+func (p *MongoDBProvider) LiftBan(banID int64, liftedBy string) error {
+	// ID бана, как и ID репорта, - время его создания в секундах
+	filter := mongoReportFilter(banID)
+	filter["lifted_at"] = nil
+
+	_, err := p.bans.UpdateMany(p.ctx, filter, bson.M{"$set": bson.M{"lifted_at": time.Now(), "lifted_by": liftedBy}})
+	return err
+}
+
+// findBans возвращает баны, подходящие под фильтр
+func (p *MongoDBProvider) findBans(filter bson.M, opts *options.FindOptions) ([]Ban, error) {
+	cursor, err := p.bans.Find(p.ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(p.ctx)
+
+	var bans []Ban
+	for cursor.Next(p.ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		bans = append(bans, decodeMongoBan(doc))
+	}
+
+	return bans, cursor.Err()
+}
+
+// decodeMongoBan преобразует документ бана в Ban
+func decodeMongoBan(doc bson.M) Ban {
 	var ban Ban
-	ban.UserID = doc["user_id"].(string)
-	ban.Reason = doc["reason"].(string)
-	ban.AdminID = doc["admin_id"].(string)
-	ban.Timestamp = doc["timestamp"].(primitive.DateTime).Time()
+	ban.UserID, _ = doc["user_id"].(string)
+	ban.Reason, _ = doc["reason"].(string)
+	ban.AdminID, _ = doc["admin_id"].(string)
+	if timestamp, ok := doc["timestamp"].(primitive.DateTime); ok {
+		ban.Timestamp = timestamp.Time()
+	}
 
 	// Используем временную метку как ID для совместимости
 	ban.ID = ban.Timestamp.Unix()
@@ -215,8 +389,489 @@ func (p *MongoDBProvider) GetActiveBan(userID string) (*Ban, error) {
 		expTime := expiresAt.Time()
 		ban.ExpiresAt = &expTime
 	}
+	if liftedAt, ok := doc["lifted_at"].(primitive.DateTime); ok {
+		liftTime := liftedAt.Time()
+		ban.LiftedAt = &liftTime
+	}
+	ban.LiftedBy, _ = doc["lifted_by"].(string)
+	ban.GuildID, _ = doc["guild_id"].(string)
+	enforcement, _ := doc["enforcement"].(string)
+	ban.Enforcement = BanEnforcement(enforcement)
+	if ban.Enforcement == "" {
+		ban.Enforcement = BanEnforcementMessages
+	}
+	return ban
+}
 
-	return &ban, nil
+// AddConversationMessage сохраняет сообщение диалога с AI
+func (p *MongoDBProvider) AddConversationMessage(msg ConversationMessage) error {
+	doc := bson.M{
+		"session_id": msg.SessionID,
+		"role":       msg.Role,
+		"content":    msg.Content,
+		"message_id": msg.MessageID,
+		"timestamp":  time.Now(),
+	}
+
+	_, err := p.conversations.InsertOne(p.ctx, doc)
+	return err
+}
+
+// AddConversationReplies связывает сообщения Discord с ответом модели с диалогом
+func (p *MongoDBProvider) AddConversationReplies(sessionID string, messageIDs []string) error {
+	for _, messageID := range messageIDs {
+		if _, err := p.replies.InsertOne(p.ctx, bson.M{"message_id": messageID, "session_id": sessionID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetConversationMessages возвращает последние limit сообщений диалога в хронологическом порядке
+func (p *MongoDBProvider) GetConversationMessages(sessionID string, limit int) ([]ConversationMessage, error) {
+	filter := bson.M{"session_id": sessionID}
+	opts := options.Find().SetSort(bson.M{"_id": -1}).SetLimit(int64(limit))
+
+	cursor, err := p.conversations.Find(p.ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(p.ctx)
+
+	var messages []ConversationMessage
+	for cursor.Next(p.ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		var m ConversationMessage
+		m.SessionID = doc["session_id"].(string)
+		m.Role = doc["role"].(string)
+		m.Content = doc["content"].(string)
+		m.MessageID, _ = doc["message_id"].(string)
+		m.Timestamp = doc["timestamp"].(primitive.DateTime).Time()
+
+		// Используем временную метку как ID для совместимости
+		m.ID = m.Timestamp.Unix()
+
+		messages = append(messages, m)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	// Запрос возвращает новые сообщения первыми
+	reverseConversation(messages)
+	return messages, nil
+}
+
+// GetConversationSession возвращает диалог, к которому относится сообщение бота, или пустую строку
+func (p *MongoDBProvider) GetConversationSession(messageID string) (string, error) {
+	// Ответы, сохраненные до появления ai_conversation_replies, связаны с диалогом только в ai_conversations
+	for _, collection := range []*mongo.Collection{p.replies, p.conversations} {
+		var doc bson.M
+		err := collection.FindOne(p.ctx, bson.M{"message_id": messageID}).Decode(&doc)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		sessionID, _ := doc["session_id"].(string)
+		return sessionID, nil
+	}
+	return "", nil
+}
+
+// ClearConversation удаляет историю диалога
+func (p *MongoDBProvider) ClearConversation(sessionID string) error {
+	if _, err := p.replies.DeleteMany(p.ctx, bson.M{"session_id": sessionID}); err != nil {
+		return err
+	}
+	_, err := p.conversations.DeleteMany(p.ctx, bson.M{"session_id": sessionID})
+	return err
+}
+
+// GetAIQuota возвращает использование AI пользователем за день и его индивидуальный лимит
+func (p *MongoDBProvider) GetAIQuota(userID, day string) (*AIQuota, error) {
+	quota := &AIQuota{UserID: userID, Day: day}
+
+	var usage bson.M
+	err := p.quotaUsage.FindOne(p.ctx, bson.M{"user_id": userID, "usage_day": day}).Decode(&usage)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if used, ok := usage["used"].(int32); ok {
+		quota.Used = int(used)
+	}
+
+	var limit bson.M
+	err = p.quotaLimits.FindOne(p.ctx, bson.M{"user_id": userID}).Decode(&limit)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if dailyLimit, ok := limit["daily_limit"].(int32); ok {
+		value := int(dailyLimit)
+		quota.DailyLimit = &value
+	}
+
+	return quota, nil
+}
+
+// IncrementAIQuota увеличивает счетчик запросов пользователя к AI за день
+func (p *MongoDBProvider) IncrementAIQuota(userID, day string) error {
+	filter := bson.M{"user_id": userID, "usage_day": day}
+	update := bson.M{"$inc": bson.M{"used": int32(1)}}
+
+	_, err := p.quotaUsage.UpdateOne(p.ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// ResetAIQuota обнуляет счетчик запросов пользователя к AI за день
+func (p *MongoDBProvider) ResetAIQuota(userID, day string) error {
+	_, err := p.quotaUsage.DeleteOne(p.ctx, bson.M{"user_id": userID, "usage_day": day})
+	return err
+}
+
+// SetAIQuotaLimit устанавливает индивидуальный дневной лимит пользователя (nil - лимит из конфигурации)
+func (p *MongoDBProvider) SetAIQuotaLimit(userID string, limit *int) error {
+	filter := bson.M{"user_id": userID}
+	if limit == nil {
+		_, err := p.quotaLimits.DeleteOne(p.ctx, filter)
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"daily_limit": int32(*limit)}}
+	_, err := p.quotaLimits.UpdateOne(p.ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// RecordAIUsage сохраняет сведения об обращении к провайдеру AI
+func (p *MongoDBProvider) RecordAIUsage(record AIUsageRecord) error {
+	doc := bson.M{
+		"user_id":           record.UserID,
+		"guild_id":          record.GuildID,
+		"provider":          record.Provider,
+		"model":             record.Model,
+		"latency_ms":        record.LatencyMs,
+		"prompt_tokens":     int64(record.PromptTokens),
+		"completion_tokens": int64(record.CompletionTokens),
+		"success":           record.Success,
+		"error_message":     record.Error,
+		"usage_day":         record.Timestamp.UTC().Format("2006-01-02"),
+		"timestamp":         record.Timestamp,
+	}
+
+	_, err := p.usage.InsertOne(p.ctx, doc)
+	return err
+}
+
+// GetAIUsageSummary возвращает использование AI по дням и провайдерам начиная с указанной даты (2006-01-02)
+func (p *MongoDBProvider) GetAIUsageSummary(since string) ([]AIUsageSummary, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"usage_day": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":               bson.M{"day": "$usage_day", "provider": "$provider"},
+			"requests":          bson.M{"$sum": int64(1)},
+			"failures":          bson.M{"$sum": bson.M{"$cond": bson.A{"$success", int64(0), int64(1)}}},
+			"prompt_tokens":     bson.M{"$sum": "$prompt_tokens"},
+			"completion_tokens": bson.M{"$sum": "$completion_tokens"},
+			"latency_ms":        bson.M{"$sum": "$latency_ms"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.day", Value: 1}, {Key: "_id.provider", Value: 1}}}},
+	}
+
+	cursor, err := p.usage.Aggregate(p.ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(p.ctx)
+
+	var summaries []AIUsageSummary
+	for cursor.Next(p.ctx) {
+		var result struct {
+			ID struct {
+				Day      string `bson:"day"`
+				Provider string `bson:"provider"`
+			} `bson:"_id"`
+			Requests         int64 `bson:"requests"`
+			Failures         int64 `bson:"failures"`
+			PromptTokens     int64 `bson:"prompt_tokens"`
+			CompletionTokens int64 `bson:"completion_tokens"`
+			LatencyMs        int64 `bson:"latency_ms"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+
+		summaries = append(summaries, AIUsageSummary{
+			Day:              result.ID.Day,
+			Provider:         result.ID.Provider,
+			Requests:         result.Requests,
+			Failures:         result.Failures,
+			PromptTokens:     result.PromptTokens,
+			CompletionTokens: result.CompletionTokens,
+			TotalLatencyMs:   result.LatencyMs,
+		})
+	}
+
+	return summaries, cursor.Err()
+}
+
+// mongoAIPersona - документ персоны AI в MongoDB
+type mongoAIPersona struct {
+	GuildID         string   `bson:"guild_id"`
+	Name            string   `bson:"name"`
+	SystemPrompt    string   `bson:"system_prompt"`
+	Provider        string   `bson:"provider"`
+	Temperature     *float64 `bson:"temperature"`
+	AllowedChannels []string `bson:"allowed_channels"`
+	IsDefault       bool     `bson:"is_default"`
+}
+
+// GetAIPersonas возвращает персоны AI сервера, упорядоченные по имени
+func (p *MongoDBProvider) GetAIPersonas(guildID string) ([]AIPersona, error) {
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cursor, err := p.personas.Find(p.ctx, bson.M{"guild_id": guildID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(p.ctx)
+
+	var personas []AIPersona
+	for cursor.Next(p.ctx) {
+		var doc mongoAIPersona
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		personas = append(personas, AIPersona(doc))
+	}
+
+	return personas, cursor.Err()
+}
+
+// GetAIPersona возвращает персону AI сервера по имени или nil, если она не найдена
+func (p *MongoDBProvider) GetAIPersona(guildID, name string) (*AIPersona, error) {
+	var doc mongoAIPersona
+	err := p.personas.FindOne(p.ctx, bson.M{"guild_id": guildID, "name": name}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	persona := AIPersona(doc)
+	return &persona, nil
+}
+
+// SaveAIPersona создает или обновляет персону AI сервера
+func (p *MongoDBProvider) SaveAIPersona(persona AIPersona) error {
+	// Персона по умолчанию на сервере может быть только одна
+	if persona.IsDefault {
+		_, err := p.personas.UpdateMany(p.ctx, bson.M{"guild_id": persona.GuildID}, bson.M{"$set": bson.M{"is_default": false}})
+		if err != nil {
+			return err
+		}
+	}
+
+	filter := bson.M{"guild_id": persona.GuildID, "name": persona.Name}
+	_, err := p.personas.ReplaceOne(p.ctx, filter, mongoAIPersona(persona), options.Replace().SetUpsert(true))
+	return err
+}
+
+// DeleteAIPersona удаляет персону AI сервера
+func (p *MongoDBProvider) DeleteAIPersona(guildID, name string) error {
+	_, err := p.personas.DeleteOne(p.ctx, bson.M{"guild_id": guildID, "name": name})
+	return err
+}
+
+// mongoReportMessage - документ сообщения репорта в MongoDB
+type mongoReportMessage struct {
+	MessageID      string       `bson:"message_id"`
+	ReportID       int64        `bson:"report_id"`
+	GuildID        string       `bson:"guild_id"`
+	ChannelID      string       `bson:"channel_id"`
+	ReportedUserID string       `bson:"reported_user_id"`
+	ReporterID     string       `bson:"reporter_id"`
+	Reason         string       `bson:"reason"`
+	Status         ReportStatus `bson:"status"`
+	Timestamp      time.Time    `bson:"created_at"`
+}
+
+// SaveReportMessage сохраняет сообщение репорта, отправленное в канал модерации
+func (p *MongoDBProvider) SaveReportMessage(msg ReportMessage) error {
+	if msg.Status == "" {
+		msg.Status = ReportStatusPending
+	}
+	msg.Timestamp = time.Now()
+
+	filter := bson.M{"message_id": msg.MessageID}
+	_, err := p.reportMsgs.ReplaceOne(p.ctx, filter, mongoReportMessage(msg), options.Replace().SetUpsert(true))
+	return err
+}
+
+// GetReportMessage возвращает сообщение репорта по ID сообщения Discord или nil, если оно не найдено
+func (p *MongoDBProvider) GetReportMessage(messageID string) (*ReportMessage, error) {
+	var doc mongoReportMessage
+	err := p.reportMsgs.FindOne(p.ctx, bson.M{"message_id": messageID}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	msg := ReportMessage(doc)
+	return &msg, nil
+}
+
+// SetReportMessageStatus меняет статус сообщения репорта
+func (p *MongoDBProvider) SetReportMessageStatus(messageID string, status ReportStatus) error {
+	_, err := p.reportMsgs.UpdateOne(p.ctx, bson.M{"message_id": messageID}, bson.M{"$set": bson.M{"status": status}})
+	return err
+}
+
+// GetPendingReportMessages возвращает нерассмотренные репорты сервера (пусто - всех серверов)
+func (p *MongoDBProvider) GetPendingReportMessages(guildID string) ([]ReportMessage, error) {
+	filter := bson.M{"status": ReportStatusPending}
+	if guildID != "" {
+		filter["guild_id"] = guildID
+	}
+
+	opts := options.Find().SetSort(bson.M{"report_id": 1})
+	cursor, err := p.reportMsgs.Find(p.ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(p.ctx)
+
+	var messages []ReportMessage
+	for cursor.Next(p.ctx) {
+		var doc mongoReportMessage
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		messages = append(messages, ReportMessage(doc))
+	}
+
+	return messages, cursor.Err()
+}
+
+// AddCase сохраняет модерационный случай и возвращает его номер на сервере
+func (p *MongoDBProvider) AddCase(c ModerationCase) (int64, error) {
+	// Следующий номер - после номера последнего случая сервера
+	number := int64(1)
+	var last bson.M
+	opts := options.FindOne().SetSort(bson.M{"number": -1})
+	err := p.cases.FindOne(p.ctx, bson.M{"guild_id": c.GuildID}, opts).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return 0, err
+	}
+	if err == nil {
+		number = mongoInt64(last["number"]) + 1
+	}
+
+	doc := bson.M{
+		"guild_id":         c.GuildID,
+		"number":           number,
+		"action_type":      string(c.Action),
+		"user_id":          c.UserID,
+		"moderator_id":     c.ModeratorID,
+		"reason":           c.Reason,
+		"duration_seconds": caseDuration(c),
+		"report_id":        nullableID(c.ReportID),
+		"ban_id":           nullableID(c.BanID),
+		"evidence":         c.Evidence,
+		"created_at":       time.Now(),
+	}
+
+	if _, err := p.cases.InsertOne(p.ctx, doc); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return 0, errCaseNumberTaken
+		}
+		return 0, err
+	}
+
+	return number, nil
+}
+
+// GetCase возвращает модерационный случай по номеру на сервере или nil, если он не найден
+func (p *MongoDBProvider) GetCase(guildID string, number int64) (*ModerationCase, error) {
+	var doc bson.M
+	err := p.cases.FindOne(p.ctx, bson.M{"guild_id": guildID, "number": number}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := ModerationCase{
+		GuildID:  guildID,
+		Number:   number,
+		ReportID: mongoInt64(doc["report_id"]),
+		BanID:    mongoInt64(doc["ban_id"]),
+	}
+	action, _ := doc["action_type"].(string)
+	c.Action = CaseAction(action)
+	c.UserID, _ = doc["user_id"].(string)
+	c.ModeratorID, _ = doc["moderator_id"].(string)
+	c.Reason, _ = doc["reason"].(string)
+	c.UpdatedBy, _ = doc["updated_by"].(string)
+	if doc["duration_seconds"] != nil {
+		d := time.Duration(mongoInt64(doc["duration_seconds"])) * time.Second
+		c.Duration = &d
+	}
+	if evidence, ok := doc["evidence"].(bson.A); ok {
+		for _, item := range evidence {
+			if value, ok := item.(string); ok {
+				c.Evidence = append(c.Evidence, value)
+			}
+		}
+	}
+	if createdAt, ok := doc["created_at"].(primitive.DateTime); ok {
+		c.Timestamp = createdAt.Time()
+	}
+	if updatedAt, ok := doc["updated_at"].(primitive.DateTime); ok {
+		t := updatedAt.Time()
+		c.UpdatedAt = &t
+	}
+
+	// Используем временную метку как ID для совместимости
+	c.ID = c.Timestamp.Unix()
+	return &c, nil
+}
+
+// UpdateCaseReason меняет причину модерационного случая и запоминает, кто и когда ее изменил
+func (p *MongoDBProvider) UpdateCaseReason(guildID string, number int64, reason, moderatorID string) error {
+	update := bson.M{"$set": bson.M{"reason": reason, "updated_by": moderatorID, "updated_at": time.Now()}}
+
+	_, err := p.cases.UpdateOne(p.ctx, bson.M{"guild_id": guildID, "number": number}, update)
+	return err
+}
+
+// mongoInt64 преобразует числовое значение документа в int64 (0 - значения нет)
+func mongoInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// CountCases возвращает количество случаев с действием action у пользователя на сервере
+func (p *MongoDBProvider) CountCases(guildID, userID string, action CaseAction) (int, error) {
+	filter := bson.M{"guild_id": guildID, "user_id": userID, "action_type": string(action)}
+
+	count, err := p.cases.CountDocuments(p.ctx, filter)
+	return int(count), err
 }
 
 // GetType возвращает тип базы данных
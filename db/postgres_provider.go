@@ -64,6 +64,18 @@ func (p *PostgreSQLProvider) createTables() error {
 		return err
 	}
 
+	// Столбцы рассмотрения репорта
+	err = migrateReportColumns(p.db, []string{
+		"ALTER TABLE reports ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending'",
+		"ALTER TABLE reports ADD COLUMN IF NOT EXISTS note TEXT",
+		"ALTER TABLE reports ADD COLUMN IF NOT EXISTS decided_by TEXT",
+		"ALTER TABLE reports ADD COLUMN IF NOT EXISTS decided_at TIMESTAMP",
+		"ALTER TABLE reports ADD COLUMN IF NOT EXISTS guild_id TEXT",
+	})
+	if err != nil {
+		return err
+	}
+
 	// Таблица для хранения банов
 	_, err = p.db.Exec(`
 		CREATE TABLE IF NOT EXISTS bans (
@@ -79,6 +91,157 @@ func (p *PostgreSQLProvider) createTables() error {
 		return err
 	}
 
+	// Столбцы сервера, способа применения и снятия бана
+	err = addMissingColumns(p.db, []string{
+		"ALTER TABLE bans ADD COLUMN IF NOT EXISTS guild_id TEXT",
+		"ALTER TABLE bans ADD COLUMN IF NOT EXISTS enforcement TEXT NOT NULL DEFAULT 'messages'",
+		"ALTER TABLE bans ADD COLUMN IF NOT EXISTS lifted_at TIMESTAMP",
+		"ALTER TABLE bans ADD COLUMN IF NOT EXISTS lifted_by TEXT",
+	})
+	if err != nil {
+		return err
+	}
+
+	// Таблица для хранения истории диалогов с AI
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_conversations (
+			id SERIAL PRIMARY KEY,
+			session_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			content TEXT NOT NULL,
+			message_id TEXT,
+			timestamp TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.Exec("CREATE INDEX IF NOT EXISTS idx_ai_conversations_session ON ai_conversations (session_id)")
+	if err != nil {
+		return err
+	}
+
+	// Таблица сообщений Discord с ответами AI: длинный ответ занимает несколько сообщений,
+	// и ответ на любое из них продолжает диалог
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_conversation_replies (
+			message_id TEXT PRIMARY KEY,
+			session_id TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Таблицы для учета дневных квот AI
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_quota_usage (
+			user_id TEXT NOT NULL,
+			usage_day TEXT NOT NULL,
+			used INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (user_id, usage_day)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_quota_limits (
+			user_id TEXT PRIMARY KEY,
+			daily_limit INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Таблица для учета обращений к провайдерам AI
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_usage (
+			id SERIAL PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			guild_id TEXT,
+			provider TEXT NOT NULL,
+			model TEXT,
+			latency_ms INTEGER NOT NULL,
+			prompt_tokens INTEGER NOT NULL DEFAULT 0,
+			completion_tokens INTEGER NOT NULL DEFAULT 0,
+			success INTEGER NOT NULL,
+			error_message TEXT,
+			usage_day TEXT NOT NULL,
+			timestamp TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.Exec("CREATE INDEX IF NOT EXISTS idx_ai_usage_day ON ai_usage (usage_day)")
+	if err != nil {
+		return err
+	}
+
+	// Таблица для хранения персон AI серверов
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ai_personas (
+			guild_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			system_prompt TEXT NOT NULL,
+			provider TEXT,
+			temperature DOUBLE PRECISION,
+			allowed_channels TEXT,
+			is_default INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (guild_id, name)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Таблица для хранения сообщений репортов в каналах модерации
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS report_messages (
+			message_id TEXT PRIMARY KEY,
+			report_id BIGINT NOT NULL,
+			guild_id TEXT,
+			channel_id TEXT NOT NULL,
+			reported_user_id TEXT NOT NULL,
+			reporter_id TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			created_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Таблица модерационных случаев с номерами, уникальными и последовательными в пределах сервера
+	_, err = p.db.Exec(`
+		CREATE TABLE IF NOT EXISTS cases (
+			id SERIAL PRIMARY KEY,
+			guild_id TEXT NOT NULL,
+			number BIGINT NOT NULL,
+			action_type TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			moderator_id TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			duration_seconds BIGINT,
+			report_id BIGINT,
+			ban_id BIGINT,
+			evidence TEXT,
+			created_at TIMESTAMP NOT NULL,
+			updated_by TEXT,
+			updated_at TIMESTAMP,
+			UNIQUE (guild_id, number)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -91,11 +254,11 @@ func (p *PostgreSQLProvider) Close() error {
 }
 
 // AddReport добавляет новый репорт в базу данных
-func (p *PostgreSQLProvider) AddReport(reportedUserID, reporterID, reason string) (int64, error) {
+func (p *PostgreSQLProvider) AddReport(report Report) (int64, error) {
 	var id int64
 	err := p.db.QueryRow(
-		"INSERT INTO reports (reported_user_id, reporter_id, reason, timestamp) VALUES ($1, $2, $3, $4) RETURNING id",
-		reportedUserID, reporterID, reason, time.Now(),
+		"INSERT INTO reports (reported_user_id, reporter_id, reason, timestamp, guild_id) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		report.ReportedUserID, report.ReporterID, report.Reason, time.Now(), report.GuildID,
 	).Scan(&id)
 	if err != nil {
 		return 0, err
@@ -106,84 +269,430 @@ func (p *PostgreSQLProvider) AddReport(reportedUserID, reporterID, reason string
 
 // ConfirmReport подтверждает репорт администратором
 func (p *PostgreSQLProvider) ConfirmReport(reportID int64, adminID string) error {
+	return p.SetReportStatus(reportID, ReportStatusConfirmed, adminID)
+}
+
+// GetReportsByUser получает все репорты на указанного пользователя на сервере
+func (p *PostgreSQLProvider) GetReportsByUser(guildID, userID string) ([]Report, error) {
+	rows, err := p.db.Query(
+		"SELECT "+reportColumns+" FROM reports WHERE guild_id = $1 AND reported_user_id = $2 ORDER BY id DESC",
+		guildID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanReports(rows)
+}
+
+// GetReport возвращает репорт по ID или nil, если он не найден
+func (p *PostgreSQLProvider) GetReport(reportID int64) (*Report, error) {
+	row := p.db.QueryRow("SELECT "+reportColumns+" FROM reports WHERE id = $1", reportID)
+
+	report, err := scanReport(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// SetReportStatus сохраняет решение модератора по репорту
+func (p *PostgreSQLProvider) SetReportStatus(reportID int64, status ReportStatus, moderatorID string) error {
+	confirmedBy := sql.NullString{String: moderatorID, Valid: status.Confirmed()}
+	result, err := p.db.Exec(
+		"UPDATE reports SET status = $1, confirmed = $2, confirmed_by = $3, decided_by = $4, decided_at = $5 WHERE id = $6 AND status = 'pending'",
+		string(status), status.Confirmed(), confirmedBy, moderatorID, time.Now(), reportID,
+	)
+	if err != nil {
+		return err
+	}
+	return checkReportReviewed(result)
+}
+
+// ReopenReport возвращает эскалированный репорт к рассмотрению
+func (p *PostgreSQLProvider) ReopenReport(reportID int64) error {
 	_, err := p.db.Exec(
-		"UPDATE reports SET confirmed = TRUE, confirmed_by = $1 WHERE id = $2",
-		adminID, reportID,
+		"UPDATE reports SET status = $1, confirmed = FALSE, confirmed_by = NULL, decided_by = NULL, decided_at = NULL WHERE id = $2",
+		string(ReportStatusPending), reportID,
 	)
 	return err
 }
 
-// GetReportsByUser получает все репорты на указанного пользователя
-func (p *PostgreSQLProvider) GetReportsByUser(userID string) ([]Report, error) {
+// SetReportNote сохраняет заметку модератора к репорту
+func (p *PostgreSQLProvider) SetReportNote(reportID int64, note string) error {
+	_, err := p.db.Exec("UPDATE reports SET note = $1 WHERE id = $2", note, reportID)
+	return err
+}
+
+// GetReportCount получает количество подтвержденных репортов на пользователя на сервере
+func (p *PostgreSQLProvider) GetReportCount(guildID, userID string) (int, error) {
+	var count int
+	err := p.db.QueryRow(
+		"SELECT COUNT(*) FROM reports WHERE guild_id = $1 AND reported_user_id = $2 AND confirmed = TRUE",
+		guildID, userID,
+	).Scan(&count)
+
+	return count, err
+}
+
+// AddBan добавляет новый бан в базу данных
+func (p *PostgreSQLProvider) AddBan(ban Ban) (int64, error) {
+	var id int64
+	err := p.db.QueryRow(
+		"INSERT INTO bans (user_id, reason, admin_id, timestamp, expires_at, guild_id, enforcement) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		ban.UserID, ban.Reason, ban.AdminID, time.Now(), ban.ExpiresAt, ban.GuildID, banEnforcement(ban),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetActiveBan проверяет, есть ли активный бан у пользователя
+func (p *PostgreSQLProvider) GetActiveBan(userID string) (*Ban, error) {
+	row := p.db.QueryRow(
+		"SELECT "+banColumns+" FROM bans WHERE user_id = $1 AND "+activeBanCondition("$2")+" ORDER BY id DESC",
+		userID, time.Now(),
+	)
+
+	ban, err := scanBan(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ban, nil
+}
+
+// GetActiveBans возвращает действующие баны, начиная с последнего
+func (p *PostgreSQLProvider) GetActiveBans() ([]Ban, error) {
 	rows, err := p.db.Query(
-		"SELECT id, reported_user_id, reporter_id, reason, timestamp, confirmed, confirmed_by FROM reports WHERE reported_user_id = $1",
-		userID,
+		"SELECT "+banColumns+" FROM bans WHERE "+activeBanCondition("$1")+" ORDER BY id DESC",
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBans(rows)
+}
+
+// GetBansByUser возвращает все баны пользователя, начиная с последнего
+func (p *PostgreSQLProvider) GetBansByUser(userID string) ([]Ban, error) {
+	rows, err := p.db.Query("SELECT "+banColumns+" FROM bans WHERE user_id = $1 ORDER BY id DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBans(rows)
+}
+
+// GetExpiredBans возвращает истекшие баны, которые еще не сняты
+func (p *PostgreSQLProvider) GetExpiredBans() ([]Ban, error) {
+	rows, err := p.db.Query(
+		"SELECT "+banColumns+" FROM bans WHERE lifted_at IS NULL AND expires_at IS NOT NULL AND expires_at <= $1 ORDER BY expires_at",
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBans(rows)
+}
+
+// LiftBan отмечает бан снятым. liftedBy - модератор, снявший бан (пусто - бан истек)
+func (p *PostgreSQLProvider) LiftBan(banID int64, liftedBy string) error {
+	_, err := p.db.Exec("UPDATE bans SET lifted_at = $1, lifted_by = $2 WHERE id = $3 AND lifted_at IS NULL", time.Now(), liftedBy, banID)
+	return err
+}
+
+// AddConversationMessage сохраняет сообщение диалога с AI
+func (p *PostgreSQLProvider) AddConversationMessage(msg ConversationMessage) error {
+	_, err := p.db.Exec(
+		"INSERT INTO ai_conversations (session_id, role, content, message_id, timestamp) VALUES ($1, $2, $3, $4, $5)",
+		msg.SessionID, msg.Role, msg.Content, msg.MessageID, time.Now(),
+	)
+	return err
+}
+
+// AddConversationReplies связывает сообщения Discord с ответом модели с диалогом
+func (p *PostgreSQLProvider) AddConversationReplies(sessionID string, messageIDs []string) error {
+	for _, messageID := range messageIDs {
+		_, err := p.db.Exec("INSERT INTO ai_conversation_replies (message_id, session_id) VALUES ($1, $2)", messageID, sessionID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetConversationMessages возвращает последние limit сообщений диалога в хронологическом порядке
+func (p *PostgreSQLProvider) GetConversationMessages(sessionID string, limit int) ([]ConversationMessage, error) {
+	rows, err := p.db.Query(
+		"SELECT id, session_id, role, content, message_id, timestamp FROM ai_conversations WHERE session_id = $1 ORDER BY id DESC LIMIT $2",
+		sessionID, limit,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var reports []Report
+	var messages []ConversationMessage
 	for rows.Next() {
-		var r Report
-		var timestamp time.Time
-		var confirmedBy sql.NullString
-		err := rows.Scan(&r.ID, &r.ReportedUserID, &r.ReporterID, &r.Reason, &timestamp, &r.Confirmed, &confirmedBy)
+		var m ConversationMessage
+		var messageID sql.NullString
+		err := rows.Scan(&m.ID, &m.SessionID, &m.Role, &m.Content, &messageID, &m.Timestamp)
 		if err != nil {
 			return nil, err
 		}
+		m.MessageID = messageID.String
+
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-		r.Timestamp = timestamp
+	// Запрос возвращает новые сообщения первыми
+	reverseConversation(messages)
+	return messages, nil
+}
 
-		if confirmedBy.Valid {
-			r.ConfirmedBy = confirmedBy.String
-		}
+// GetConversationSession возвращает диалог, к которому относится сообщение бота, или пустую строку
+func (p *PostgreSQLProvider) GetConversationSession(messageID string) (string, error) {
+	// Ответы, сохраненные до появления ai_conversation_replies, связаны с диалогом только в ai_conversations
+	return querySession(p.db, messageID,
+		"SELECT session_id FROM ai_conversation_replies WHERE message_id = $1",
+		"SELECT session_id FROM ai_conversations WHERE message_id = $1",
+	)
+}
 
-		reports = append(reports, r)
+// ClearConversation удаляет историю диалога
+func (p *PostgreSQLProvider) ClearConversation(sessionID string) error {
+	if _, err := p.db.Exec("DELETE FROM ai_conversation_replies WHERE session_id = $1", sessionID); err != nil {
+		return err
 	}
-
-	return reports, nil
+	_, err := p.db.Exec("DELETE FROM ai_conversations WHERE session_id = $1", sessionID)
+	return err
 }
 
-// GetReportCount получает количество подтвержденных репортов на пользователя
-func (p *PostgreSQLProvider) GetReportCount(userID string) (int, error) {
-	var count int
+// GetAIQuota возвращает использование AI пользователем за день и его индивидуальный лимит
+func (p *PostgreSQLProvider) GetAIQuota(userID, day string) (*AIQuota, error) {
+	quota := &AIQuota{UserID: userID, Day: day}
+
 	err := p.db.QueryRow(
-		"SELECT COUNT(*) FROM reports WHERE reported_user_id = $1 AND confirmed = TRUE",
+		"SELECT used FROM ai_quota_usage WHERE user_id = $1 AND usage_day = $2",
+		userID, day,
+	).Scan(&quota.Used)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	var limit sql.NullInt64
+	err = p.db.QueryRow(
+		"SELECT daily_limit FROM ai_quota_limits WHERE user_id = $1",
 		userID,
-	).Scan(&count)
+	).Scan(&limit)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if limit.Valid {
+		dailyLimit := int(limit.Int64)
+		quota.DailyLimit = &dailyLimit
+	}
 
-	return count, err
+	return quota, nil
 }
 
-// AddBan добавляет новый бан в базу данных
-func (p *PostgreSQLProvider) AddBan(userID, reason, adminID string, duration *time.Duration) error {
-	var expiresAt *time.Time
-	if duration != nil {
-		expires := time.Now().Add(*duration)
-		expiresAt = &expires
+// IncrementAIQuota увеличивает счетчик запросов пользователя к AI за день
+func (p *PostgreSQLProvider) IncrementAIQuota(userID, day string) error {
+	result, err := p.db.Exec(
+		"UPDATE ai_quota_usage SET used = used + 1 WHERE user_id = $1 AND usage_day = $2",
+		userID, day,
+	)
+	if err != nil {
+		return err
 	}
 
+	// Первый запрос за день - создаем запись
+	if affected, err := result.RowsAffected(); err != nil || affected > 0 {
+		return err
+	}
+	_, err = p.db.Exec(
+		"INSERT INTO ai_quota_usage (user_id, usage_day, used) VALUES ($1, $2, 1)",
+		userID, day,
+	)
+	return err
+}
+
+// ResetAIQuota обнуляет счетчик запросов пользователя к AI за день
+func (p *PostgreSQLProvider) ResetAIQuota(userID, day string) error {
 	_, err := p.db.Exec(
-		"INSERT INTO bans (user_id, reason, admin_id, timestamp, expires_at) VALUES ($1, $2, $3, $4, $5)",
-		userID, reason, adminID, time.Now(), expiresAt,
+		"DELETE FROM ai_quota_usage WHERE user_id = $1 AND usage_day = $2",
+		userID, day,
 	)
+	return err
+}
+
+// SetAIQuotaLimit устанавливает индивидуальный дневной лимит пользователя (nil - лимит из конфигурации)
+func (p *PostgreSQLProvider) SetAIQuotaLimit(userID string, limit *int) error {
+	_, err := p.db.Exec("DELETE FROM ai_quota_limits WHERE user_id = $1", userID)
+	if err != nil || limit == nil {
+		return err
+	}
 
+	_, err = p.db.Exec(
+		"INSERT INTO ai_quota_limits (user_id, daily_limit) VALUES ($1, $2)",
+		userID, *limit,
+	)
 	return err
 }
 
-// GetActiveBan проверяет, есть ли активный бан у пользователя
-func (p *PostgreSQLProvider) GetActiveBan(userID string) (*Ban, error) {
+// RecordAIUsage сохраняет сведения об обращении к провайдеру AI
+func (p *PostgreSQLProvider) RecordAIUsage(record AIUsageRecord) error {
+	success := 0
+	if record.Success {
+		success = 1
+	}
+
+	_, err := p.db.Exec(
+		"INSERT INTO ai_usage (user_id, guild_id, provider, model, latency_ms, prompt_tokens, completion_tokens, success, error_message, usage_day, timestamp) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		record.UserID, record.GuildID, record.Provider, record.Model, record.LatencyMs,
+		record.PromptTokens, record.CompletionTokens, success, record.Error, record.Timestamp.UTC().Format("2006-01-02"), record.Timestamp,
+	)
+	return err
+}
+
+// GetAIUsageSummary возвращает использование AI по дням и провайдерам начиная с указанной даты (2006-01-02)
+func (p *PostgreSQLProvider) GetAIUsageSummary(since string) ([]AIUsageSummary, error) {
+	rows, err := p.db.Query(
+		"SELECT usage_day, provider, COUNT(*), SUM(1 - success), SUM(prompt_tokens), SUM(completion_tokens), SUM(latency_ms) "+
+			"FROM ai_usage WHERE usage_day >= $1 GROUP BY usage_day, provider ORDER BY usage_day, provider",
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []AIUsageSummary
+	for rows.Next() {
+		var s AIUsageSummary
+		err := rows.Scan(&s.Day, &s.Provider, &s.Requests, &s.Failures, &s.PromptTokens, &s.CompletionTokens, &s.TotalLatencyMs)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, s)
+	}
+
+	return summaries, rows.Err()
+}
+
+// GetAIPersonas возвращает персоны AI сервера, упорядоченные по имени
+func (p *PostgreSQLProvider) GetAIPersonas(guildID string) ([]AIPersona, error) {
+	rows, err := p.db.Query(
+		"SELECT guild_id, name, system_prompt, provider, temperature, allowed_channels, is_default FROM ai_personas WHERE guild_id = $1 ORDER BY name",
+		guildID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var personas []AIPersona
+	for rows.Next() {
+		persona, err := scanAIPersona(rows)
+		if err != nil {
+			return nil, err
+		}
+		personas = append(personas, persona)
+	}
+
+	return personas, rows.Err()
+}
+
+// GetAIPersona возвращает персону AI сервера по имени или nil, если она не найдена
+func (p *PostgreSQLProvider) GetAIPersona(guildID, name string) (*AIPersona, error) {
 	row := p.db.QueryRow(
-		"SELECT id, user_id, reason, admin_id, timestamp, expires_at FROM bans WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > $2)",
-		userID, time.Now(),
+		"SELECT guild_id, name, system_prompt, provider, temperature, allowed_channels, is_default FROM ai_personas WHERE guild_id = $1 AND name = $2",
+		guildID, name,
 	)
 
-	var ban Ban
-	var expiresAt sql.NullTime
+	persona, err := scanAIPersona(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &persona, nil
+}
+
+// SaveAIPersona создает или обновляет персону AI сервера
+func (p *PostgreSQLProvider) SaveAIPersona(persona AIPersona) error {
+	// Персона по умолчанию на сервере может быть только одна
+	if persona.IsDefault {
+		_, err := p.db.Exec("UPDATE ai_personas SET is_default = 0 WHERE guild_id = $1", persona.GuildID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := p.db.Exec("DELETE FROM ai_personas WHERE guild_id = $1 AND name = $2", persona.GuildID, persona.Name)
+	if err != nil {
+		return err
+	}
 
-	err := row.Scan(&ban.ID, &ban.UserID, &ban.Reason, &ban.AdminID, &ban.Timestamp, &expiresAt)
+	isDefault := 0
+	if persona.IsDefault {
+		isDefault = 1
+	}
+
+	_, err = p.db.Exec(
+		"INSERT INTO ai_personas (guild_id, name, system_prompt, provider, temperature, allowed_channels, is_default) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		persona.GuildID, persona.Name, persona.SystemPrompt, persona.Provider,
+		persona.Temperature, joinChannels(persona.AllowedChannels), isDefault,
+	)
+	return err
+}
+
+// DeleteAIPersona удаляет персону AI сервера
+func (p *PostgreSQLProvider) DeleteAIPersona(guildID, name string) error {
+	_, err := p.db.Exec("DELETE FROM ai_personas WHERE guild_id = $1 AND name = $2", guildID, name)
+	return err
+}
+
+// SaveReportMessage сохраняет сообщение репорта, отправленное в канал модерации
+func (p *PostgreSQLProvider) SaveReportMessage(msg ReportMessage) error {
+	status := msg.Status
+	if status == "" {
+		status = ReportStatusPending
+	}
+
+	_, err := p.db.Exec(
+		"INSERT INTO report_messages (message_id, report_id, guild_id, channel_id, reported_user_id, reporter_id, reason, status, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		msg.MessageID, msg.ReportID, msg.GuildID, msg.ChannelID,
+		msg.ReportedUserID, msg.ReporterID, msg.Reason, string(status), time.Now(),
+	)
+	return err
+}
+
+// GetReportMessage возвращает сообщение репорта по ID сообщения Discord или nil, если оно не найдено
+func (p *PostgreSQLProvider) GetReportMessage(messageID string) (*ReportMessage, error) {
+	row := p.db.QueryRow(
+		"SELECT message_id, report_id, guild_id, channel_id, reported_user_id, reporter_id, reason, status, created_at FROM report_messages WHERE message_id = $1",
+		messageID,
+	)
+
+	msg, err := scanReportMessage(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -191,11 +700,104 @@ func (p *PostgreSQLProvider) GetActiveBan(userID string) (*Ban, error) {
 		return nil, err
 	}
 
-	if expiresAt.Valid {
-		ban.ExpiresAt = &expiresAt.Time
+	return &msg, nil
+}
+
+// SetReportMessageStatus меняет статус сообщения репорта
+func (p *PostgreSQLProvider) SetReportMessageStatus(messageID string, status ReportStatus) error {
+	_, err := p.db.Exec("UPDATE report_messages SET status = $1 WHERE message_id = $2", string(status), messageID)
+	return err
+}
+
+// GetPendingReportMessages возвращает нерассмотренные репорты сервера (пусто - всех серверов)
+func (p *PostgreSQLProvider) GetPendingReportMessages(guildID string) ([]ReportMessage, error) {
+	query := "SELECT message_id, report_id, guild_id, channel_id, reported_user_id, reporter_id, reason, status, created_at FROM report_messages WHERE status = $1"
+	args := []interface{}{string(ReportStatusPending)}
+	if guildID != "" {
+		query += " AND guild_id = $2"
+		args = append(args, guildID)
 	}
 
-	return &ban, nil
+	rows, err := p.db.Query(query+" ORDER BY report_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []ReportMessage
+	for rows.Next() {
+		msg, err := scanReportMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// AddCase сохраняет модерационный случай и возвращает его номер на сервере
+func (p *PostgreSQLProvider) AddCase(c ModerationCase) (int64, error) {
+	number, err := p.nextCaseNumber(c.GuildID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = p.db.Exec(
+		"INSERT INTO cases (guild_id, number, action_type, user_id, moderator_id, reason, duration_seconds, report_id, ban_id, evidence, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		c.GuildID, number, string(c.Action), c.UserID, c.ModeratorID, c.Reason,
+		caseDuration(c), nullableID(c.ReportID), nullableID(c.BanID), caseEvidence(c), time.Now(),
+	)
+	if isUniqueViolation(err) {
+		return 0, errCaseNumberTaken
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+// nextCaseNumber возвращает следующий номер модерационного случая на сервере
+func (p *PostgreSQLProvider) nextCaseNumber(guildID string) (int64, error) {
+	var number int64
+	err := p.db.QueryRow("SELECT COALESCE(MAX(number), 0) + 1 FROM cases WHERE guild_id = $1", guildID).Scan(&number)
+	return number, err
+}
+
+// GetCase возвращает модерационный случай по номеру на сервере или nil, если он не найден
+func (p *PostgreSQLProvider) GetCase(guildID string, number int64) (*ModerationCase, error) {
+	row := p.db.QueryRow("SELECT "+caseColumns+" FROM cases WHERE guild_id = $1 AND number = $2", guildID, number)
+
+	c, err := scanCase(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// UpdateCaseReason меняет причину модерационного случая и запоминает, кто и когда ее изменил
+func (p *PostgreSQLProvider) UpdateCaseReason(guildID string, number int64, reason, moderatorID string) error {
+	_, err := p.db.Exec(
+		"UPDATE cases SET reason = $1, updated_by = $2, updated_at = $3 WHERE guild_id = $4 AND number = $5",
+		reason, moderatorID, time.Now(), guildID, number,
+	)
+	return err
+}
+
+// CountCases возвращает количество случаев с действием action у пользователя на сервере
+func (p *PostgreSQLProvider) CountCases(guildID, userID string, action CaseAction) (int, error) {
+	var count int
+	err := p.db.QueryRow(
+		"SELECT COUNT(*) FROM cases WHERE guild_id = $1 AND user_id = $2 AND action_type = $3",
+		guildID, userID, string(action),
+	).Scan(&count)
+
+	return count, err
 }
 
 // GetType возвращает тип базы данных
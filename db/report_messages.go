@@ -0,0 +1,101 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetReportMessage возвращает сообщение репорта по ID сообщения Discord или nil, если оно не найдено
+func GetReportMessage(messageID string) (*ReportMessage, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetReportMessage(messageID)
+}
+
+// SaveReportMessage сохраняет сообщение репорта, отправленное в канал модерации
+func SaveReportMessage(msg ReportMessage) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.SaveReportMessage(msg)
+}
+
+// SetReportMessageStatus меняет статус сообщения репорта
+func SetReportMessageStatus(messageID string, status ReportStatus) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.SetReportMessageStatus(messageID, status)
+}
+
+// GetPendingReportMessages возвращает нерассмотренные репорты сервера (пусто - всех серверов),
+// упорядоченные по ID репорта
+func GetPendingReportMessages(guildID string) ([]ReportMessage, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetPendingReportMessages(guildID)
+}
+
+// scanReportMessage читает сообщение репорта из строки результата SQL запроса
+func scanReportMessage(row rowScanner) (ReportMessage, error) {
+	var msg ReportMessage
+	var guildID sql.NullString
+	var status string
+	var createdAt timestampValue
+
+	err := row.Scan(&msg.MessageID, &msg.ReportID, &guildID, &msg.ChannelID,
+		&msg.ReportedUserID, &msg.ReporterID, &msg.Reason, &status, &createdAt)
+	if err != nil {
+		return msg, err
+	}
+
+	msg.GuildID = guildID.String
+	msg.Status = ReportStatus(status)
+	msg.Timestamp = createdAt.Time
+	return msg, nil
+}
+
+// timestampValue читает время, которое драйверы возвращают как time.Time или как строку:
+// RFC3339 в SQLite и DATETIME в MySQL без параметра parseTime
+type timestampValue struct {
+	time.Time
+}
+
+// timestampLayouts - форматы строкового представления времени
+var timestampLayouts = []string{time.RFC3339, "2006-01-02 15:04:05"}
+
+// Scan реализует sql.Scanner
+func (t *timestampValue) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		t.Time = time.Time{}
+	case time.Time:
+		t.Time = v
+	case string:
+		return t.parse(v)
+	case []byte:
+		return t.parse(string(v))
+	default:
+		return fmt.Errorf("неподдерживаемый тип времени: %T", value)
+	}
+	return nil
+}
+
+func (t *timestampValue) parse(value string) error {
+	var err error
+	for _, layout := range timestampLayouts {
+		var parsed time.Time
+		if parsed, err = time.Parse(layout, value); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,182 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ReportStatus - статус рассмотрения репорта
+type ReportStatus string
+
+const (
+	ReportStatusPending   ReportStatus = "pending"   // Репорт ожидает рассмотрения
+	ReportStatusConfirmed ReportStatus = "confirmed" // Репорт подтвержден
+	ReportStatusRejected  ReportStatus = "rejected"  // Репорт отклонен
+	ReportStatusEscalated ReportStatus = "escalated" // По репорту применено более строгое наказание (бан)
+)
+
+// ErrReportAlreadyReviewed возвращается SetReportStatus, если репорт уже рассмотрен или не найден
+var ErrReportAlreadyReviewed = errors.New("репорт уже рассмотрен")
+
+// Confirmed возвращает true для статусов, которые учитываются в пороге репортов
+func (s ReportStatus) Confirmed() bool {
+	return s == ReportStatusConfirmed || s == ReportStatusEscalated
+}
+
+// RecordReport сохраняет репорт вместе с сервером, на котором он создан, и возвращает его ID
+func RecordReport(report Report) (int64, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return 0, err
+	}
+	return provider.AddReport(report)
+}
+
+// GetReport возвращает репорт по ID или nil, если он не найден
+func GetReport(reportID int64) (*Report, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetReport(reportID)
+}
+
+// GetReportsByUser возвращает репорты на пользователя на сервере, начиная с последнего
+func GetReportsByUser(guildID, userID string) ([]Report, error) {
+	provider, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetReportsByUser(guildID, userID)
+}
+
+// SetReportStatus сохраняет решение модератора по репорту и время решения.
+// Подтвержденные и эскалированные репорты учитываются в пороге репортов.
+// Решение сохраняется только для нерассмотренного репорта, иначе возвращается ErrReportAlreadyReviewed
+func SetReportStatus(reportID int64, status ReportStatus, moderatorID string) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.SetReportStatus(reportID, status, moderatorID)
+}
+
+// ReopenReport возвращает репорт к рассмотрению, например если бан по эскалированному репорту не удался
+func ReopenReport(reportID int64) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.ReopenReport(reportID)
+}
+
+// checkReportReviewed возвращает ErrReportAlreadyReviewed, если условное обновление статуса
+// не затронуло ни одной строки: репорт уже рассмотрен другим модератором или не существует
+func checkReportReviewed(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrReportAlreadyReviewed
+	}
+	return nil
+}
+
+// SetReportNote сохраняет заметку модератора к репорту
+func SetReportNote(reportID int64, note string) error {
+	provider, err := currentProvider()
+	if err != nil {
+		return err
+	}
+	return provider.SetReportNote(reportID, note)
+}
+
+// reportColumns - столбцы таблицы reports в порядке, ожидаемом scanReport
+const reportColumns = "id, reported_user_id, reporter_id, reason, timestamp, confirmed, confirmed_by, status, note, decided_by, decided_at, guild_id"
+
+// scanReport читает репорт из строки результата SQL запроса
+func scanReport(row rowScanner) (Report, error) {
+	var r Report
+	var timestamp, decidedAt timestampValue
+	var confirmedBy, status, note, decidedBy, guildID sql.NullString
+
+	err := row.Scan(&r.ID, &r.ReportedUserID, &r.ReporterID, &r.Reason, &timestamp,
+		&r.Confirmed, &confirmedBy, &status, &note, &decidedBy, &decidedAt, &guildID)
+	if err != nil {
+		return r, err
+	}
+
+	r.Timestamp = timestamp.Time
+	r.ConfirmedBy = confirmedBy.String
+	r.Status = ReportStatus(status.String)
+	if r.Status == "" {
+		r.Status = ReportStatusPending
+	}
+	r.Note = note.String
+	r.DecidedBy = decidedBy.String
+	r.GuildID = guildID.String
+	if !decidedAt.IsZero() {
+		t := decidedAt.Time
+		r.DecidedAt = &t
+	}
+	return r, nil
+}
+
+// scanReports читает все репорты из результата SQL запроса
+func scanReports(rows *sql.Rows) ([]Report, error) {
+	defer rows.Close()
+
+	var reports []Report
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+// migrateReportColumns добавляет столбцы рассмотрения репорта в таблицы, созданные до их появления,
+// и переносит статус подтвержденных ранее репортов
+func migrateReportColumns(db *sql.DB, addColumns []string) error {
+	if err := addMissingColumns(db, addColumns); err != nil {
+		return err
+	}
+
+	_, err := db.Exec("UPDATE reports SET status = 'confirmed' WHERE confirmed = TRUE AND status = 'pending'")
+	return err
+}
+
+// addMissingColumns добавляет столбцы в таблицы, созданные до их появления. Не все СУБД поддерживают
+// ADD COLUMN IF NOT EXISTS, поэтому игнорируется только ошибка добавления уже существующего столбца
+func addMissingColumns(db *sql.DB, statements []string) error {
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil && !isDuplicateColumn(err) {
+			return fmt.Errorf("%s: %w", statement, err)
+		}
+	}
+	return nil
+}
+
+// isDuplicateColumn проверяет, отклонила ли СУБД добавление столбца, потому что он уже существует
+func isDuplicateColumn(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "42701" // duplicate_column
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1060 // ER_DUP_FIELDNAME
+	}
+
+	// SQLite и Firebird возвращают только текст ошибки
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "duplicate column name") || strings.Contains(message, "already exists")
+}
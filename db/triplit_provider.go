@@ -45,7 +45,7 @@ func (p *TriplitProvider) Close() error {
 }
 
 // AddReport добавляет новый репорт в базу данных
-func (p *TriplitProvider) AddReport(reportedUserID, reporterID, reason string) (int64, error) {
+func (p *TriplitProvider) AddReport(report Report) (int64, error) {
 	// Заглушка для добавления репорта
 	// В реальной реализации здесь должен быть код для работы с API Triplit
 	return time.Now().Unix(), fmt.Errorf("метод AddReport не реализован для Triplit")
@@ -57,22 +57,46 @@ func (p *TriplitProvider) ConfirmReport(reportID int64, adminID string) error {
 	return fmt.Errorf("метод ConfirmReport не реализован для Triplit")
 }
 
-// GetReportsByUser получает все репорты на указанного пользователя
-func (p *TriplitProvider) GetReportsByUser(userID string) ([]Report, error) {
+// GetReportsByUser получает все репорты на указанного пользователя на сервере
+func (p *TriplitProvider) GetReportsByUser(guildID, userID string) ([]Report, error) {
 	// Заглушка для получения репортов
 	return nil, fmt.Errorf("метод GetReportsByUser не реализован для Triplit")
 }
 
-// GetReportCount получает количество подтвержденных репортов на пользователя
-func (p *TriplitProvider) GetReportCount(userID string) (int, error) {
+// GetReport возвращает репорт по ID или nil, если он не найден
+func (p *TriplitProvider) GetReport(reportID int64) (*Report, error) {
+	// Заглушка для получения репорта
+	return nil, fmt.Errorf("метод GetReport не реализован для Triplit")
+}
+
+// SetReportStatus сохраняет решение модератора по репорту
+func (p *TriplitProvider) SetReportStatus(reportID int64, status ReportStatus, moderatorID string) error {
+	// Заглушка для изменения статуса репорта
+	return fmt.Errorf("метод SetReportStatus не реализован для Triplit")
+}
+
+// ReopenReport возвращает эскалированный репорт к рассмотрению
+func (p *TriplitProvider) ReopenReport(reportID int64) error {
+	// Заглушка для возврата репорта к рассмотрению
+	return fmt.Errorf("метод ReopenReport не реализован для Triplit")
+}
+
+// SetReportNote сохраняет заметку модератора к репорту
+func (p *TriplitProvider) SetReportNote(reportID int64, note string) error {
+	// Заглушка для сохранения заметки
+	return fmt.Errorf("метод SetReportNote не реализован для Triplit")
+}
+
+// GetReportCount получает количество подтвержденных репортов на пользователя на сервере
+func (p *TriplitProvider) GetReportCount(guildID, userID string) (int, error) {
 	// Заглушка для получения количества репортов
 	return 0, fmt.Errorf("метод GetReportCount не реализован для Triplit")
 }
 
 // AddBan добавляет новый бан в базу данных
-func (p *TriplitProvider) AddBan(userID, reason, adminID string, duration *time.Duration) error {
+func (p *TriplitProvider) AddBan(ban Ban) (int64, error) {
 	// Заглушка для добавления бана
-	return fmt.Errorf("метод AddBan не реализован для Triplit")
+	return 0, fmt.Errorf("метод AddBan не реализован для Triplit")
 }
 
 // GetActiveBan проверяет, есть ли активный бан у пользователя
@@ -81,6 +105,168 @@ func (p *TriplitProvider) GetActiveBan(userID string) (*Ban, error) {
 	return nil, fmt.Errorf("метод GetActiveBan не реализован для Triplit")
 }
 
+// GetActiveBans возвращает действующие баны
+func (p *TriplitProvider) GetActiveBans() ([]Ban, error) {
+	// Заглушка для получения действующих банов
+	return nil, fmt.Errorf("метод GetActiveBans не реализован для Triplit")
+}
+
+// GetBansByUser возвращает все баны пользователя
+func (p *TriplitProvider) GetBansByUser(userID string) ([]Ban, error) {
+	// Заглушка для получения банов пользователя
+	return nil, fmt.Errorf("метод GetBansByUser не реализован для Triplit")
+}
+
+// GetExpiredBans возвращает истекшие баны, которые еще не сняты
+func (p *TriplitProvider) GetExpiredBans() ([]Ban, error) {
+	// Заглушка для получения истекших банов
+	return nil, fmt.Errorf("метод GetExpiredBans не реализован для Triplit")
+}
+
+// LiftBan отмечает бан снятым
+func (p *TriplitProvider) LiftBan(banID int64, liftedBy string) error {
+	// Заглушка для снятия бана
+	return fmt.Errorf("метод LiftBan не реализован для Triplit")
+}
+
+// AddConversationMessage сохраняет сообщение диалога с AI
+func (p *TriplitProvider) AddConversationMessage(msg ConversationMessage) error {
+	// Заглушка для сохранения сообщения диалога
+	return fmt.Errorf("метод AddConversationMessage не реализован для Triplit")
+}
+
+// AddConversationReplies связывает сообщения Discord с ответом модели с диалогом
+func (p *TriplitProvider) AddConversationReplies(sessionID string, messageIDs []string) error {
+	// Заглушка для сохранения сообщений ответа
+	return fmt.Errorf("метод AddConversationReplies не реализован для Triplit")
+}
+
+// GetConversationMessages возвращает последние limit сообщений диалога в хронологическом порядке
+func (p *TriplitProvider) GetConversationMessages(sessionID string, limit int) ([]ConversationMessage, error) {
+	// Заглушка для получения истории диалога
+	return nil, fmt.Errorf("метод GetConversationMessages не реализован для Triplit")
+}
+
+// GetConversationSession возвращает диалог, к которому относится сообщение бота, или пустую строку
+func (p *TriplitProvider) GetConversationSession(messageID string) (string, error) {
+	// Заглушка для поиска диалога по сообщению
+	return "", fmt.Errorf("метод GetConversationSession не реализован для Triplit")
+}
+
+// ClearConversation удаляет историю диалога
+func (p *TriplitProvider) ClearConversation(sessionID string) error {
+	// Заглушка для очистки истории диалога
+	return fmt.Errorf("метод ClearConversation не реализован для Triplit")
+}
+
+// GetAIQuota возвращает использование AI пользователем за день и его индивидуальный лимит
+func (p *TriplitProvider) GetAIQuota(userID, day string) (*AIQuota, error) {
+	// Заглушка для получения квоты
+	return nil, fmt.Errorf("метод GetAIQuota не реализован для Triplit")
+}
+
+// IncrementAIQuota увеличивает счетчик запросов пользователя к AI за день
+func (p *TriplitProvider) IncrementAIQuota(userID, day string) error {
+	// Заглушка для учета запроса
+	return fmt.Errorf("метод IncrementAIQuota не реализован для Triplit")
+}
+
+// ResetAIQuota обнуляет счетчик запросов пользователя к AI за день
+func (p *TriplitProvider) ResetAIQuota(userID, day string) error {
+	// Заглушка для сброса квоты
+	return fmt.Errorf("метод ResetAIQuota не реализован для Triplit")
+}
+
+// SetAIQuotaLimit устанавливает индивидуальный дневной лимит пользователя (nil - лимит из конфигурации)
+func (p *TriplitProvider) SetAIQuotaLimit(userID string, limit *int) error {
+	// Заглушка для изменения лимита
+	return fmt.Errorf("метод SetAIQuotaLimit не реализован для Triplit")
+}
+
+// RecordAIUsage сохраняет сведения об обращении к провайдеру AI
+func (p *TriplitProvider) RecordAIUsage(record AIUsageRecord) error {
+	// Заглушка для учета использования AI
+	return fmt.Errorf("метод RecordAIUsage не реализован для Triplit")
+}
+
+// GetAIUsageSummary возвращает использование AI по дням и провайдерам начиная с указанной даты (2006-01-02)
+func (p *TriplitProvider) GetAIUsageSummary(since string) ([]AIUsageSummary, error) {
+	// Заглушка для получения статистики использования AI
+	return nil, fmt.Errorf("метод GetAIUsageSummary не реализован для Triplit")
+}
+
+// GetAIPersonas возвращает персоны AI сервера, упорядоченные по имени
+func (p *TriplitProvider) GetAIPersonas(guildID string) ([]AIPersona, error) {
+	// Заглушка для получения персон
+	return nil, fmt.Errorf("метод GetAIPersonas не реализован для Triplit")
+}
+
+// GetAIPersona возвращает персону AI сервера по имени или nil, если она не найдена
+func (p *TriplitProvider) GetAIPersona(guildID, name string) (*AIPersona, error) {
+	// Заглушка для получения персоны
+	return nil, fmt.Errorf("метод GetAIPersona не реализован для Triplit")
+}
+
+// SaveAIPersona создает или обновляет персону AI сервера
+func (p *TriplitProvider) SaveAIPersona(persona AIPersona) error {
+	// Заглушка для сохранения персоны
+	return fmt.Errorf("метод SaveAIPersona не реализован для Triplit")
+}
+
+// DeleteAIPersona удаляет персону AI сервера
+func (p *TriplitProvider) DeleteAIPersona(guildID, name string) error {
+	// Заглушка для удаления персоны
+	return fmt.Errorf("метод DeleteAIPersona не реализован для Triplit")
+}
+
+// SaveReportMessage сохраняет сообщение репорта, отправленное в канал модерации
+func (p *TriplitProvider) SaveReportMessage(msg ReportMessage) error {
+	// Заглушка для сохранения сообщения репорта
+	return fmt.Errorf("метод SaveReportMessage не реализован для Triplit")
+}
+
+// GetReportMessage возвращает сообщение репорта по ID сообщения Discord или nil, если оно не найдено
+func (p *TriplitProvider) GetReportMessage(messageID string) (*ReportMessage, error) {
+	// Заглушка для получения сообщения репорта
+	return nil, fmt.Errorf("метод GetReportMessage не реализован для Triplit")
+}
+
+// SetReportMessageStatus меняет статус сообщения репорта
+func (p *TriplitProvider) SetReportMessageStatus(messageID string, status ReportStatus) error {
+	// Заглушка для изменения статуса сообщения репорта
+	return fmt.Errorf("метод SetReportMessageStatus не реализован для Triplit")
+}
+
+// GetPendingReportMessages возвращает нерассмотренные репорты сервера (пусто - всех серверов)
+func (p *TriplitProvider) GetPendingReportMessages(guildID string) ([]ReportMessage, error) {
+	// Заглушка для получения нерассмотренных репортов
+	return nil, fmt.Errorf("метод GetPendingReportMessages не реализован для Triplit")
+}
+
+// AddCase сохраняет модерационный случай
+func (p *TriplitProvider) AddCase(c ModerationCase) (int64, error) {
+	// Заглушка для сохранения модерационного случая
+	return 0, fmt.Errorf("метод AddCase не реализован для Triplit")
+}
+
+// GetCase возвращает модерационный случай по номеру на сервере
+func (p *TriplitProvider) GetCase(guildID string, number int64) (*ModerationCase, error) {
+	// Заглушка для получения модерационного случая
+	return nil, fmt.Errorf("метод GetCase не реализован для Triplit")
+}
+
+// UpdateCaseReason меняет причину модерационного случая
+func (p *TriplitProvider) UpdateCaseReason(guildID string, number int64, reason, moderatorID string) error {
+	// Заглушка для изменения модерационного случая
+	return fmt.Errorf("метод UpdateCaseReason не реализован для Triplit")
+}
+
+// CountCases возвращает количество случаев пользователя
+func (p *TriplitProvider) CountCases(guildID, userID string, action CaseAction) (int, error) {
+	// Заглушка для подсчета модерационных случаев
+	return 0, fmt.Errorf("метод CountCases не реализован для Triplit")
+}
+
 // GetType возвращает тип базы данных
 func (p *TriplitProvider) GetType() string {
 	return "triplit"
@@ -1,133 +1,38 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"discord-bot/ai"
+	"discord-bot/db"
 	"discord-bot/localization"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-// Глобальные переменные для хранения команд приложения
-var (
-	aiCommands []*discordgo.ApplicationCommand
-	aiHandlers map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate)
-)
+// aiRequestTimeout ограничивает время ожидания ответа от AI
+const aiRequestTimeout = 2 * time.Minute
 
-// InitAICommands инициализирует команды AI для Discord
-func InitAICommands(s *discordgo.Session) error {
-	// Определяем обработчики команд
-	aiHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
-		"ai":      handleAIInteraction,
-		"gemini":  handleAIModelInteraction,
-		"grok":    handleAIModelInteraction,
-		"chatgpt": handleAIModelInteraction,
-		"qwen":    handleAIModelInteraction,
-		"claude":  handleAIModelInteraction,
-	}
+// queryOption - название параметра слеш-команд AI с текстом запроса
+const queryOption = "query"
 
-	// Определяем команды приложения
-	aiCommands = []*discordgo.ApplicationCommand{
-		{
-			Name:        "ai",
-			Description: "Задать вопрос искусственному интеллекту (используя модель по умолчанию)",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "запрос",
-					Description: "Ваш вопрос или запрос к AI",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "gemini",
-			Description: "Задать вопрос Gemini AI",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "запрос",
-					Description: "Ваш вопрос или запрос к Gemini",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "grok",
-			Description: "Задать вопрос Grok AI",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "запрос",
-					Description: "Ваш вопрос или запрос к Grok",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "chatgpt",
-			Description: "Задать вопрос ChatGPT",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "запрос",
-					Description: "Ваш вопрос или запрос к ChatGPT",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "qwen",
-			Description: "Задать вопрос Qwen AI",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "запрос",
-					Description: "Ваш вопрос или запрос к Qwen",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "claude",
-			Description: "Задать вопрос Claude AI",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "запрос",
-					Description: "Ваш вопрос или запрос к Claude",
-					Required:    true,
-				},
-			},
-		},
-	}
-
-	// Регистрируем обработчик интеракций
-	s.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		if i.Type == discordgo.InteractionApplicationCommand {
-			if h, ok := aiHandlers[i.ApplicationCommandData().Name]; ok {
-				h(s, i)
-			}
-		}
-	})
+// HandleAICommand обрабатывает текстовые команды AI (для обратной совместимости)
+func HandleAICommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	images := imageAttachments(m.Attachments)
 
-	// Регистрируем команды в Discord
-	for _, cmd := range aiCommands {
-		_, err := s.ApplicationCommandCreate(s.State.User.ID, "", cmd)
-		if err != nil {
-			return fmt.Errorf("не удалось создать команду %s: %w", cmd.Name, err)
+	if len(args) == 0 && len(images) == 0 {
+		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("ai_usage", cfg.Prefix)); err != nil {
+			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
 		}
+		return
 	}
 
-	return nil
-}
-
-// HandleAICommand обрабатывает текстовые команды AI (для обратной совместимости)
-func HandleAICommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	if len(args) == 0 {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("ai_usage", cfg.Prefix)); err != nil {
+	// Очистка истории диалога в канале
+	if len(args) == 1 && strings.EqualFold(args[0], "reset") {
+		if _, err := s.ChannelMessageSend(m.ChannelID, resetConversation(m.ChannelID)); err != nil {
 			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
 		}
 		return
@@ -137,36 +42,123 @@ func HandleAICommand(s *discordgo.Session, m *discordgo.MessageCreate, args []st
 	var modelName string
 	var prompt string
 
-	if len(args) > 1 && isAIModel(args[0]) {
+	if len(args) > 0 && isAIModel(args[0]) && (len(args) > 1 || len(images) > 0) {
 		modelName = args[0]
 		prompt = strings.Join(args[1:], " ")
 	} else {
 		modelName = ""
 		prompt = strings.Join(args, " ")
 	}
+	if prompt == "" {
+		prompt = localization.GetText("ai_image_default_prompt")
+	}
 
-	// Отправляем сообщение о том, что запрос обрабатывается
-	if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("ai_processing")); err != nil {
+	// Проверяем квоту и частоту запросов
+	if denial := checkAILimits(m.Author.ID, m.GuildID, memberRoles(m.Member)); denial != "" {
+		if _, err := s.ChannelMessageSend(m.ChannelID, denial); err != nil {
+			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
+		}
+		return
+	}
+
+	// Отправляем сообщение-заглушку, которое будет дополняться по мере генерации ответа
+	target := &channelStreamTarget{s: s, channelID: m.ChannelID}
+	placeholderID, err := target.create(localization.GetText("ai_processing"))
+	if err != nil {
 		fmt.Printf("Ошибка отправки сообщения: %v\n", err)
 	}
 
-	// Получаем ответ от AI
-	response, err := generateAIResponse(modelName, prompt)
+	streamAIResponse(newAIStreamWriter(target, placeholderID), aiPrompt{
+		userID:    m.Author.ID,
+		guildID:   m.GuildID,
+		modelName: modelName,
+		sessionID: m.ChannelID,
+		text:      prompt,
+		persona:   defaultPersona(m.GuildID, m.ChannelID),
+		images:    images,
+	})
+}
+
+// HandleAIReply продолжает диалог, если сообщение является ответом на ответ AI.
+// Возвращает true, если сообщение было обработано.
+func HandleAIReply(s *discordgo.Session, m *discordgo.MessageCreate) bool {
+	ref := m.ReferencedMessage
+	if ref == nil || ref.Author == nil || ref.Author.ID != s.State.User.ID || !aiMemoryEnabled() {
+		return false
+	}
+
+	// Продолжаем только диалоги, в которых сообщение бота было ответом AI
+	sessionID, err := db.GetConversationSession(ref.ID)
 	if err != nil {
-		if _, err2 := s.ChannelMessageSend(m.ChannelID, localization.GetText("ai_error", err.Error())); err2 != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err2)
+		fmt.Printf("Ошибка поиска диалога: %v\n", err)
+		return false
+	}
+	images := imageAttachments(m.Attachments)
+	prompt := strings.TrimSpace(m.Content)
+	if sessionID == "" || (prompt == "" && len(images) == 0) {
+		return false
+	}
+	if prompt == "" {
+		prompt = localization.GetText("ai_image_default_prompt")
+	}
+
+	if denial := checkAILimits(m.Author.ID, m.GuildID, memberRoles(m.Member)); denial != "" {
+		if _, err := s.ChannelMessageSend(m.ChannelID, denial); err != nil {
+			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
 		}
-		return
+		return true
+	}
+
+	target := &channelStreamTarget{s: s, channelID: m.ChannelID}
+	placeholderID, err := target.create(localization.GetText("ai_processing"))
+	if err != nil {
+		fmt.Printf("Ошибка отправки сообщения: %v\n", err)
 	}
 
-	// Отправляем ответ
-	sendAIResponse(s, m.ChannelID, response)
+	streamAIResponse(newAIStreamWriter(target, placeholderID), aiPrompt{
+		userID:    m.Author.ID,
+		guildID:   m.GuildID,
+		sessionID: sessionID,
+		text:      prompt,
+		persona:   defaultPersona(m.GuildID, m.ChannelID),
+		images:    images,
+	})
+	return true
 }
 
 // handleAIInteraction обрабатывает слеш-команду /ai
 func handleAIInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	options := i.ApplicationCommandData().Options
-	prompt := options[0].StringValue()
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	if subcommand.Name == "reset" {
+		respondEphemeral(s, i, resetConversation(i.ChannelID))
+		return
+	}
+
+	var prompt, personaName string
+	var images []*discordgo.MessageAttachment
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case queryOption:
+			prompt = option.StringValue()
+		case personaOption:
+			personaName = option.StringValue()
+		case imageOption:
+			if attachment := resolvedAttachment(i, option); attachment != nil {
+				images = append(images, attachment)
+			}
+		}
+	}
+
+	persona, denial := resolvePersona(i.GuildID, i.ChannelID, personaName)
+	if denial != "" {
+		respondEphemeral(s, i, denial)
+		return
+	}
+
+	if !allowAIInteraction(s, i) {
+		return
+	}
 
 	// Отправляем сообщение о том, что запрос обрабатывается
 	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -176,19 +168,26 @@ func handleAIInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	// Получаем ответ от AI по умолчанию
-	response, err := generateAIResponse("", prompt)
-	if err != nil {
-		if _, err2 := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
-			Content: localization.GetText("ai_error", err.Error()),
-		}); err2 != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err2)
-		}
-		return
-	}
+	// Получаем ответ от AI по умолчанию, дополняя отложенный ответ по мере генерации
+	target := &interactionStreamTarget{s: s, i: i}
+	streamAIResponse(newAIStreamWriter(target, interactionOriginalMessage), aiPrompt{
+		userID:    interactionUserID(i),
+		guildID:   i.GuildID,
+		sessionID: i.ChannelID,
+		text:      prompt,
+		persona:   persona,
+		images:    images,
+	})
+}
 
-	// Отправляем ответ
-	sendAIInteractionResponse(s, i, response)
+// resolvedAttachment возвращает вложение, переданное в параметре слеш-команды
+func resolvedAttachment(i *discordgo.InteractionCreate, option *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageAttachment {
+	resolved := i.ApplicationCommandData().Resolved
+	id, ok := option.Value.(string)
+	if resolved == nil || !ok {
+		return nil
+	}
+	return resolved.Attachments[id]
 }
 
 // handleAIModelInteraction обрабатывает слеш-команды для конкретных моделей AI
@@ -197,6 +196,10 @@ func handleAIModelInteraction(s *discordgo.Session, i *discordgo.InteractionCrea
 	options := i.ApplicationCommandData().Options
 	prompt := options[0].StringValue()
 
+	if !allowAIInteraction(s, i) {
+		return
+	}
+
 	// Отправляем сообщение о том, что запрос обрабатывается
 	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
@@ -205,87 +208,67 @@ func handleAIModelInteraction(s *discordgo.Session, i *discordgo.InteractionCrea
 		return
 	}
 
-	// Получаем ответ от указанной модели AI
-	response, err := generateAIResponse(modelName, prompt)
-	if err != nil {
-		if _, err2 := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
-			Content: localization.GetText("ai_error", err.Error()),
-		}); err2 != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err2)
-		}
-		return
-	}
-
-	// Отправляем ответ
-	sendAIInteractionResponse(s, i, response)
+	// Получаем ответ от указанной модели AI, дополняя отложенный ответ по мере генерации
+	target := &interactionStreamTarget{s: s, i: i}
+	streamAIResponse(newAIStreamWriter(target, interactionOriginalMessage), aiPrompt{
+		userID:    interactionUserID(i),
+		guildID:   i.GuildID,
+		modelName: modelName,
+		sessionID: i.ChannelID,
+		text:      prompt,
+		persona:   defaultPersona(i.GuildID, i.ChannelID),
+	})
 }
 
-// generateAIResponse генерирует ответ от указанной модели AI
-func generateAIResponse(modelName string, prompt string) (string, error) {
-	var response string
-	var err error
+// allowAIInteraction проверяет ограничения запросов к AI для интеракции.
+// При отказе пользователь получает сообщение, видимое только ему.
+func allowAIInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	user := interactionUser(i)
+	if user == nil {
+		return true
+	}
 
-	if modelName == "" {
-		// Используем провайдер по умолчанию
-		response, err = ai.GenerateResponse(prompt)
-	} else {
-		// Используем указанный провайдер
-		provider, err := ai.GetProvider(modelName)
-		if err != nil {
-			return "", err
-		}
-		response, err = provider.GenerateResponse(prompt)
+	denial := checkAILimits(user.ID, i.GuildID, memberRoles(i.Member))
+	if denial == "" {
+		return true
 	}
 
-	return response, err
+	respondEphemeral(s, i, denial)
+	return false
 }
 
-// sendAIResponse отправляет ответ от AI в текстовый канал
-func sendAIResponse(s *discordgo.Session, channelID string, response string) {
-	// Если ответ слишком длинный, разбиваем его на части
-	if len(response) > 2000 {
-		chunks := splitMessage(response, 2000)
-		for _, chunk := range chunks {
-			if _, err := s.ChannelMessageSend(channelID, chunk); err != nil {
-				fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-			}
-		}
-	} else {
-		if _, err := s.ChannelMessageSend(channelID, response); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+// respondEphemeral отвечает на интеракцию сообщением, видимым только вызвавшему ее пользователю
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}); err != nil {
+		fmt.Printf("Ошибка отправки ответа на взаимодействие: %v\n", err)
 	}
 }
 
-// sendAIInteractionResponse отправляет ответ от AI в ответ на интеракцию
-func sendAIInteractionResponse(s *discordgo.Session, i *discordgo.InteractionCreate, response string) {
-	// Если ответ слишком длинный, разбиваем его на части
-	if len(response) > 2000 {
-		chunks := splitMessage(response, 2000)
-		for index, chunk := range chunks {
-			if index == 0 {
-				// Первый чанк отправляем как основной ответ
-				if _, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
-					Content: chunk,
-				}); err != nil {
-					fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-				}
-			} else {
-				// Остальные чанки отправляем как дополнительные сообщения
-				if _, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
-					Content: chunk,
-				}); err != nil {
-					fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-				}
-			}
-		}
-	} else {
-		if _, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
-			Content: response,
-		}); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+// resetConversation очищает историю диалога в канале и возвращает текст для пользователя
+func resetConversation(channelID string) string {
+	if err := db.ClearConversation(channelID); err != nil {
+		fmt.Printf("Ошибка очистки истории диалога: %v\n", err)
+		return localization.GetText("ai_memory_reset_failed", err.Error())
 	}
+	return localization.GetText("ai_memory_reset")
+}
+
+// aiErrorText возвращает локализованное сообщение об ошибке AI.
+// Временные ошибки (перегрузка, лимиты) получают отдельный текст с предложением повторить запрос.
+func aiErrorText(err error) string {
+	if ai.IsRetryable(err) {
+		return localization.GetText("ai_error_retryable")
+	}
+	if errors.Is(err, ai.ErrImagesUnsupported) {
+		return localization.GetText("ai_images_unsupported")
+	}
+	return localization.GetText("ai_error", err.Error())
 }
 
 // isAIModel проверяет, является ли строка названием модели AI
@@ -303,15 +286,10 @@ func splitMessage(message string, chunkSize int) []string {
 			break
 		}
 
-		// Ищем последний пробел в пределах chunkSize
-		lastSpace := strings.LastIndex(message[:chunkSize], " ")
-		if lastSpace == -1 {
-			// Если пробел не найден, просто разбиваем по размеру чанка
-			lastSpace = chunkSize
-		}
-
-		chunks = append(chunks, message[:lastSpace])
-		message = message[lastSpace+1:]
+		// Разбиваем по последнему переводу строки или пробелу в пределах chunkSize
+		cut := splitIndex(message, chunkSize)
+		chunks = append(chunks, message[:cut])
+		message = strings.TrimLeft(message[cut:], " \n")
 	}
 	return chunks
 }
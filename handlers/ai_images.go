@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"discord-bot/ai"
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxAIImageSize - максимальный размер изображения. 5 МБ - ограничение Claude,
+	// самое строгое среди провайдеров с поддержкой изображений
+	maxAIImageSize = 5 << 20
+	// maxAIImages - максимальное количество изображений в одном запросе
+	maxAIImages = 4
+	// imageOption - название параметра /ai ask для изображения
+	imageOption = "image"
+)
+
+// supportedImageTypes - форматы изображений, которые принимают все провайдеры с поддержкой изображений
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Ошибки загрузки изображений
+var (
+	errImageTooLarge = errors.New("изображение превышает допустимый размер")
+	errImageType     = errors.New("формат изображения не поддерживается")
+	errTooManyImages = errors.New("слишком много изображений")
+)
+
+// imageDownloadClient загружает вложения Discord. Время загрузки ограничено контекстом запроса к AI
+var imageDownloadClient = &http.Client{}
+
+// imageAttachments отбирает изображения среди вложений сообщения
+func imageAttachments(attachments []*discordgo.MessageAttachment) []*discordgo.MessageAttachment {
+	var images []*discordgo.MessageAttachment
+	for _, attachment := range attachments {
+		if strings.HasPrefix(attachment.ContentType, "image/") {
+			images = append(images, attachment)
+		}
+	}
+	return images
+}
+
+// attachmentMIMEType возвращает тип вложения без параметров
+func attachmentMIMEType(attachment *discordgo.MessageAttachment) string {
+	mimeType, _, _ := strings.Cut(attachment.ContentType, ";")
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+// downloadImages загружает вложения Discord, проверяя их количество, формат и размер
+func downloadImages(ctx context.Context, attachments []*discordgo.MessageAttachment) ([]ai.Image, error) {
+	if len(attachments) > maxAIImages {
+		return nil, errTooManyImages
+	}
+
+	images := make([]ai.Image, 0, len(attachments))
+	for _, attachment := range attachments {
+		mimeType := attachmentMIMEType(attachment)
+		if !supportedImageTypes[mimeType] {
+			return nil, fmt.Errorf("%s: %w", attachment.Filename, errImageType)
+		}
+		if attachment.Size > maxAIImageSize {
+			return nil, fmt.Errorf("%s: %w", attachment.Filename, errImageTooLarge)
+		}
+
+		data, err := downloadAttachment(ctx, attachment.URL)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка загрузки %s: %w", attachment.Filename, err)
+		}
+		images = append(images, ai.Image{MIMEType: mimeType, Data: data})
+	}
+	return images, nil
+}
+
+// downloadAttachment загружает вложение, не читая больше maxAIImageSize байт
+func downloadAttachment(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := imageDownloadClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	// Размер из метаданных вложения может не совпадать с фактическим
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAIImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAIImageSize {
+		return nil, errImageTooLarge
+	}
+	return data, nil
+}
+
+// imageErrorText возвращает локализованное сообщение об ошибке загрузки изображений
+func imageErrorText(err error) string {
+	switch {
+	case errors.Is(err, errTooManyImages):
+		return localization.GetText("ai_image_too_many", maxAIImages)
+	case errors.Is(err, errImageType):
+		return localization.GetText("ai_image_unsupported_type")
+	case errors.Is(err, errImageTooLarge):
+		return localization.GetText("ai_image_too_large", maxAIImageSize>>20)
+	default:
+		return localization.GetText("ai_image_download_failed", err.Error())
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestDownloadImages(t *testing.T) {
+	small := []byte("png-data")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/large.png" {
+			// Фактический размер больше указанного в метаданных вложения
+			w.Write(bytes.Repeat([]byte{0}, maxAIImageSize+1))
+			return
+		}
+		w.Write(small)
+	}))
+	defer server.Close()
+
+	attachment := func(name, contentType string, size int) *discordgo.MessageAttachment {
+		return &discordgo.MessageAttachment{Filename: name, URL: server.URL + "/" + name, ContentType: contentType, Size: size}
+	}
+
+	images, err := downloadImages(context.Background(), []*discordgo.MessageAttachment{attachment("cat.png", "image/png", len(small))})
+	if err != nil || len(images) != 1 || images[0].MIMEType != "image/png" || !bytes.Equal(images[0].Data, small) {
+		t.Fatalf("Изображение не загружено: %+v, %v", images, err)
+	}
+
+	cases := []struct {
+		attachment *discordgo.MessageAttachment
+		want       error
+	}{
+		{attachment("cat.svg", "image/svg+xml", 10), errImageType},
+		{attachment("cat.png", "image/png", maxAIImageSize+1), errImageTooLarge},
+		{attachment("large.png", "image/png", 10), errImageTooLarge},
+	}
+	for _, c := range cases {
+		if _, err := downloadImages(context.Background(), []*discordgo.MessageAttachment{c.attachment}); !errors.Is(err, c.want) {
+			t.Errorf("%s: ожидалась ошибка %v, получено: %v", c.attachment.Filename, c.want, err)
+		}
+	}
+
+	tooMany := make([]*discordgo.MessageAttachment, maxAIImages+1)
+	if _, err := downloadImages(context.Background(), tooMany); !errors.Is(err, errTooManyImages) {
+		t.Errorf("Ожидалась ошибка errTooManyImages, получено: %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"discord-bot/db"
+	"discord-bot/localization"
+	"discord-bot/ratelimit"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Ограничения запросов к AI по умолчанию
+const (
+	defaultAIUserPerMinute   = 5
+	defaultAIGuildPerMinute  = 30
+	defaultAIGlobalPerMinute = 60
+	defaultAIDailyQuota      = 100
+)
+
+// aiLimiter ограничивает частоту запросов к AI
+var aiLimiter = ratelimit.New()
+
+// aiQuotaMu делает проверку и учет дневной квоты атомарными, чтобы одновременные
+// запросы пользователя не превысили квоту
+var aiQuotaMu sync.Mutex
+
+// limitValue возвращает значение ограничения: 0 - значение по умолчанию, отрицательное - без ограничения
+func limitValue(value, defaultValue int) int {
+	switch {
+	case value < 0:
+		return 0
+	case value == 0:
+		return defaultValue
+	default:
+		return value
+	}
+}
+
+// aiLimitsExempt проверяет, освобожден ли пользователь с указанными ролями от ограничений
+func aiLimitsExempt(roles []string) bool {
+	for _, roleID := range roles {
+		if cfg.AdminRoleID != "" && roleID == cfg.AdminRoleID {
+			return true
+		}
+		for _, exempt := range cfg.AIRateLimit.ExemptRoles {
+			if roleID == exempt {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// quotaDay возвращает ключ суток для дневной квоты (по UTC)
+func quotaDay(now time.Time) string {
+	return now.UTC().Format("2006-01-02")
+}
+
+// nextQuotaReset возвращает время обновления дневной квоты
+func nextQuotaReset(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// dailyQuotaLimit возвращает дневной лимит пользователя (0 - без ограничения)
+func dailyQuotaLimit(quota *db.AIQuota) int {
+	if quota.DailyLimit != nil {
+		if *quota.DailyLimit < 0 {
+			return 0
+		}
+		return *quota.DailyLimit
+	}
+	return limitValue(cfg.AIRateLimit.DailyQuota, defaultAIDailyQuota)
+}
+
+// checkAILimits проверяет дневную квоту и частоту запросов пользователя.
+// Возвращает пустую строку, если запрос разрешен, иначе локализованный текст отказа.
+func checkAILimits(userID, guildID string, roles []string) string {
+	if cfg == nil || aiLimitsExempt(roles) {
+		return ""
+	}
+
+	aiQuotaMu.Lock()
+	defer aiQuotaMu.Unlock()
+
+	now := time.Now()
+	day := quotaDay(now)
+
+	// При недоступности базы данных квота не проверяется, остаются только ограничения частоты
+	quota, err := db.GetAIQuota(userID, day)
+	if err != nil {
+		fmt.Printf("Ошибка получения квоты AI: %v\n", err)
+	} else if limit := dailyQuotaLimit(quota); limit > 0 && quota.Used >= limit {
+		return localization.GetText("ai_quota_exceeded", quota.Used, limit, nextQuotaReset(now).Unix())
+	}
+
+	rules := []ratelimit.Rule{
+		{Key: "user:" + userID, Capacity: limitValue(cfg.AIRateLimit.UserPerMinute, defaultAIUserPerMinute), Per: time.Minute},
+		{Key: "global", Capacity: limitValue(cfg.AIRateLimit.GlobalPerMinute, defaultAIGlobalPerMinute), Per: time.Minute},
+	}
+	if guildID != "" {
+		rules = append(rules, ratelimit.Rule{
+			Key:      "guild:" + guildID,
+			Capacity: limitValue(cfg.AIRateLimit.GuildPerMinute, defaultAIGuildPerMinute),
+			Per:      time.Minute,
+		})
+	}
+
+	if ok, wait := aiLimiter.Allow(rules...); !ok {
+		retryAt := now.Add(wait).Add(time.Second)
+		return localization.GetText("ai_rate_limited", retryAt.Unix())
+	}
+
+	if err == nil {
+		if err := db.IncrementAIQuota(userID, day); err != nil {
+			fmt.Printf("Ошибка учета квоты AI: %v\n", err)
+		}
+	}
+	return ""
+}
+
+// HandleAIQuotaCommand позволяет модераторам просматривать и изменять квоты AI пользователей.
+// Формат: aiquota @пользователь [reset | limit <число|default|unlimited>]
+func HandleAIQuotaCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	send := func(text string) {
+		if _, err := s.ChannelMessageSend(m.ChannelID, text); err != nil {
+			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
+		}
+	}
+
+	if len(args) == 0 || extractUserID(args[0]) == "" {
+		send(localization.GetText("ai_quota_usage", cfg.Prefix))
+		return
+	}
+
+	userID := extractUserID(args[0])
+	day := quotaDay(time.Now())
+
+	if len(args) > 1 {
+		switch strings.ToLower(args[1]) {
+		case "reset":
+			if err := db.ResetAIQuota(userID, day); err != nil {
+				send(localization.GetText("ai_quota_error", err.Error()))
+				return
+			}
+			aiLimiter.Reset("user:" + userID)
+		case "limit":
+			if len(args) < 3 {
+				send(localization.GetText("ai_quota_usage", cfg.Prefix))
+				return
+			}
+			limit, ok := parseQuotaLimit(args[2])
+			if !ok {
+				send(localization.GetText("ai_quota_usage", cfg.Prefix))
+				return
+			}
+			if err := db.SetAIQuotaLimit(userID, limit); err != nil {
+				send(localization.GetText("ai_quota_error", err.Error()))
+				return
+			}
+		default:
+			send(localization.GetText("ai_quota_usage", cfg.Prefix))
+			return
+		}
+	}
+
+	quota, err := db.GetAIQuota(userID, day)
+	if err != nil {
+		send(localization.GetText("ai_quota_error", err.Error()))
+		return
+	}
+
+	limitText := localization.GetText("ai_quota_unlimited")
+	if limit := dailyQuotaLimit(quota); limit > 0 {
+		limitText = strconv.Itoa(limit)
+	}
+	send(localization.GetText("ai_quota_info", userID, quota.Used, limitText, nextQuotaReset(time.Now()).Unix()))
+}
+
+// parseQuotaLimit разбирает значение лимита: число, default (лимит из конфигурации) или unlimited
+func parseQuotaLimit(value string) (*int, bool) {
+	switch strings.ToLower(value) {
+	case "default":
+		return nil, true
+	case "unlimited":
+		unlimited := -1
+		return &unlimited, true
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return nil, false
+	}
+	return &limit, true
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"discord-bot/ai"
+	"discord-bot/db"
+)
+
+// Значения по умолчанию для памяти диалогов
+const (
+	defaultAIMemoryTokens   = 2000
+	defaultAIMemoryMessages = 40
+)
+
+// aiMemoryEnabled проверяет, включена ли история диалогов
+func aiMemoryEnabled() bool {
+	return cfg == nil || !cfg.AIMemory.Disabled
+}
+
+// aiMemoryLimits возвращает бюджет токенов и максимальное количество сообщений истории
+func aiMemoryLimits() (int, int) {
+	tokens, messages := defaultAIMemoryTokens, defaultAIMemoryMessages
+	if cfg != nil {
+		if cfg.AIMemory.MaxTokens > 0 {
+			tokens = cfg.AIMemory.MaxTokens
+		}
+		if cfg.AIMemory.MaxMessages > 0 {
+			messages = cfg.AIMemory.MaxMessages
+		}
+	}
+	return tokens, messages
+}
+
+// buildConversationRequest формирует запрос к модели из истории диалога и нового сообщения.
+// Диалог привязан к каналу; ветки Discord являются отдельными каналами и получают свою историю.
+func buildConversationRequest(sessionID, prompt string) ai.Request {
+	request := ai.Request{}
+	if sessionID != "" && aiMemoryEnabled() {
+		request.Messages = loadConversation(sessionID)
+	}
+	request.Messages = append(request.Messages, ai.Message{Role: ai.RoleUser, Content: prompt})
+	return request
+}
+
+// loadConversation загружает историю диалога, укладывающуюся в бюджет токенов
+func loadConversation(sessionID string) []ai.Message {
+	budget, limit := aiMemoryLimits()
+
+	history, err := db.GetConversationMessages(sessionID, limit)
+	if err != nil {
+		fmt.Printf("Ошибка загрузки истории диалога: %v\n", err)
+		return nil
+	}
+
+	messages := make([]ai.Message, 0, len(history))
+	for _, msg := range history {
+		messages = append(messages, ai.Message{Role: msg.Role, Content: msg.Content})
+	}
+	return trimToTokenBudget(messages, budget)
+}
+
+// saveConversationTurn сохраняет вопрос пользователя и ответ модели в историю диалога.
+// Длинный ответ занимает несколько сообщений Discord, и с диалогом связывается каждое из них
+func saveConversationTurn(sessionID, prompt, reply string, replyMessageIDs []string) {
+	if sessionID == "" || !aiMemoryEnabled() {
+		return
+	}
+
+	var lastMessageID string
+	if len(replyMessageIDs) > 0 {
+		lastMessageID = replyMessageIDs[len(replyMessageIDs)-1]
+	}
+	turn := []db.ConversationMessage{
+		{SessionID: sessionID, Role: ai.RoleUser, Content: prompt},
+		{SessionID: sessionID, Role: ai.RoleAssistant, Content: reply, MessageID: lastMessageID},
+	}
+	for _, msg := range turn {
+		if err := db.AddConversationMessage(msg); err != nil {
+			fmt.Printf("Ошибка сохранения истории диалога: %v\n", err)
+			return
+		}
+	}
+	if err := db.AddConversationReplies(sessionID, replyMessageIDs); err != nil {
+		fmt.Printf("Ошибка сохранения сообщений ответа: %v\n", err)
+	}
+}
+
+// trimToTokenBudget оставляет самые новые сообщения, суммарный размер которых не превышает бюджет.
+// История всегда начинается с сообщения пользователя, как того требуют API Gemini и Claude.
+func trimToTokenBudget(messages []ai.Message, budget int) []ai.Message {
+	total := 0
+	start := len(messages)
+	for start > 0 {
+		tokens := estimateTokens(messages[start-1].Content)
+		if total+tokens > budget {
+			break
+		}
+		total += tokens
+		start--
+	}
+
+	for start < len(messages) && messages[start].Role != ai.RoleUser {
+		start++
+	}
+	return messages[start:]
+}
+
+// estimateTokens приблизительно оценивает количество токенов в тексте (около 4 символов на токен)
+func estimateTokens(text string) int {
+	return utf8.RuneCountInString(text)/4 + 1
+}
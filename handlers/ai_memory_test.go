@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"discord-bot/ai"
+)
+
+func TestTrimToTokenBudgetKeepsNewest(t *testing.T) {
+	long := strings.Repeat("слово ", 100)
+	messages := []ai.Message{
+		{Role: ai.RoleUser, Content: long},
+		{Role: ai.RoleAssistant, Content: long},
+		{Role: ai.RoleUser, Content: "Как дела?"},
+		{Role: ai.RoleAssistant, Content: "Хорошо"},
+	}
+
+	trimmed := trimToTokenBudget(messages, 200)
+	if len(trimmed) != 2 || trimmed[0].Content != "Как дела?" {
+		t.Errorf("Ожидались два последних сообщения, получено: %+v", trimmed)
+	}
+}
+
+func TestTrimToTokenBudgetStartsWithUser(t *testing.T) {
+	messages := []ai.Message{
+		{Role: ai.RoleUser, Content: strings.Repeat("а", 400)},
+		{Role: ai.RoleAssistant, Content: "Ответ"},
+	}
+
+	if trimmed := trimToTokenBudget(messages, 50); len(trimmed) != 0 {
+		t.Errorf("История не должна начинаться с ответа модели: %+v", trimmed)
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"discord-bot/ai"
+	"discord-bot/localization"
+	"discord-bot/moderation"
+	"discord-bot/ratelimit"
+	"discord-bot/reports"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Параметры проверки сообщений по умолчанию
+const (
+	defaultModerationMinLength      = 5
+	defaultModerationReportCooldown = 10 * time.Minute
+	// moderationTimeout ограничивает время ожидания ответа классификатора
+	moderationTimeout = 30 * time.Second
+	// maxModerationQueue - максимум сообщений, одновременно проверяемых классификатором.
+	// При большем потоке сообщения пропускаются, чтобы не копить запросы к AI
+	maxModerationQueue = 4
+	// maxModerationQuote - максимум символов сообщения и объяснения в тексте репорта
+	maxModerationQuote = 300
+	// moderationRequester - пользователь, на которого записываются обращения классификатора
+	// в учете использования AI, чтобы они не приписывались авторам проверенных сообщений
+	moderationRequester = "moderation"
+)
+
+var (
+	// moderationSlots ограничивает количество одновременных проверок
+	moderationSlots = make(chan struct{}, maxModerationQueue)
+	// moderationLimiter не дает создавать репорты на одного пользователя слишком часто
+	moderationLimiter = ratelimit.New()
+)
+
+// shouldModerate проверяет, нужно ли отправлять сообщение классификатору
+func shouldModerate(m *discordgo.MessageCreate) bool {
+	settings := cfg.AIModeration
+	if !settings.Enabled || m.GuildID == "" || m.Author.Bot {
+		return false
+	}
+
+	minLength := settings.MinLength
+	if minLength <= 0 {
+		minLength = defaultModerationMinLength
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(m.Content)) < minLength {
+		return false
+	}
+
+	if hasModeratorRole(memberRoles(m.Member)) {
+		return false
+	}
+
+	for _, channelID := range settings.Channels {
+		if channelID == m.ChannelID {
+			return true
+		}
+	}
+	return false
+}
+
+// ModerateMessage отправляет сообщение AI классификатору в фоне. При превышении порога
+// создается репорт для модераторов; решение о наказании всегда принимают модераторы
+func ModerateMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if !shouldModerate(m) {
+		return
+	}
+
+	select {
+	case moderationSlots <- struct{}{}:
+	default:
+		fmt.Printf("Проверка сообщения %s пропущена: очередь модерации заполнена\n", m.ID)
+		return
+	}
+
+	go func() {
+		defer func() { <-moderationSlots }()
+		moderateMessage(s, m)
+	}()
+}
+
+// moderateMessage проверяет сообщение и создает репорт при нарушении
+func moderateMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
+	settings := cfg.AIModeration
+	classifier := moderation.NewClassifier(settings.Provider, settings.Thresholds, localization.GetCurrentLanguage())
+
+	ctx, cancel := context.WithTimeout(ai.WithRequester(context.Background(), moderationRequester, m.GuildID), moderationTimeout)
+	defer cancel()
+
+	result, err := classifier.Classify(ctx, m.Content)
+	if err != nil {
+		fmt.Printf("Ошибка проверки сообщения %s: %v\n", m.ID, err)
+		return
+	}
+
+	violations := classifier.Violations(result)
+	if len(violations) == 0 {
+		return
+	}
+
+	cooldown := defaultModerationReportCooldown
+	if settings.ReportCooldown > 0 {
+		cooldown = time.Duration(settings.ReportCooldown) * time.Minute
+	}
+	if ok, _ := moderationLimiter.Allow(ratelimit.Rule{Key: "report:" + m.Author.ID, Capacity: 1, Per: cooldown}); !ok {
+		return
+	}
+
+	channelID := settings.ReportChannelID
+	if channelID == "" {
+		channelID = reportQueueChannel(m.GuildID, m.ChannelID)
+	}
+
+	reason := moderationReportReason(m, violations, result.Explanation)
+	if _, err := reports.CreateReport(s, m.GuildID, channelID, m.Author.ID, s.State.User.ID, reason); err != nil {
+		fmt.Printf("Ошибка создания репорта по итогам проверки сообщения %s: %v\n", m.ID, err)
+	}
+}
+
+// moderationReportReason формирует причину репорта: категории с оценками, объяснение и ссылку на сообщение
+func moderationReportReason(m *discordgo.MessageCreate, violations []moderation.Violation, explanation string) string {
+	categories := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		categories = append(categories, fmt.Sprintf("%s %.2f", violation.Category, violation.Score))
+	}
+
+	link := fmt.Sprintf("https://discord.com/channels/%s/%s/%s", m.GuildID, m.ChannelID, m.ID)
+	return localization.GetText("ai_moderation_report_reason",
+		strings.Join(categories, ", "),
+		truncateRunes(explanation, maxModerationQuote),
+		truncateRunes(m.Content, maxModerationQuote),
+		link)
+}
+
+// truncateRunes обрезает строку до limit символов
+func truncateRunes(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"discord-bot/ai"
+	"discord-bot/db"
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxAutocompleteChoices - максимальное число вариантов автодополнения в Discord
+const maxAutocompleteChoices = 25
+
+// personaOption - название параметра /ai ask для выбора персоны
+const personaOption = "persona"
+
+// resolvePersona выбирает персону AI для запроса. Явно указанная персона должна существовать
+// и быть доступна в канале, иначе возвращается текст отказа. Без указания имени используется
+// персона сервера по умолчанию, если она доступна в канале.
+func resolvePersona(guildID, channelID, name string) (*db.AIPersona, string) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if name == "" {
+		return defaultPersona(guildID, channelID), ""
+	}
+	if guildID == "" {
+		return nil, localization.GetText("ai_persona_not_found", name)
+	}
+
+	persona, err := db.GetAIPersona(guildID, name)
+	if err != nil {
+		fmt.Printf("Ошибка загрузки персоны AI: %v\n", err)
+		return nil, localization.GetText("ai_error", err.Error())
+	}
+	if persona == nil {
+		return nil, localization.GetText("ai_persona_not_found", name)
+	}
+	if !personaAllowed(persona, channelID) {
+		return nil, localization.GetText("ai_persona_channel_denied", name)
+	}
+
+	return persona, ""
+}
+
+// defaultPersona возвращает персону сервера по умолчанию, если она доступна в канале
+func defaultPersona(guildID, channelID string) *db.AIPersona {
+	if guildID == "" {
+		return nil
+	}
+
+	personas, err := db.GetAIPersonas(guildID)
+	if err != nil {
+		// Без персоны бот продолжает работать с настройками провайдера
+		fmt.Printf("Ошибка загрузки персон AI: %v\n", err)
+		return nil
+	}
+
+	for i := range personas {
+		if personas[i].IsDefault && personaAllowed(&personas[i], channelID) {
+			return &personas[i]
+		}
+	}
+	return nil
+}
+
+// personaAllowed проверяет, доступна ли персона в канале
+func personaAllowed(persona *db.AIPersona, channelID string) bool {
+	if len(persona.AllowedChannels) == 0 {
+		return true
+	}
+	for _, id := range persona.AllowedChannels {
+		if id == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPersona добавляет в запрос системный промпт и температуру персоны
+func applyPersona(request *ai.Request, persona *db.AIPersona) {
+	if persona == nil {
+		return
+	}
+	request.System = persona.SystemPrompt
+	if persona.Temperature != nil {
+		temperature := *persona.Temperature
+		request.Temperature = &temperature
+	}
+}
+
+// handleAIPersonaAutocomplete предлагает персоны сервера, доступные в канале, при вводе параметра /ai ask
+func handleAIPersonaAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var typed string
+	for _, subcommand := range i.ApplicationCommandData().Options {
+		for _, option := range subcommand.Options {
+			if option.Focused && option.Name == personaOption {
+				typed = strings.ToLower(option.StringValue())
+			}
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	if i.GuildID != "" {
+		personas, err := db.GetAIPersonas(i.GuildID)
+		if err != nil {
+			fmt.Printf("Ошибка загрузки персон AI: %v\n", err)
+		}
+
+		for n := range personas {
+			persona := &personas[n]
+			if !strings.HasPrefix(persona.Name, typed) || !personaAllowed(persona, i.ChannelID) {
+				continue
+			}
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: persona.Name, Value: persona.Name})
+			if len(choices) == maxAutocompleteChoices {
+				break
+			}
+		}
+	}
+
+	respondAutocomplete(s, i, choices)
+}
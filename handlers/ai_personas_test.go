@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"testing"
+
+	"discord-bot/ai"
+	"discord-bot/db"
+)
+
+func TestPersonaAllowed(t *testing.T) {
+	open := &db.AIPersona{Name: "open"}
+	limited := &db.AIPersona{Name: "limited", AllowedChannels: []string{"1", "2"}}
+
+	if !personaAllowed(open, "3") {
+		t.Error("Персона без списка каналов должна быть доступна везде")
+	}
+	if !personaAllowed(limited, "2") || personaAllowed(limited, "3") {
+		t.Error("Персона должна быть доступна только в разрешенных каналах")
+	}
+}
+
+func TestApplyPersona(t *testing.T) {
+	temperature := 0.2
+	request := ai.Request{}
+	applyPersona(&request, &db.AIPersona{SystemPrompt: "Ты пират", Temperature: &temperature})
+
+	if request.System != "Ты пират" || request.Temperature == nil || *request.Temperature != 0.2 {
+		t.Errorf("Настройки персоны не применены к запросу: %+v", request)
+	}
+
+	// Изменение персоны после применения не должно влиять на запрос
+	temperature = 1
+	if *request.Temperature != 0.2 {
+		t.Error("Запрос не должен ссылаться на температуру персоны")
+	}
+
+	request = ai.Request{}
+	applyPersona(&request, nil)
+	if request.System != "" || request.Temperature != nil {
+		t.Errorf("Без персоны запрос не должен меняться: %+v", request)
+	}
+}
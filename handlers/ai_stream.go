@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"discord-bot/ai"
+	"discord-bot/db"
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// discordMessageLimit - максимальная длина сообщения Discord
+	discordMessageLimit = 2000
+	// aiStreamEditInterval - минимальный интервал между редактированиями одного сообщения.
+	// Discord допускает около 5 правок в 5 секунд на канал, поэтому правим не чаще раза в 1.5 секунды.
+	aiStreamEditInterval = 1500 * time.Millisecond
+)
+
+// streamTarget описывает место, куда выводится потоковый ответ AI
+type streamTarget interface {
+	// create отправляет новое сообщение и возвращает его ID
+	create(content string) (string, error)
+	// edit заменяет текст ранее отправленного сообщения и возвращает его ID
+	edit(messageID, content string) (string, error)
+}
+
+// channelStreamTarget выводит ответ обычными сообщениями в текстовый канал
+type channelStreamTarget struct {
+	s         *discordgo.Session
+	channelID string
+}
+
+func (t *channelStreamTarget) create(content string) (string, error) {
+	msg, err := t.s.ChannelMessageSend(t.channelID, content)
+	if err != nil {
+		return "", err
+	}
+	return msg.ID, nil
+}
+
+func (t *channelStreamTarget) edit(messageID, content string) (string, error) {
+	if _, err := t.s.ChannelMessageEdit(t.channelID, messageID, content); err != nil {
+		return "", err
+	}
+	return messageID, nil
+}
+
+// interactionStreamTarget выводит ответ в отложенный ответ на интеракцию и follow-up сообщения
+type interactionStreamTarget struct {
+	s *discordgo.Session
+	i *discordgo.InteractionCreate
+}
+
+// interactionOriginalMessage - идентификатор исходного ответа на интеракцию
+const interactionOriginalMessage = "@original"
+
+func (t *interactionStreamTarget) create(content string) (string, error) {
+	msg, err := t.s.FollowupMessageCreate(t.i.Interaction, true, &discordgo.WebhookParams{
+		Content: content,
+	})
+	if err != nil {
+		return "", err
+	}
+	return msg.ID, nil
+}
+
+func (t *interactionStreamTarget) edit(messageID, content string) (string, error) {
+	edit := &discordgo.WebhookEdit{Content: &content}
+
+	var msg *discordgo.Message
+	var err error
+	if messageID == interactionOriginalMessage {
+		// Возвращаем настоящий ID исходного ответа, чтобы на него можно было ответить
+		msg, err = t.s.InteractionResponseEdit(t.i.Interaction, edit)
+	} else {
+		msg, err = t.s.FollowupMessageEdit(t.i.Interaction, messageID, edit)
+	}
+	if err != nil {
+		return "", err
+	}
+	return msg.ID, nil
+}
+
+// aiStreamWriter постепенно выводит потоковый ответ, редактируя сообщение по мере поступления текста.
+// Правки ограничиваются по частоте, а при превышении лимита Discord текст переносится в новое сообщение.
+type aiStreamWriter struct {
+	target    streamTarget
+	messageID string          // Сообщение, которое сейчас дополняется
+	content   string          // Текст текущего сообщения
+	shown     string          // Текст, уже отображенный в текущем сообщении
+	lastEdit  time.Time       // Время последней правки
+	sentIDs   []string        // ID всех сообщений Discord, в которых выведен ответ
+	written   bool            // Получен ли хотя бы один фрагмент текста
+	text      strings.Builder // Весь полученный текст ответа
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// newAIStreamWriter создает writer, дополняющий уже отправленное сообщение-заглушку
+func newAIStreamWriter(target streamTarget, placeholderID string) *aiStreamWriter {
+	return &aiStreamWriter{
+		target:    target,
+		messageID: placeholderID,
+		interval:  aiStreamEditInterval,
+		now:       time.Now,
+	}
+}
+
+// write добавляет фрагмент текста и при необходимости обновляет сообщение
+func (w *aiStreamWriter) write(text string) error {
+	if text == "" {
+		return nil
+	}
+	w.written = true
+	w.text.WriteString(text)
+	return w.appendContent(text)
+}
+
+// footer добавляет служебную подпись в конец ответа, не включая ее в текст ответа
+func (w *aiStreamWriter) footer(text string) error {
+	return w.appendContent("\n\n" + text)
+}
+
+// appendContent дописывает текст в текущее сообщение, перенося излишек в новые сообщения
+func (w *aiStreamWriter) appendContent(text string) error {
+	w.content += text
+
+	// Заполняем сообщения до лимита и переносим остаток в новые
+	for len(w.content) > discordMessageLimit {
+		cut := splitIndex(w.content, discordMessageLimit)
+		head := strings.TrimRight(w.content[:cut], " \n")
+		rest := strings.TrimLeft(w.content[cut:], " \n")
+
+		if err := w.show(head); err != nil {
+			return err
+		}
+
+		w.content = rest
+		w.shown = ""
+		if rest == "" {
+			w.messageID = ""
+			return nil
+		}
+		id, err := w.target.create(trimToLimit(rest))
+		if err != nil {
+			return fmt.Errorf("ошибка отправки продолжения ответа: %w", err)
+		}
+		w.messageID = id
+		w.remember(id)
+		w.shown = trimToLimit(rest)
+		w.lastEdit = w.now()
+	}
+
+	if w.now().Sub(w.lastEdit) < w.interval {
+		return nil
+	}
+	return w.show(w.content)
+}
+
+// flush выводит весь накопленный текст, не дожидаясь интервала
+func (w *aiStreamWriter) flush() error {
+	if w.content == "" {
+		return nil
+	}
+	return w.show(w.content)
+}
+
+// fail выводит сообщение об ошибке вместо заглушки или после частично полученного ответа
+func (w *aiStreamWriter) fail(text string) error {
+	if !w.written && w.messageID != "" {
+		_, err := w.target.edit(w.messageID, text)
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	_, err := w.target.create(text)
+	return err
+}
+
+// show отображает текст в текущем сообщении, создавая его при необходимости
+func (w *aiStreamWriter) show(content string) error {
+	if content == "" || content == w.shown {
+		return nil
+	}
+
+	if w.messageID == "" {
+		id, err := w.target.create(content)
+		if err != nil {
+			return fmt.Errorf("ошибка отправки ответа: %w", err)
+		}
+		w.messageID = id
+		w.remember(id)
+	} else {
+		id, err := w.target.edit(w.messageID, content)
+		if err != nil {
+			return fmt.Errorf("ошибка обновления ответа: %w", err)
+		}
+		w.remember(id)
+	}
+
+	w.shown = content
+	w.lastEdit = w.now()
+	return nil
+}
+
+// remember запоминает ID сообщения с ответом. Повторные правки одного сообщения не дублируют ID
+func (w *aiStreamWriter) remember(id string) {
+	if !slices.Contains(w.sentIDs, id) {
+		w.sentIDs = append(w.sentIDs, id)
+	}
+}
+
+// trimToLimit обрезает текст до лимита сообщения Discord
+func trimToLimit(text string) string {
+	if len(text) <= discordMessageLimit {
+		return text
+	}
+	return text[:splitIndex(text, discordMessageLimit)]
+}
+
+// splitIndex возвращает позицию, по которой текст длиннее limit лучше всего разбить:
+// последний перевод строки или пробел в пределах лимита, иначе граница символа UTF-8
+func splitIndex(text string, limit int) int {
+	if len(text) <= limit {
+		return len(text)
+	}
+	if idx := strings.LastIndex(text[:limit], "\n"); idx > 0 {
+		return idx
+	}
+	if idx := strings.LastIndex(text[:limit], " "); idx > 0 {
+		return idx
+	}
+
+	idx := limit
+	for idx > 0 && !utf8.RuneStart(text[idx]) {
+		idx--
+	}
+	if idx == 0 {
+		return limit
+	}
+	return idx
+}
+
+// aiPrompt описывает запрос пользователя к AI
+type aiPrompt struct {
+	userID    string                         // Автор запроса
+	guildID   string                         // Сервер Discord (пусто для личных сообщений)
+	modelName string                         // Модель, выбранная пользователем (пусто - цепочка провайдеров по умолчанию)
+	sessionID string                         // Диалог, в который добавляется реплика (пусто - без истории)
+	text      string                         // Текст запроса
+	persona   *db.AIPersona                  // Персона сервера (nil - без системного промпта)
+	images    []*discordgo.MessageAttachment // Изображения, приложенные к запросу
+}
+
+// streamAIResponse запрашивает ответ у модели в потоковом режиме и выводит его через writer.
+// Если указан sessionID, в запрос добавляется история диалога, а новая реплика сохраняется в нее.
+func streamAIResponse(writer *aiStreamWriter, prompt aiPrompt) {
+	ctx, cancel := context.WithTimeout(context.Background(), aiRequestTimeout)
+	defer cancel()
+	ctx = ai.WithRequester(ctx, prompt.userID, prompt.guildID)
+
+	request := buildConversationRequest(prompt.sessionID, prompt.text)
+	applyPersona(&request, prompt.persona)
+
+	if len(prompt.images) > 0 {
+		images, err := downloadImages(ctx, prompt.images)
+		if err != nil {
+			fmt.Printf("Ошибка загрузки изображений: %v\n", err)
+			reportStreamError(writer, imageErrorText(err))
+			return
+		}
+		request.Messages[len(request.Messages)-1].Images = images
+	}
+
+	chunks, err := openAIStream(ctx, prompt, request)
+	if err != nil {
+		reportStreamError(writer, aiErrorText(err))
+		return
+	}
+
+	var final ai.Chunk
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			reportStreamError(writer, aiErrorText(chunk.Err))
+			return
+		}
+		if chunk.Done {
+			final = chunk
+		}
+		if err := writer.write(chunk.Text); err != nil {
+			// Отмена контекста завершит горутину провайдера
+			fmt.Printf("Ошибка вывода ответа AI: %v\n", err)
+			return
+		}
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		reportStreamError(writer, aiErrorText(ctx.Err()))
+		return
+	}
+
+	if !writer.written {
+		reportStreamError(writer, localization.GetText("ai_empty_response"))
+		return
+	}
+	if label := answeredBy(final); label != "" {
+		if err := writer.footer(localization.GetText("ai_answered_by", label)); err != nil {
+			fmt.Printf("Ошибка вывода ответа AI: %v\n", err)
+		}
+	}
+	if err := writer.flush(); err != nil {
+		fmt.Printf("Ошибка вывода ответа AI: %v\n", err)
+	}
+
+	saveConversationTurn(prompt.sessionID, prompt.text, writer.text.String(), writer.sentIDs)
+}
+
+// openAIStream открывает поток ответа выбранной пользователем модели. Без явного выбора
+// запрос проходит по цепочке провайдеров, начиная с предпочтительного провайдера персоны.
+func openAIStream(ctx context.Context, prompt aiPrompt, request ai.Request) (<-chan ai.Chunk, error) {
+	switch {
+	case prompt.modelName != "":
+		return ai.StreamWith(ctx, prompt.modelName, request)
+	case prompt.persona != nil && prompt.persona.Provider != "":
+		return ai.StreamPreferring(ctx, prompt.persona.Provider, request)
+	default:
+		return ai.Stream(ctx, request)
+	}
+}
+
+// answeredBy возвращает название провайдера и модели, сформировавших ответ
+func answeredBy(final ai.Chunk) string {
+	switch {
+	case final.Provider == "":
+		return final.Model
+	case final.Model == "" || final.Model == final.Provider:
+		return final.Provider
+	default:
+		return fmt.Sprintf("%s (%s)", final.Provider, final.Model)
+	}
+}
+
+// reportStreamError выводит ошибку потоковой генерации
+func reportStreamError(writer *aiStreamWriter, text string) {
+	if err := writer.fail(text); err != nil {
+		fmt.Printf("Ошибка отправки сообщения: %v\n", err)
+	}
+}
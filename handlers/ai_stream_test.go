@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeStreamTarget запоминает отправленные и отредактированные сообщения
+type fakeStreamTarget struct {
+	messages map[string]string
+	order    []string
+	edits    int
+}
+
+func newFakeStreamTarget() *fakeStreamTarget {
+	return &fakeStreamTarget{messages: map[string]string{}}
+}
+
+func (t *fakeStreamTarget) create(content string) (string, error) {
+	id := fmt.Sprintf("msg-%d", len(t.order))
+	t.messages[id] = content
+	t.order = append(t.order, id)
+	return id, nil
+}
+
+func (t *fakeStreamTarget) edit(messageID, content string) (string, error) {
+	t.messages[messageID] = content
+	t.edits++
+	return messageID, nil
+}
+
+func TestAIStreamWriterThrottlesEdits(t *testing.T) {
+	target := newFakeStreamTarget()
+	placeholder, _ := target.create("...")
+
+	clock := time.Unix(0, 0)
+	writer := newAIStreamWriter(target, placeholder)
+	writer.now = func() time.Time { return clock }
+
+	for _, part := range []string{"Раз ", "два ", "три"} {
+		if err := writer.write(part); err != nil {
+			t.Fatalf("Неожиданная ошибка: %v", err)
+		}
+	}
+	if target.edits != 1 {
+		t.Errorf("Ожидалась одна правка в пределах интервала, получено: %d", target.edits)
+	}
+
+	if err := writer.flush(); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if target.messages[placeholder] != "Раз два три" {
+		t.Errorf("Неверный текст сообщения: %q", target.messages[placeholder])
+	}
+}
+
+func TestAIStreamWriterRollsOver(t *testing.T) {
+	target := newFakeStreamTarget()
+	placeholder, _ := target.create("...")
+	writer := newAIStreamWriter(target, placeholder)
+
+	word := strings.Repeat("а", 99) + " "
+	for n := 0; n < 50; n++ {
+		if err := writer.write(word); err != nil {
+			t.Fatalf("Неожиданная ошибка: %v", err)
+		}
+	}
+	if err := writer.flush(); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+
+	if len(target.order) < 3 {
+		t.Fatalf("Ожидалось продолжение ответа в новых сообщениях, получено сообщений: %d", len(target.order))
+	}
+	var total int
+	for _, id := range target.order {
+		content := target.messages[id]
+		if len(content) > discordMessageLimit {
+			t.Errorf("Сообщение %s превышает лимит: %d", id, len(content))
+		}
+		total += strings.Count(content, "а")
+	}
+	if total != 50*99 {
+		t.Errorf("Часть текста потеряна: %d из %d символов", total, 50*99)
+	}
+	if !slices.Equal(writer.sentIDs, target.order) {
+		t.Errorf("С диалогом должны связываться все сообщения ответа: %v, ожидалось %v", writer.sentIDs, target.order)
+	}
+}
+
+func TestAIStreamWriterFailReplacesPlaceholder(t *testing.T) {
+	target := newFakeStreamTarget()
+	placeholder, _ := target.create("...")
+	writer := newAIStreamWriter(target, placeholder)
+
+	if err := writer.fail("ошибка"); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if target.messages[placeholder] != "ошибка" || len(target.order) != 1 {
+		t.Errorf("Ошибка должна заменить заглушку: %+v", target.messages)
+	}
+}
+
+func TestSplitMessageKeepsText(t *testing.T) {
+	chunks := splitMessage(strings.Repeat("x", 25), 10)
+	if strings.Join(chunks, "") != strings.Repeat("x", 25) {
+		t.Errorf("Разбиение без пробелов потеряло символы: %q", chunks)
+	}
+}
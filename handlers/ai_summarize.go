@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"discord-bot/ai"
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Параметры сводки канала
+const (
+	defaultSummaryMessages = 100
+	maxSummaryMessages     = 1000
+	// summaryPageSize - максимум сообщений, возвращаемых Discord за один запрос
+	summaryPageSize = 100
+	// summaryChunkSize - размер части переписки в символах, передаваемой модели за один запрос
+	summaryChunkSize = 12000
+	// maxSummaryRounds ограничивает количество этапов объединения частичных сводок
+	maxSummaryRounds = 4
+	// summaryTimeout ограничивает время составления сводки: длинная переписка требует нескольких запросов
+	summaryTimeout = 5 * time.Minute
+	// maxEmbedDescription - максимальная длина описания эмбеда в Discord
+	maxEmbedDescription = 4096
+	// summaryCountOption и summarySinceOption - параметры слеш-команды /summarize
+	summaryCountOption = "count"
+	summarySinceOption = "period"
+)
+
+// minSummaryMessages - минимальное значение параметра количества (Discord принимает его по указателю)
+var minSummaryMessages = 1.0
+
+// Промпты этапов сводки: отдельные части переписки и объединение частичных сводок
+const (
+	summaryMapPrompt = `You summarize part %d of %d of a Discord channel conversation.
+Keep track of who said what: attribute key points, proposals, decisions, questions and disagreements to participants by name.
+Be concise, use short bullet points, do not invent anything that is not in the messages.
+Write in the language with code "%s".`
+	summaryReducePrompt = `You combine partial summaries of consecutive parts of one Discord channel conversation into a single summary.
+Keep attribution of statements to participants, merge repeated points, preserve the chronological order of topics.
+Be concise, use short bullet points. Write in the language with code "%s".`
+)
+
+// summaryRequest описывает, какие сообщения канала включить в сводку
+type summaryRequest struct {
+	count int           // Максимум сообщений
+	since time.Duration // Только сообщения за этот период (0 - без ограничения)
+}
+
+// generateFunc отправляет запрос модели
+type generateFunc func(ctx context.Context, req ai.Request) (ai.Response, error)
+
+// parseSummaryArgs разбирает параметр команды: количество сообщений или период вида 30m, 2h, 1d
+func parseSummaryArgs(args []string) (summaryRequest, bool) {
+	request := summaryRequest{count: defaultSummaryMessages}
+	if len(args) == 0 {
+		return request, true
+	}
+	if len(args) > 1 {
+		return request, false
+	}
+
+	if count, err := strconv.Atoi(args[0]); err == nil {
+		if count < 1 {
+			return request, false
+		}
+		request.count = clampSummaryCount(count)
+		return request, true
+	}
+
+	since, err := parseDuration(args[0])
+	if err != nil {
+		return request, false
+	}
+	request.count = maxSummaryMessages
+	request.since = since
+	return request, true
+}
+
+// clampSummaryCount ограничивает количество сообщений сводки
+func clampSummaryCount(count int) int {
+	if count > maxSummaryMessages {
+		return maxSummaryMessages
+	}
+	return count
+}
+
+// collectMessages загружает сообщения канала от новых к старым, пока не наберется count сообщений
+// или не встретится сообщение старше since. Возвращает сообщения в хронологическом порядке
+func collectMessages(fetch func(limit int, beforeID string) ([]*discordgo.Message, error), beforeID string, count int, since time.Time) ([]*discordgo.Message, error) {
+	var collected []*discordgo.Message
+
+	for len(collected) < count {
+		limit := count - len(collected)
+		if limit > summaryPageSize {
+			limit = summaryPageSize
+		}
+
+		page, err := fetch(limit, beforeID)
+		if err != nil {
+			return nil, err
+		}
+
+		reachedSince := false
+		for _, msg := range page {
+			if !since.IsZero() && msg.Timestamp.Before(since) {
+				reachedSince = true
+				break
+			}
+			collected = append(collected, msg)
+		}
+
+		if reachedSince || len(page) < limit {
+			break
+		}
+		beforeID = page[len(page)-1].ID
+	}
+
+	for left, right := 0, len(collected)-1; left < right; left, right = left+1, right-1 {
+		collected[left], collected[right] = collected[right], collected[left]
+	}
+	return collected, nil
+}
+
+// formatTranscript превращает сообщения в текст переписки с авторами и временем
+func formatTranscript(messages []*discordgo.Message) (string, int) {
+	var transcript strings.Builder
+	participants := make(map[string]bool)
+
+	for _, msg := range messages {
+		if msg.Author == nil {
+			continue
+		}
+
+		content := strings.TrimSpace(msg.Content)
+		for _, attachment := range msg.Attachments {
+			content = strings.TrimSpace(content + " [" + attachment.Filename + "]")
+		}
+		if content == "" {
+			continue
+		}
+
+		participants[msg.Author.ID] = true
+		fmt.Fprintf(&transcript, "[%s] %s: %s\n", msg.Timestamp.UTC().Format("2006-01-02 15:04"), msg.Author.Username, content)
+	}
+
+	return transcript.String(), len(participants)
+}
+
+// summarizeTranscript составляет сводку переписки в стиле map-reduce: длинная переписка делится
+// на части, каждая часть сводится отдельно, затем частичные сводки объединяются
+func summarizeTranscript(ctx context.Context, generate generateFunc, transcript, language string) (string, error) {
+	chunks := splitMessage(transcript, summaryChunkSize)
+
+	summaries := make([]string, 0, len(chunks))
+	for n, chunk := range chunks {
+		summary, err := summarizeChunk(ctx, generate, fmt.Sprintf(summaryMapPrompt, n+1, len(chunks), language), chunk)
+		if err != nil {
+			return "", err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	for round := 0; len(summaries) > 1; round++ {
+		combined := strings.Join(summaries, "\n\n")
+		if round == maxSummaryRounds {
+			// Частичные сводки не сокращаются, возвращаем их как есть
+			return combined, nil
+		}
+
+		parts := splitMessage(combined, summaryChunkSize)
+		summaries = summaries[:0]
+		for _, part := range parts {
+			summary, err := summarizeChunk(ctx, generate, fmt.Sprintf(summaryReducePrompt, language), part)
+			if err != nil {
+				return "", err
+			}
+			summaries = append(summaries, summary)
+		}
+	}
+
+	return summaries[0], nil
+}
+
+// summarizeChunk отправляет модели одну часть переписки или частичных сводок
+func summarizeChunk(ctx context.Context, generate generateFunc, system, text string) (string, error) {
+	temperature := 0.3
+	response, err := generate(ctx, ai.Request{
+		System:      system,
+		Messages:    []ai.Message{{Role: ai.RoleUser, Content: text}},
+		Temperature: &temperature,
+		MaxTokens:   1000,
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(response.Text), nil
+}
+
+// summarizeChannel загружает сообщения канала и составляет эмбед со сводкой.
+// При ошибке возвращается локализованный текст для пользователя
+func summarizeChannel(s *discordgo.Session, userID, guildID, channelID, beforeID string, request summaryRequest) (*discordgo.MessageEmbed, string) {
+	var since time.Time
+	if request.since > 0 {
+		since = time.Now().Add(-request.since)
+	}
+
+	fetch := func(limit int, before string) ([]*discordgo.Message, error) {
+		return s.ChannelMessages(channelID, limit, before, "", "")
+	}
+	messages, err := collectMessages(fetch, beforeID, request.count, since)
+	if err != nil {
+		fmt.Printf("Ошибка загрузки сообщений канала: %v\n", err)
+		return nil, localization.GetText("summary_fetch_error", err.Error())
+	}
+
+	transcript, participants := formatTranscript(messages)
+	if transcript == "" {
+		return nil, localization.GetText("summary_no_messages")
+	}
+
+	ctx, cancel := context.WithTimeout(ai.WithRequester(context.Background(), userID, guildID), summaryTimeout)
+	defer cancel()
+
+	summary, err := summarizeTranscript(ctx, ai.Generate, transcript, localization.GetCurrentLanguage())
+	if err != nil {
+		fmt.Printf("Ошибка составления сводки: %v\n", err)
+		return nil, aiErrorText(err)
+	}
+
+	description, truncated := summaryDescription(summary)
+
+	first, last := messages[0].Timestamp, messages[len(messages)-1].Timestamp
+	embed := &discordgo.MessageEmbed{
+		Title:       localization.GetText("summary_title", len(messages)),
+		Description: description,
+		Color:       0x00BFFF,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   localization.GetText("summary_participants"),
+				Value:  strconv.Itoa(participants),
+				Inline: true,
+			},
+			{
+				Name:   localization.GetText("summary_period"),
+				Value:  fmt.Sprintf("<t:%d:f> - <t:%d:f>", first.Unix(), last.Unix()),
+				Inline: true,
+			},
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	if truncated {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: localization.GetText("summary_truncated")}
+	}
+	return embed, ""
+}
+
+// summaryDescription обрезает сводку до длины описания эмбеда в символах и сообщает, была ли она обрезана
+func summaryDescription(summary string) (string, bool) {
+	if utf8.RuneCountInString(summary) <= maxEmbedDescription {
+		return summary, false
+	}
+	return truncateRunes(summary, maxEmbedDescription), true
+}
+
+// HandleSummarizeCommand обрабатывает текстовую команду summarize
+func HandleSummarizeCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	request, ok := parseSummaryArgs(args)
+	if !ok {
+		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("summary_usage", cfg.Prefix, maxSummaryMessages)); err != nil {
+			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
+		}
+		return
+	}
+
+	if denial := checkAILimits(m.Author.ID, m.GuildID, memberRoles(m.Member)); denial != "" {
+		if _, err := s.ChannelMessageSend(m.ChannelID, denial); err != nil {
+			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
+		}
+		return
+	}
+
+	if err := s.ChannelTyping(m.ChannelID); err != nil {
+		fmt.Printf("Ошибка отправки статуса набора: %v\n", err)
+	}
+
+	// Сообщение с командой не входит в сводку
+	embed, failure := summarizeChannel(s, m.Author.ID, m.GuildID, m.ChannelID, m.ID, request)
+	if failure != "" {
+		if _, err := s.ChannelMessageSend(m.ChannelID, failure); err != nil {
+			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
+		}
+		return
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed); err != nil {
+		fmt.Printf("Ошибка отправки сводки: %v\n", err)
+	}
+}
+
+// handleSummarizeInteraction обрабатывает слеш-команду /summarize
+func handleSummarizeInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	request := summaryRequest{count: defaultSummaryMessages}
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case summaryCountOption:
+			request.count = clampSummaryCount(int(option.IntValue()))
+		case summarySinceOption:
+			since, err := parseDuration(option.StringValue())
+			if err != nil {
+				respondEphemeral(s, i, localization.GetText("summary_invalid_period"))
+				return
+			}
+			request.since = since
+		}
+	}
+
+	// Период без количества включает все сообщения за период в пределах общего ограничения
+	if request.since > 0 && !hasOption(i.ApplicationCommandData().Options, summaryCountOption) {
+		request.count = maxSummaryMessages
+	}
+
+	if !allowAIInteraction(s, i) {
+		return
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		fmt.Printf("Ошибка отправки ответа на взаимодействие: %v\n", err)
+		return
+	}
+
+	edit := &discordgo.WebhookEdit{}
+	embed, failure := summarizeChannel(s, interactionUserID(i), i.GuildID, i.ChannelID, "", request)
+	if failure != "" {
+		edit.Content = &failure
+	} else {
+		edit.Embeds = &[]*discordgo.MessageEmbed{embed}
+	}
+
+	if _, err := s.InteractionResponseEdit(i.Interaction, edit); err != nil {
+		fmt.Printf("Ошибка отправки сводки: %v\n", err)
+	}
+}
+
+// hasOption проверяет, передан ли параметр слеш-команды
+func hasOption(options []*discordgo.ApplicationCommandInteractionDataOption, name string) bool {
+	for _, option := range options {
+		if option.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"discord-bot/ai"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestParseSummaryArgs(t *testing.T) {
+	tests := []struct {
+		args  []string
+		want  summaryRequest
+		valid bool
+	}{
+		{nil, summaryRequest{count: defaultSummaryMessages}, true},
+		{[]string{"50"}, summaryRequest{count: 50}, true},
+		{[]string{"5000"}, summaryRequest{count: maxSummaryMessages}, true},
+		{[]string{"2h"}, summaryRequest{count: maxSummaryMessages, since: 2 * time.Hour}, true},
+		{[]string{"1d"}, summaryRequest{count: maxSummaryMessages, since: 24 * time.Hour}, true},
+		{[]string{"0"}, summaryRequest{}, false},
+		{[]string{"вчера"}, summaryRequest{}, false},
+		{[]string{"10", "2h"}, summaryRequest{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseSummaryArgs(tt.args)
+		if ok != tt.valid || (ok && got != tt.want) {
+			t.Errorf("parseSummaryArgs(%v) = %+v, %v; ожидалось %+v, %v", tt.args, got, ok, tt.want, tt.valid)
+		}
+	}
+}
+
+func TestCollectMessagesStopsAtSince(t *testing.T) {
+	now := time.Now()
+	// Сообщения от новых к старым, как их возвращает Discord
+	var history []*discordgo.Message
+	for n := 0; n < 250; n++ {
+		history = append(history, &discordgo.Message{
+			ID:        fmt.Sprint(1000 - n),
+			Timestamp: now.Add(-time.Duration(n) * time.Minute),
+		})
+	}
+
+	fetch := func(limit int, beforeID string) ([]*discordgo.Message, error) {
+		start := 0
+		for beforeID != "" && history[start].ID != beforeID {
+			start++
+		}
+		if beforeID != "" {
+			start++
+		}
+		end := start + limit
+		if end > len(history) {
+			end = len(history)
+		}
+		return history[start:end], nil
+	}
+
+	messages, err := collectMessages(fetch, "", maxSummaryMessages, now.Add(-150*time.Minute+time.Second))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if len(messages) != 150 {
+		t.Fatalf("Ожидалось 150 сообщений за период, получено %d", len(messages))
+	}
+	if messages[0].ID != "851" || messages[149].ID != "1000" {
+		t.Errorf("Сообщения должны идти в хронологическом порядке: %s ... %s", messages[0].ID, messages[149].ID)
+	}
+
+	messages, err = collectMessages(fetch, "", 120, time.Time{})
+	if err != nil || len(messages) != 120 {
+		t.Errorf("Ожидалось 120 последних сообщений, получено %d (%v)", len(messages), err)
+	}
+}
+
+func TestSummarizeTranscriptMapReduce(t *testing.T) {
+	var mapCalls, reduceCalls int
+	generate := func(ctx context.Context, req ai.Request) (ai.Response, error) {
+		if strings.Contains(req.System, "combine") {
+			reduceCalls++
+			return ai.Response{Text: "итог"}, nil
+		}
+		mapCalls++
+		return ai.Response{Text: fmt.Sprintf("часть %d", mapCalls)}, nil
+	}
+
+	line := "[2024-01-01 10:00] alice: " + strings.Repeat("слово ", 20) + "\n"
+	transcript := strings.Repeat(line, 3*summaryChunkSize/len(line))
+
+	summary, err := summarizeTranscript(context.Background(), generate, transcript, "ru")
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if summary != "итог" || mapCalls < 3 || reduceCalls != 1 {
+		t.Errorf("Ожидалась сводка частей и одно объединение: %q, частей %d, объединений %d", summary, mapCalls, reduceCalls)
+	}
+
+	mapCalls, reduceCalls = 0, 0
+	if summary, _ := summarizeTranscript(context.Background(), generate, line, "ru"); summary != "часть 1" || reduceCalls != 0 {
+		t.Errorf("Короткая переписка не должна объединяться: %q, объединений %d", summary, reduceCalls)
+	}
+}
+
+func TestSummaryDescriptionCountsRunes(t *testing.T) {
+	// Русская сводка в 3000 символов занимает больше 4096 байт, но помещается в эмбед целиком
+	summary := strings.Repeat("я", 3000)
+	if description, truncated := summaryDescription(summary); truncated || description != summary {
+		t.Error("Сводка в 3000 символов не должна обрезаться")
+	}
+
+	description, truncated := summaryDescription(strings.Repeat("я", maxEmbedDescription+10))
+	if !truncated {
+		t.Error("Длинная сводка должна быть отмечена как обрезанная")
+	}
+	if got := utf8.RuneCountInString(description); got != maxEmbedDescription {
+		t.Errorf("Ожидалось %d символов, получено %d", maxEmbedDescription, got)
+	}
+}
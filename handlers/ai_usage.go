@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"fmt"
+
+	"discord-bot/ai"
+	"discord-bot/db"
+)
+
+// RecordAIUsage сохраняет сведения об обращении к провайдеру AI в базу данных
+func RecordAIUsage(record ai.UsageRecord) {
+	usage := db.AIUsageRecord{
+		UserID:           record.UserID,
+		GuildID:          record.GuildID,
+		Provider:         record.Provider,
+		Model:            record.Model,
+		LatencyMs:        record.Latency.Milliseconds(),
+		PromptTokens:     record.Usage.PromptTokens,
+		CompletionTokens: record.Usage.CompletionTokens,
+		Success:          record.Err == nil,
+		Timestamp:        record.Time,
+	}
+	if record.Err != nil {
+		usage.Error = record.Err.Error()
+	}
+
+	if err := db.RecordAIUsage(usage); err != nil {
+		fmt.Printf("Ошибка записи статистики использования AI: %v\n", err)
+	}
+}
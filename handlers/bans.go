@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"discord-bot/db"
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	banListPage       = 10          // Банов на странице списка
+	banInfoHistory    = 5           // Последних банов в подробностях о пользователе
+	maxBanListReason  = 80          // Максимальная длина причины в списке банов
+	banSchedulerEvery = time.Minute // Интервал проверки истекших банов
+)
+
+// handleUnbanCommand обрабатывает команду снятия бана (unban @пользователь [причина])
+func handleUnbanCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
+	if len(args) < 1 {
+		reply.Reply(localization.GetText("unban_usage", cfg.Prefix))
+		return
+	}
+
+	unbanUser(s, reply, m.GuildID, m.Author.ID, extractUserID(args[0]), strings.Join(args[1:], " "))
+}
+
+// handleUnbanInteraction обрабатывает слеш-команду /unban
+func handleUnbanInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := interactionOptions(i)
+	reply := &interactionResponder{s: s, i: i}
+
+	unbanUser(s, reply, i.GuildID, interactionUserID(i), idOption(options, userOption), stringOption(options, reasonOption))
+}
+
+// unbanUser снимает действующие баны пользователя на сервере в Discord и в базе данных
+// и записывает снятие модерационным случаем
+func unbanUser(s *discordgo.Session, reply responder, guildID, moderatorID, userID, reason string) {
+	bans, err := activeGuildBans(guildID, userID)
+	if err != nil {
+		reply.Private(localization.GetText("unban_error", err.Error()))
+		return
+	}
+	if len(bans) == 0 {
+		reply.Private(localization.GetText("unban_not_banned", userID))
+		return
+	}
+
+	// Бан, который Discord отказался снять, остается действующим и в базе данных
+	var lifted []db.Ban
+	for _, ban := range bans {
+		if err := liftDiscordBan(s, ban, reason); err != nil && !isAlreadyLifted(err) {
+			fmt.Printf("Не удалось снять бан #%d в Discord: %v\n", ban.ID, err)
+			reply.Private(localization.GetText("unban_discord_failed", err.Error()))
+			continue
+		}
+		if err := db.LiftBan(ban.ID, moderatorID); err != nil {
+			reply.Private(localization.GetText("unban_error", err.Error()))
+			return
+		}
+		lifted = append(lifted, ban)
+	}
+	if len(lifted) == 0 {
+		return
+	}
+
+	c, err := recordCase(s, db.ModerationCase{
+		GuildID:     guildID,
+		UserID:      userID,
+		ModeratorID: moderatorID,
+		Action:      db.CaseUnban,
+		Reason:      reason,
+		BanID:       lifted[0].ID,
+	})
+	if err != nil {
+		// Бан уже снят, но случай не сохранен - номер случая в ответе не указывается
+		fmt.Printf("Ошибка сохранения случая снятия бана пользователя %s: %v\n", userID, err)
+		reply.Reply(localization.GetText("unban_success", userID))
+		return
+	}
+	reply.Reply(localization.GetText("unban_success", userID) + caseSuffix(c))
+}
+
+// activeGuildBans возвращает действующие баны пользователя на сервере, включая баны без сервера
+func activeGuildBans(guildID, userID string) ([]db.Ban, error) {
+	bans, err := db.GetBansByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []db.Ban
+	for _, ban := range bans {
+		if banActive(ban) && banInGuild(ban, guildID) {
+			active = append(active, ban)
+		}
+	}
+	return active, nil
+}
+
+// liftDiscordBan снимает бан или тайм-аут в Discord. Баны, применяемые удалением сообщений,
+// снимаются только в базе данных
+func liftDiscordBan(s *discordgo.Session, ban db.Ban, reason string) error {
+	if ban.GuildID == "" {
+		return nil
+	}
+
+	var options []discordgo.RequestOption
+	if reason != "" {
+		options = append(options, discordgo.WithAuditLogReason(truncateRunes(reason, maxAuditLogReason)))
+	}
+
+	switch ban.Enforcement {
+	case db.BanEnforcementGuildBan:
+		if err := s.GuildBanDelete(ban.GuildID, ban.UserID, options...); err != nil {
+			return fmt.Errorf("не удалось снять бан на сервере: %w", err)
+		}
+	case db.BanEnforcementTimeout:
+		if err := s.GuildMemberTimeout(ban.GuildID, ban.UserID, nil, options...); err != nil {
+			return fmt.Errorf("не удалось снять тайм-аут: %w", err)
+		}
+	}
+	return nil
+}
+
+// isAlreadyLifted проверяет, что Discord не снял бан, потому что пользователь уже не забанен
+// или покинул сервер
+func isAlreadyLifted(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Message == nil {
+		return false
+	}
+	return restErr.Message.Code == discordgo.ErrCodeUnknownBan || restErr.Message.Code == discordgo.ErrCodeUnknownMember
+}
+
+// handleBansCommand обрабатывает команду списка действующих банов (bans [страница])
+func handleBansCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
+
+	page := 1
+	if len(args) > 0 {
+		value, err := strconv.Atoi(args[0])
+		if err != nil || value < 1 {
+			reply.Reply(localization.GetText("bans_usage", cfg.Prefix))
+			return
+		}
+		page = value
+	}
+
+	listBans(reply, m.GuildID, page)
+}
+
+// handleBansInteraction обрабатывает слеш-команду /bans
+func handleBansInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	page := int(intOption(interactionOptions(i), pageOption))
+	if page < 1 {
+		page = 1
+	}
+	listBans(&interactionResponder{s: s, i: i}, i.GuildID, page)
+}
+
+// listBans отправляет модератору страницу действующих банов сервера
+func listBans(reply responder, guildID string, page int) {
+	bans, err := db.GetActiveBans()
+	if err != nil {
+		fmt.Printf("Ошибка получения списка банов: %v\n", err)
+		reply.Private(localization.GetText("bans_error", err.Error()))
+		return
+	}
+
+	var guildBans []db.Ban
+	for _, ban := range bans {
+		if banInGuild(ban, guildID) {
+			guildBans = append(guildBans, ban)
+		}
+	}
+	if len(guildBans) == 0 {
+		reply.Private(localization.GetText("bans_empty"))
+		return
+	}
+
+	reply.Private(formatBanList(guildBans, page))
+}
+
+// formatBanList формирует страницу списка банов. Номер страницы ограничивается последней страницей
+func formatBanList(bans []db.Ban, page int) string {
+	pages := (len(bans) + banListPage - 1) / banListPage
+	if page > pages {
+		page = pages
+	}
+	start := (page - 1) * banListPage
+	end := start + banListPage
+	if end > len(bans) {
+		end = len(bans)
+	}
+
+	var b strings.Builder
+	b.WriteString(localization.GetText("bans_title", len(bans), page, pages))
+	for _, ban := range bans[start:end] {
+		b.WriteString("\n" + formatBanLine(ban, true))
+	}
+	if page < pages {
+		b.WriteString("\n" + localization.GetText("bans_next", cfg.Prefix, page+1))
+	}
+
+	return b.String()
+}
+
+// banInfo отправляет модератору действующий бан пользователя и его последние баны
+func banInfo(reply responder, guildID, userID string) {
+	bans, err := db.GetBansByUser(userID)
+	if err != nil {
+		fmt.Printf("Ошибка получения банов пользователя: %v\n", err)
+		reply.Private(localization.GetText("bans_error", err.Error()))
+		return
+	}
+
+	var history []db.Ban
+	for _, ban := range bans {
+		if banInGuild(ban, guildID) {
+			history = append(history, ban)
+		}
+	}
+	if len(history) == 0 {
+		reply.Private(localization.GetText("ban_info_none", userID))
+		return
+	}
+
+	reply.Private(formatBanInfo(userID, history))
+}
+
+// formatBanInfo формирует подробности о банах пользователя: действующий бан и последние баны
+func formatBanInfo(userID string, history []db.Ban) string {
+	lines := []string{localization.GetText("ban_info_title", userID, len(history))}
+
+	active := false
+	for _, ban := range history {
+		if banActive(ban) {
+			active = true
+			lines = append(lines,
+				localization.GetText("ban_info_active", ban.ID, banEnforcementText(ban.Enforcement), banStatusText(ban)),
+				localization.GetText("ban_info_moderator", ban.AdminID, ban.Timestamp.Unix()),
+				localization.GetText("ban_info_reason", truncateRunes(ban.Reason, maxEmbedFieldValue)),
+			)
+			break
+		}
+	}
+	if !active {
+		lines = append(lines, localization.GetText("ban_info_not_active"))
+	}
+
+	if len(history) > banInfoHistory {
+		history = history[:banInfoHistory]
+	}
+	lines = append(lines, localization.GetText("ban_info_history"))
+	for _, ban := range history {
+		lines = append(lines, formatBanLine(ban, false))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatBanLine формирует строку бана для списков. withUser - добавить упоминание пользователя
+func formatBanLine(ban db.Ban, withUser bool) string {
+	user := ""
+	if withUser {
+		user = fmt.Sprintf(" <@%s>", ban.UserID)
+	}
+	return fmt.Sprintf("**#%d**%s <t:%d:d> %s - %s, %s",
+		ban.ID, user, ban.Timestamp.Unix(), banListReason(ban.Reason),
+		banEnforcementText(ban.Enforcement), banStatusText(ban),
+	)
+}
+
+// banStatusText возвращает локализованное состояние бана: снят, истек, навсегда или время окончания
+func banStatusText(ban db.Ban) string {
+	switch {
+	case ban.LiftedAt != nil && ban.LiftedBy != "":
+		return localization.GetText("ban_status_lifted", ban.LiftedBy, ban.LiftedAt.Unix())
+	case ban.LiftedAt != nil || (ban.ExpiresAt != nil && !ban.ExpiresAt.After(time.Now())):
+		return localization.GetText("ban_status_expired")
+	case ban.ExpiresAt == nil:
+		return localization.GetText("ban_status_permanent")
+	default:
+		return localization.GetText("ban_status_expires", ban.ExpiresAt.Unix())
+	}
+}
+
+// banEnforcementText возвращает локализованное название способа применения бана
+func banEnforcementText(enforcement db.BanEnforcement) string {
+	return localization.GetText("ban_enforcement_" + string(enforcement))
+}
+
+// banListReason сворачивает причину бана в одну короткую строку для списков
+func banListReason(reason string) string {
+	return truncateRunes(strings.Join(strings.Fields(reason), " "), maxBanListReason)
+}
+
+// banActive проверяет, действует ли бан: он не снят и не истек
+func banActive(ban db.Ban) bool {
+	return ban.LiftedAt == nil && (ban.ExpiresAt == nil || ban.ExpiresAt.After(time.Now()))
+}
+
+// banInGuild проверяет, относится ли бан к серверу. Баны без сервера, выданные до
+// сохранения сервера в базе данных, относятся ко всем серверам
+func banInGuild(ban db.Ban, guildID string) bool {
+	return ban.GuildID == "" || ban.GuildID == guildID
+}
+
+// StartBanScheduler запускает фоновое снятие истекших банов. Истекшие баны хранятся в базе данных,
+// пока не будут сняты, поэтому после перезапуска бота пропущенные баны снимаются при первой проверке
+func StartBanScheduler(s *discordgo.Session) {
+	go func() {
+		ticker := time.NewTicker(banSchedulerEvery)
+		defer ticker.Stop()
+
+		for {
+			liftExpiredBans(s)
+			<-ticker.C
+		}
+	}()
+}
+
+// liftExpiredBans снимает истекшие баны в Discord, отмечает их снятыми и записывает модерационные случаи.
+// При сетевой ошибке бан остается истекшим и снимается при следующей проверке
+func liftExpiredBans(s *discordgo.Session) {
+	bans, err := db.GetExpiredBans()
+	if err != nil {
+		fmt.Printf("Ошибка получения истекших банов: %v\n", err)
+		return
+	}
+
+	for _, ban := range bans {
+		reason := localization.GetText("ban_expired_reason", ban.ID)
+
+		// Тайм-аут в Discord заканчивается сам
+		if ban.Enforcement != db.BanEnforcementTimeout {
+			if err := liftDiscordBan(s, ban, reason); err != nil {
+				var restErr *discordgo.RESTError
+				if !errors.As(err, &restErr) {
+					fmt.Printf("Не удалось снять истекший бан #%d, повтор при следующей проверке: %v\n", ban.ID, err)
+					continue
+				}
+				fmt.Printf("Истекший бан #%d снят только в базе данных: %v\n", ban.ID, err)
+			}
+		}
+
+		if err := db.LiftBan(ban.ID, ""); err != nil {
+			fmt.Printf("Ошибка снятия истекшего бана #%d: %v\n", ban.ID, err)
+			continue
+		}
+
+		if ban.GuildID != "" {
+			if _, err := recordCase(s, db.ModerationCase{
+				GuildID:     ban.GuildID,
+				UserID:      ban.UserID,
+				ModeratorID: s.State.User.ID,
+				Action:      db.CaseUnban,
+				Reason:      reason,
+				BanID:       ban.ID,
+			}); err != nil {
+				fmt.Printf("Ошибка сохранения случая снятия истекшего бана #%d: %v\n", ban.ID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"discord-bot/db"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestBanActive(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	cases := []struct {
+		name   string
+		ban    db.Ban
+		active bool
+	}{
+		{"бессрочный", db.Ban{}, true},
+		{"временный", db.Ban{ExpiresAt: &future}, true},
+		{"истекший", db.Ban{ExpiresAt: &past}, false},
+		{"снятый", db.Ban{LiftedAt: &past}, false},
+	}
+	for _, c := range cases {
+		if got := banActive(c.ban); got != c.active {
+			t.Errorf("Бан %s: ожидалось %v, получено %v", c.name, c.active, got)
+		}
+	}
+}
+
+func TestBanInGuild(t *testing.T) {
+	if !banInGuild(db.Ban{}, "guild") {
+		t.Error("Бан без сервера должен относиться ко всем серверам")
+	}
+	if !banInGuild(db.Ban{GuildID: "guild"}, "guild") || banInGuild(db.Ban{GuildID: "other"}, "guild") {
+		t.Error("Бан с сервером должен относиться только к своему серверу")
+	}
+}
+
+func TestFormatBanListPages(t *testing.T) {
+	bans := make([]db.Ban, banListPage+2)
+	for index := range bans {
+		bans[index] = db.Ban{
+			ID:          int64(len(bans) - index),
+			UserID:      "user",
+			Reason:      "spam\nflood",
+			Enforcement: db.BanEnforcementMessages,
+			Timestamp:   time.Unix(1700000000, 0),
+		}
+	}
+
+	first := formatBanList(bans, 1)
+	if got := strings.Count(first, "\n**#"); got != banListPage {
+		t.Errorf("На первой странице ожидалось %d банов, получено %d", banListPage, got)
+	}
+	if strings.Contains(first, "spam\nflood") {
+		t.Error("Причина в списке должна быть свернута в одну строку")
+	}
+
+	last := formatBanList(bans, 3)
+	if got := strings.Count(last, "\n**#"); got != 2 {
+		t.Errorf("На последней странице ожидалось 2 бана, получено %d", got)
+	}
+}
+
+func TestIsAlreadyLifted(t *testing.T) {
+	unknownBan := &discordgo.RESTError{Message: &discordgo.APIErrorMessage{Code: discordgo.ErrCodeUnknownBan}}
+	if !isAlreadyLifted(fmt.Errorf("не удалось снять бан на сервере: %w", unknownBan)) {
+		t.Error("Неизвестный бан означает, что пользователь уже не забанен")
+	}
+
+	missing := &discordgo.RESTError{Message: &discordgo.APIErrorMessage{Code: discordgo.ErrCodeMissingPermissions}}
+	if isAlreadyLifted(missing) {
+		t.Error("Нехватка прав не означает, что бан снят")
+	}
+	if isAlreadyLifted(fmt.Errorf("сеть недоступна")) {
+		t.Error("Обычная ошибка не означает, что бан снят")
+	}
+}
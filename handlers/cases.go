@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"discord-bot/db"
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// caseModActions - действия журнала модерации для модерационных случаев, названных иначе
+var caseModActions = map[db.CaseAction]string{
+	db.CaseReportConfirm: modActionConfirm,
+}
+
+// recordCase сохраняет модерационный случай и записывает его в журнал модерации с номером случая.
+// Если случай не удалось сохранить, возвращается ошибка. Остальные действия уже применены в Discord
+// и записываются в журнал без номера, а предупреждение существует только в базе данных и не записывается
+func recordCase(s *discordgo.Session, c db.ModerationCase) (db.ModerationCase, error) {
+	number, err := db.AddCase(c)
+	if err != nil {
+		if c.Action == db.CaseWarn {
+			return c, err
+		}
+		number = 0
+	}
+	c.Number = number
+
+	logModAction(s, c.GuildID, caseLogEntry(c))
+	return c, err
+}
+
+// caseSuffix возвращает пометку с номером случая для ответа модератору (пусто - случай не сохранен)
+func caseSuffix(c db.ModerationCase) string {
+	if c.Number == 0 {
+		return ""
+	}
+	return " " + localization.GetText("case_suffix", c.Number)
+}
+
+// caseLogEntry формирует запись журнала модерации по модерационному случаю
+func caseLogEntry(c db.ModerationCase) modLogEntry {
+	action, ok := caseModActions[c.Action]
+	if !ok {
+		action = string(c.Action)
+	}
+	return modLogEntry{
+		Action:      action,
+		TargetID:    c.UserID,
+		ModeratorID: c.ModeratorID,
+		Reason:      c.Reason,
+		ReportID:    c.ReportID,
+		Duration:    c.Duration,
+		CaseNumber:  c.Number,
+	}
+}
+
+// messageEvidence возвращает доказательство для случая из текстовой команды: сообщение,
+// на которое модератор ответил командой (nil - команда не является ответом)
+func messageEvidence(m *discordgo.Message) []string {
+	if m.MessageReference == nil || m.MessageReference.MessageID == "" {
+		return nil
+	}
+
+	channelID := m.MessageReference.ChannelID
+	if channelID == "" {
+		channelID = m.ChannelID
+	}
+	return []string{evidenceRef(channelID, m.MessageReference.MessageID)}
+}
+
+// evidenceRef формирует ссылку на сообщение-доказательство в формате хранения channelID/messageID
+func evidenceRef(channelID, messageID string) string {
+	return channelID + "/" + messageID
+}
+
+// handleCaseCommand обрабатывает команду модерационных случаев: case номер или case edit номер причина
+func handleCaseCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
+
+	if len(args) > 0 && strings.EqualFold(args[0], caseEditSubcommand) {
+		if len(args) < 3 {
+			reply.Reply(localization.GetText("case_usage", cfg.Prefix))
+			return
+		}
+		number, ok := parseCaseNumber(args[1])
+		if !ok {
+			reply.Reply(localization.GetText("case_usage", cfg.Prefix))
+			return
+		}
+		editCase(reply, m.GuildID, m.Author.ID, number, strings.Join(args[2:], " "))
+		return
+	}
+
+	if len(args) != 1 {
+		reply.Reply(localization.GetText("case_usage", cfg.Prefix))
+		return
+	}
+	number, ok := parseCaseNumber(args[0])
+	if !ok {
+		reply.Reply(localization.GetText("case_usage", cfg.Prefix))
+		return
+	}
+	viewCase(reply, m.GuildID, number)
+}
+
+// handleCaseInteraction обрабатывает слеш-команду /case
+func handleCaseInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	subcommand, options := subcommandOptions(i)
+	reply := &interactionResponder{s: s, i: i}
+
+	number := intOption(options, caseNumberOption)
+	if subcommand == caseEditSubcommand {
+		editCase(reply, i.GuildID, interactionUserID(i), number, stringOption(options, reasonOption))
+		return
+	}
+	viewCase(reply, i.GuildID, number)
+}
+
+// parseCaseNumber разбирает номер случая вида 12 или #12
+func parseCaseNumber(value string) (int64, bool) {
+	number, err := strconv.ParseInt(strings.TrimPrefix(value, "#"), 10, 64)
+	return number, err == nil && number > 0
+}
+
+// viewCase отправляет модератору подробности модерационного случая
+func viewCase(reply responder, guildID string, number int64) {
+	c, err := db.GetCase(guildID, number)
+	if err != nil {
+		fmt.Printf("Ошибка получения случая #%d: %v\n", number, err)
+		reply.Private(localization.GetText("case_error", err.Error()))
+		return
+	}
+	if c == nil {
+		reply.Private(localization.GetText("case_not_found", number))
+		return
+	}
+
+	reply.Private(formatCase(c))
+}
+
+// editCase меняет причину модерационного случая
+func editCase(reply responder, guildID, moderatorID string, number int64, reason string) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reply.Private(localization.GetText("case_usage", cfg.Prefix))
+		return
+	}
+
+	c, err := db.GetCase(guildID, number)
+	if err != nil {
+		fmt.Printf("Ошибка получения случая #%d: %v\n", number, err)
+		reply.Private(localization.GetText("case_error", err.Error()))
+		return
+	}
+	if c == nil {
+		reply.Private(localization.GetText("case_not_found", number))
+		return
+	}
+
+	if err := db.UpdateCaseReason(guildID, number, reason, moderatorID); err != nil {
+		fmt.Printf("Ошибка изменения случая #%d: %v\n", number, err)
+		reply.Private(localization.GetText("case_error", err.Error()))
+		return
+	}
+	reply.Private(localization.GetText("case_updated", number))
+}
+
+// formatCase формирует подробности модерационного случая: участники, причина, связи и доказательства
+func formatCase(c *db.ModerationCase) string {
+	lines := []string{
+		localization.GetText("case_view_title", c.Number, caseActionText(c.Action)),
+		localization.GetText("case_view_users", c.UserID, c.ModeratorID),
+		localization.GetText("case_view_created", c.Timestamp.Unix()),
+		localization.GetText("case_view_reason", truncateRunes(c.Reason, maxEmbedFieldValue)),
+	}
+	if c.Duration != nil {
+		lines = append(lines, localization.GetText("case_view_duration", formatDuration(*c.Duration)))
+	}
+	if c.ReportID != 0 {
+		lines = append(lines, localization.GetText("case_view_report", c.ReportID))
+	}
+	if c.BanID != 0 {
+		lines = append(lines, localization.GetText("case_view_ban", c.BanID))
+	}
+	if len(c.Evidence) > 0 {
+		links := make([]string, 0, len(c.Evidence))
+		for _, ref := range c.Evidence {
+			links = append(links, fmt.Sprintf("https://discord.com/channels/%s/%s", c.GuildID, ref))
+		}
+		lines = append(lines, localization.GetText("case_view_evidence", strings.Join(links, " ")))
+	}
+	if c.UpdatedAt != nil {
+		lines = append(lines, localization.GetText("case_view_updated", c.UpdatedBy, c.UpdatedAt.Unix()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// caseActionText возвращает локализованное название действия модерационного случая
+func caseActionText(action db.CaseAction) string {
+	return localization.GetText("case_action_" + string(action))
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"testing"
+
+	"discord-bot/db"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestParseCaseNumber(t *testing.T) {
+	tests := []struct {
+		value  string
+		number int64
+		ok     bool
+	}{
+		{"12", 12, true},
+		{"#7", 7, true},
+		{"0", 0, false},
+		{"-3", 0, false},
+		{"abc", 0, false},
+	}
+	for _, tt := range tests {
+		number, ok := parseCaseNumber(tt.value)
+		if ok != tt.ok || (ok && number != tt.number) {
+			t.Errorf("parseCaseNumber(%q) = %d, %v, ожидалось %d, %v", tt.value, number, ok, tt.number, tt.ok)
+		}
+	}
+}
+
+func TestMessageEvidence(t *testing.T) {
+	if got := messageEvidence(&discordgo.Message{ChannelID: "chan"}); got != nil {
+		t.Errorf("Команда без ответа не должна давать доказательств, получено %v", got)
+	}
+
+	m := &discordgo.Message{ChannelID: "chan", MessageReference: &discordgo.MessageReference{MessageID: "msg"}}
+	if got := messageEvidence(m); len(got) != 1 || got[0] != "chan/msg" {
+		t.Errorf("Ожидалось сообщение из канала команды, получено %v", got)
+	}
+
+	m.MessageReference.ChannelID = "other"
+	if got := messageEvidence(m); len(got) != 1 || got[0] != "other/msg" {
+		t.Errorf("Ожидалось сообщение из канала ответа, получено %v", got)
+	}
+}
+
+func TestCaseLogEntry(t *testing.T) {
+	entry := caseLogEntry(db.ModerationCase{Number: 4, Action: db.CaseReportConfirm, UserID: "user", ReportID: 9})
+	if entry.Action != modActionConfirm || entry.CaseNumber != 4 || entry.ReportID != 9 {
+		t.Errorf("Подтверждение репорта должно записываться как confirm с номером случая, получено %+v", entry)
+	}
+
+	if entry := caseLogEntry(db.ModerationCase{Action: db.CaseUnban}); entry.Action != modActionUnban {
+		t.Errorf("Ожидалось действие unban, получено %q", entry.Action)
+	}
+}
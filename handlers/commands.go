@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// Названия параметров слеш-команд модерации, голоса и утилит
+const (
+	userOption     = "user"
+	reasonOption   = "reason"
+	durationOption = "duration"
+	urlOption      = "url"
+	channelOption  = "channel"
+	nicknameOption = "nickname"
+	messageOption  = "message"
+	languageOption = "language"
+	pageOption     = "page"
+	reportIDOption = "id"
+)
+
+// Подкоманды слеш-команды /report
+const (
+	reportCreateSubcommand = "create"
+	reportViewSubcommand   = "view"
+)
+
+// Подкоманды слеш-команды /ban
+const (
+	banAddSubcommand  = "add"
+	banInfoSubcommand = "info"
+)
+
+// Подкоманды и параметры слеш-команды /case
+const (
+	caseViewSubcommand = "view"
+	caseEditSubcommand = "edit"
+	caseNumberOption   = "number"
+)
+
+// minPage - минимальный номер страницы, ID репорта и номер случая в параметрах слеш-команд
+var minPage = 1.0
+
+// maxNicknameLength - максимальная длина никнейма в Discord
+const maxNicknameLength = 32
+
+// aiModelCommands - команды запроса к конкретной модели AI
+var aiModelCommands = []string{"gemini", "grok", "chatgpt", "qwen", "claude"}
+
+// builtinCommands возвращает описания всех команд бота
+func builtinCommands() []*Command {
+	list := []*Command{
+		{
+			Name:     "help",
+			Category: CategoryGeneral,
+			Run: func(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+				HandleHelpCommand(s, m)
+			},
+		},
+		{
+			Name:     "language",
+			Aliases:  []string{"lang"},
+			Category: CategoryGeneral,
+			ArgsKey:  "language_command_args",
+			Run:      HandleLanguageCommand,
+			Options: []*CommandOption{
+				{Name: languageOption, Type: discordgo.ApplicationCommandOptionString, Required: true, Choices: languageChoices()},
+			},
+			Slash: handleLanguageInteraction,
+		},
+		{
+			Name:      "nickname",
+			Aliases:   []string{"nick"},
+			Category:  CategoryGeneral,
+			GuildOnly: true,
+			ArgsKey:   "nickname_command_args",
+			Run:       HandleNicknameCommand,
+			Options: []*CommandOption{
+				{Name: userOption, Key: "user", Type: discordgo.ApplicationCommandOptionUser, Required: true},
+				{Name: nicknameOption, Type: discordgo.ApplicationCommandOptionString, Required: true, MaxLength: maxNicknameLength},
+			},
+			Slash: handleNicknameInteraction,
+		},
+		{
+			Name:     "dm",
+			Aliases:  []string{"message"},
+			Category: CategoryGeneral,
+			ArgsKey:  "dm_command_args",
+			Run:      HandleDMCommand,
+			Options: []*CommandOption{
+				{Name: userOption, Key: "user", Type: discordgo.ApplicationCommandOptionUser, Required: true},
+				{Name: messageOption, Type: discordgo.ApplicationCommandOptionString, Required: true},
+			},
+			Slash: handleDMInteraction,
+		},
+		{
+			Name:      "report",
+			Category:  CategoryModeration,
+			GuildOnly: true,
+			ArgsKey:   "report_command_args",
+			Run:       handleReportCommand,
+			Options: []*CommandOption{
+				{
+					Name: reportCreateSubcommand,
+					Type: discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*CommandOption{
+						{Name: userOption, Key: "user", Type: discordgo.ApplicationCommandOptionUser, Required: true},
+						{Name: reasonOption, Key: "reason", Type: discordgo.ApplicationCommandOptionString, Required: true},
+					},
+				},
+				{
+					Name: reportViewSubcommand,
+					Type: discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*CommandOption{
+						{Name: reportIDOption, Type: discordgo.ApplicationCommandOptionInteger, Required: true, MinValue: &minPage},
+					},
+				},
+			},
+			Slash: handleReportInteraction,
+		},
+		{
+			Name:       "reports",
+			Category:   CategoryModeration,
+			Permission: PermissionModerator,
+			GuildOnly:  true,
+			ArgsKey:    "reports_command_args",
+			Run:        handleReportsCommand,
+			Options: []*CommandOption{
+				{Name: userOption, Key: "reports_user", Type: discordgo.ApplicationCommandOptionUser},
+				{Name: pageOption, Key: "page", Type: discordgo.ApplicationCommandOptionInteger, MinValue: &minPage},
+			},
+			Slash: handleReportsInteraction,
+		},
+		{
+			Name:       "ban",
+			Category:   CategoryModeration,
+			Permission: PermissionModerator,
+			GuildOnly:  true,
+			ArgsKey:    "ban_command_args",
+			Run:        handleBanCommand,
+			Options: []*CommandOption{
+				{
+					Name: banAddSubcommand,
+					Type: discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*CommandOption{
+						{Name: userOption, Key: "user", Type: discordgo.ApplicationCommandOptionUser, Required: true},
+						{Name: reasonOption, Key: "reason", Type: discordgo.ApplicationCommandOptionString, Required: true},
+						{Name: durationOption, Key: "duration", Type: discordgo.ApplicationCommandOptionString, Autocomplete: true},
+					},
+				},
+				{
+					Name: banInfoSubcommand,
+					Type: discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*CommandOption{
+						{Name: userOption, Key: "user", Type: discordgo.ApplicationCommandOptionUser, Required: true},
+					},
+				},
+			},
+			Slash:        handleBanInteraction,
+			Autocomplete: handleDurationAutocomplete,
+		},
+		{
+			Name:       "unban",
+			Category:   CategoryModeration,
+			Permission: PermissionModerator,
+			GuildOnly:  true,
+			ArgsKey:    "unban_command_args",
+			Run:        handleUnbanCommand,
+			Options: []*CommandOption{
+				{Name: userOption, Key: "user", Type: discordgo.ApplicationCommandOptionUser, Required: true},
+				{Name: reasonOption, Key: "reason", Type: discordgo.ApplicationCommandOptionString},
+			},
+			Slash: handleUnbanInteraction,
+		},
+		{
+			Name:       "bans",
+			Category:   CategoryModeration,
+			Permission: PermissionModerator,
+			GuildOnly:  true,
+			ArgsKey:    "bans_command_args",
+			Run:        handleBansCommand,
+			Options: []*CommandOption{
+				{Name: pageOption, Key: "page", Type: discordgo.ApplicationCommandOptionInteger, MinValue: &minPage},
+			},
+			Slash: handleBansInteraction,
+		},
+		{
+			Name:       "case",
+			Category:   CategoryModeration,
+			Permission: PermissionModerator,
+			GuildOnly:  true,
+			ArgsKey:    "case_command_args",
+			Run:        handleCaseCommand,
+			Options: []*CommandOption{
+				{
+					Name: caseViewSubcommand,
+					Type: discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*CommandOption{
+						{Name: caseNumberOption, Key: "case_number", Type: discordgo.ApplicationCommandOptionInteger, Required: true, MinValue: &minPage},
+					},
+				},
+				{
+					Name: caseEditSubcommand,
+					Type: discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*CommandOption{
+						{Name: caseNumberOption, Key: "case_number", Type: discordgo.ApplicationCommandOptionInteger, Required: true, MinValue: &minPage},
+						{Name: reasonOption, Key: "reason", Type: discordgo.ApplicationCommandOptionString, Required: true},
+					},
+				},
+			},
+			Slash: handleCaseInteraction,
+		},
+		{
+			Name:       "warn",
+			Category:   CategoryModeration,
+			Permission: PermissionModerator,
+			GuildOnly:  true,
+			ArgsKey:    "warn_command_args",
+			Run:        handleWarnCommand,
+			Options: []*CommandOption{
+				{Name: userOption, Key: "user", Type: discordgo.ApplicationCommandOptionUser, Required: true},
+				{Name: reasonOption, Key: "reason", Type: discordgo.ApplicationCommandOptionString, Required: true},
+			},
+			Slash: handleWarnInteraction,
+		},
+		{
+			Name:       "timeout",
+			Aliases:    []string{"mute"},
+			Category:   CategoryModeration,
+			Permission: PermissionModerator,
+			GuildOnly:  true,
+			ArgsKey:    "timeout_command_args",
+			Run:        handleTimeoutCommand,
+			Options: []*CommandOption{
+				{Name: userOption, Key: "user", Type: discordgo.ApplicationCommandOptionUser, Required: true},
+				{Name: durationOption, Key: "timeout_duration", Type: discordgo.ApplicationCommandOptionString, Required: true, Autocomplete: true},
+				{Name: reasonOption, Key: "reason", Type: discordgo.ApplicationCommandOptionString, Required: true},
+			},
+			Slash:        handleTimeoutInteraction,
+			Autocomplete: handleDurationAutocomplete,
+		},
+		{
+			Name:       "kick",
+			Category:   CategoryModeration,
+			Permission: PermissionModerator,
+			GuildOnly:  true,
+			ArgsKey:    "kick_command_args",
+			Run:        handleKickCommand,
+			Options: []*CommandOption{
+				{Name: userOption, Key: "user", Type: discordgo.ApplicationCommandOptionUser, Required: true},
+				{Name: reasonOption, Key: "reason", Type: discordgo.ApplicationCommandOptionString, Required: true},
+			},
+			Slash: handleKickInteraction,
+		},
+		{
+			Name:       "aiquota",
+			Category:   CategoryModeration,
+			Permission: PermissionModerator,
+			ArgsKey:    "aiquota_command_args",
+			Run:        HandleAIQuotaCommand,
+		},
+		{
+			Name:     "ai",
+			Category: CategoryAI,
+			ArgsKey:  "ai_command_args",
+			Run:      HandleAICommand,
+			Options: []*CommandOption{
+				{
+					Name: "ask",
+					Type: discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*CommandOption{
+						{Name: queryOption, Key: "ai_query", Type: discordgo.ApplicationCommandOptionString, Required: true},
+						{Name: personaOption, Key: "ai_persona", Type: discordgo.ApplicationCommandOptionString, Autocomplete: true},
+						{Name: imageOption, Key: "ai_image", Type: discordgo.ApplicationCommandOptionAttachment},
+					},
+				},
+				{
+					Name: "reset",
+					Type: discordgo.ApplicationCommandOptionSubCommand,
+				},
+			},
+			Slash:        handleAIInteraction,
+			Autocomplete: handleAIPersonaAutocomplete,
+		},
+	}
+
+	for _, model := range aiModelCommands {
+		model := model
+		list = append(list, &Command{
+			Name:     model,
+			Category: CategoryAI,
+			ArgsKey:  "ai_query_args",
+			Run: func(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+				HandleAICommand(s, m, append([]string{model}, args...))
+			},
+			Options: []*CommandOption{
+				{Name: queryOption, Key: "ai_query", Type: discordgo.ApplicationCommandOptionString, Required: true},
+			},
+			Slash: handleAIModelInteraction,
+		})
+	}
+
+	list = append(list,
+		&Command{
+			Name:     "summarize",
+			Category: CategoryAI,
+			ArgsKey:  "summarize_command_args",
+			Run:      HandleSummarizeCommand,
+			Options: []*CommandOption{
+				{Name: summaryCountOption, Type: discordgo.ApplicationCommandOptionInteger, MinValue: &minSummaryMessages, MaxValue: maxSummaryMessages},
+				{Name: summarySinceOption, Type: discordgo.ApplicationCommandOptionString},
+			},
+			Slash: handleSummarizeInteraction,
+		},
+		&Command{
+			Name:      "play",
+			Category:  CategoryMusic,
+			GuildOnly: true,
+			ArgsKey:   "play_command_args",
+			Run:       HandlePlayCommand,
+			Options: []*CommandOption{
+				{Name: urlOption, Type: discordgo.ApplicationCommandOptionString, Required: true},
+				{
+					Name:         channelOption,
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildVoice, discordgo.ChannelTypeGuildStageVoice},
+				},
+			},
+			Slash: handlePlayInteraction,
+		},
+		&Command{
+			Name:      "stop",
+			Category:  CategoryMusic,
+			GuildOnly: true,
+			Run: func(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+				HandleStopCommand(s, m)
+			},
+			Slash: handleStopInteraction,
+		},
+		&Command{
+			Name:      "leave",
+			Category:  CategoryMusic,
+			GuildOnly: true,
+			Run: func(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+				HandleLeaveCommand(s, m)
+			},
+			Slash: handleLeaveInteraction,
+		},
+	)
+
+	return list
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// durationPresets - варианты длительности, предлагаемые автодополнением
+var durationPresets = []string{"1h", "6h", "12h", "1d", "3d", "7d", "30d"}
+
+// maxDurationWords - наибольшее число слов длительности в конце текстовой команды, например "2 weeks 3 days"
+const maxDurationWords = 4
+
+// maxDuration - максимальная длительность наказания, защищает от переполнения time.Duration
+const maxDuration = 10 * 365 * 24 * time.Hour
+
+// Ошибки разбора длительности. Для ответа пользователю переводятся функцией durationErrorText
+var (
+	errDurationFormat    = errors.New("неверный формат длительности")
+	errDurationUnit      = errors.New("неизвестная единица длительности")
+	errDurationNoUnit    = errors.New("у длительности не указана единица")
+	errDurationZero      = errors.New("длительность должна быть больше нуля")
+	errDurationTooLong   = errors.New("слишком большая длительность")
+	errDurationPermanent = errors.New("длительность не может быть бессрочной")
+)
+
+// durationUnits - единицы длительности, в том числе полные названия на английском и русском
+var durationUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "wk": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	"mo": 30 * 24 * time.Hour, "month": 30 * 24 * time.Hour, "months": 30 * 24 * time.Hour,
+	"y": 365 * 24 * time.Hour, "year": 365 * 24 * time.Hour, "years": 365 * 24 * time.Hour,
+
+	"с": time.Second, "сек": time.Second, "секунд": time.Second, "секунды": time.Second,
+	"м": time.Minute, "мин": time.Minute, "минут": time.Minute, "минуты": time.Minute, "минута": time.Minute,
+	"ч": time.Hour, "час": time.Hour, "часа": time.Hour, "часов": time.Hour,
+	"д": 24 * time.Hour, "день": 24 * time.Hour, "дня": 24 * time.Hour, "дней": 24 * time.Hour,
+	"н": 7 * 24 * time.Hour, "нед": 7 * 24 * time.Hour, "неделя": 7 * 24 * time.Hour, "недели": 7 * 24 * time.Hour, "недель": 7 * 24 * time.Hour,
+	"мес": 30 * 24 * time.Hour, "месяц": 30 * 24 * time.Hour, "месяца": 30 * 24 * time.Hour, "месяцев": 30 * 24 * time.Hour,
+}
+
+// permanentWords - значения, обозначающие бессрочное наказание
+var permanentWords = map[string]bool{
+	"permanent": true, "perm": true, "forever": true, "навсегда": true, "бессрочно": true,
+}
+
+// parseDuration разбирает длительность из одной или нескольких частей число-единица:
+// 30m, 2h, 7d, 1w2d, 1h30m, 1.5h, "1 day", "2 weeks 3 days". Бессрочные значения
+// (permanent, навсегда) возвращают errDurationPermanent
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, errDurationFormat
+	}
+	if permanentWords[value] {
+		return 0, errDurationPermanent
+	}
+
+	var total float64
+	rest := value
+	for rest != "" {
+		number := strings.TrimLeftFunc(rest, func(r rune) bool { return unicode.IsDigit(r) || r == '.' })
+		if len(number) == len(rest) {
+			return 0, errDurationFormat
+		}
+		n, err := strconv.ParseFloat(rest[:len(rest)-len(number)], 64)
+		if err != nil {
+			return 0, errDurationFormat
+		}
+
+		number = strings.TrimLeft(number, " ")
+		tail := strings.TrimLeftFunc(number, unicode.IsLetter)
+		unitName := number[:len(number)-len(tail)]
+		if unitName == "" {
+			return 0, errDurationNoUnit
+		}
+		unit, ok := durationUnits[unitName]
+		if !ok {
+			return 0, fmt.Errorf("%w: %q", errDurationUnit, unitName)
+		}
+
+		total += n * float64(unit)
+		if total > float64(maxDuration) {
+			return 0, errDurationTooLong
+		}
+		rest = strings.TrimLeft(strings.TrimPrefix(strings.TrimLeft(tail, " "), ","), " ")
+	}
+
+	duration := time.Duration(total).Round(time.Second)
+	if duration <= 0 {
+		return 0, errDurationZero
+	}
+	return duration, nil
+}
+
+// durationWords ищет длительность из нескольких слов в начале (leading) или в конце аргументов
+// и возвращает число занятых ею слов. Сначала проверяются самые длинные варианты, чтобы
+// "1 day" не разбиралось как "1". Ноль означает, что длительность не найдена
+func durationWords(args []string, leading bool, parse func(string) error) int {
+	for words := min(len(args), maxDurationWords); words > 0; words-- {
+		part := args[len(args)-words:]
+		if leading {
+			part = args[:words]
+		}
+		if parse(strings.Join(part, " ")) == nil {
+			return words
+		}
+	}
+	return 0
+}
+
+// formatDuration возвращает длительность для показа пользователю, например "7 д" или "1 ч 30 мин",
+// вместо записи time.Duration вида "168h0m0s"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	units := []struct {
+		size time.Duration
+		key  string
+	}{
+		{24 * time.Hour, "duration_days"},
+		{time.Hour, "duration_hours"},
+		{time.Minute, "duration_minutes"},
+		{time.Second, "duration_seconds"},
+	}
+
+	var parts []string
+	for _, unit := range units {
+		if n := d / unit.size; n > 0 {
+			parts = append(parts, localization.GetText(unit.key, int64(n)))
+			d -= n * unit.size
+		}
+	}
+	if len(parts) == 0 {
+		return localization.GetText("duration_seconds", 0)
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseBanDuration разбирает длительность бана. Для бессрочного бана возвращается nil
+func parseBanDuration(value string) (*time.Duration, error) {
+	duration, err := parseDuration(value)
+	if errors.Is(err, errDurationPermanent) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &duration, nil
+}
+
+// durationErrorText возвращает локализованное объяснение ошибки разбора длительности value
+func durationErrorText(value string, err error) string {
+	switch {
+	case errors.Is(err, errDurationUnit):
+		return localization.GetText("duration_invalid_unit", value)
+	case errors.Is(err, errDurationNoUnit):
+		return localization.GetText("duration_no_unit", value)
+	case errors.Is(err, errDurationZero):
+		return localization.GetText("duration_zero", value)
+	case errors.Is(err, errDurationTooLong):
+		return localization.GetText("duration_too_long", value)
+	case errors.Is(err, errDurationPermanent):
+		return localization.GetText("duration_permanent_not_allowed")
+	default:
+		return localization.GetText("duration_invalid", value)
+	}
+}
+
+// handleDurationAutocomplete предлагает варианты длительности. Введенное значение
+// предлагается первым, если оно описывает допустимую длительность
+func handleDurationAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Параметр длительности может находиться в подкоманде
+	options := i.ApplicationCommandData().Options
+	if len(options) > 0 && options[0].Type == discordgo.ApplicationCommandOptionSubCommand {
+		options = options[0].Options
+	}
+
+	var typed string
+	for _, option := range options {
+		if option.Focused {
+			typed = strings.ToLower(strings.TrimSpace(option.StringValue()))
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	if _, err := parseDuration(typed); err == nil {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: typed, Value: typed})
+	}
+	for _, preset := range durationPresets {
+		if preset != typed && strings.HasPrefix(preset, typed) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: preset, Value: preset})
+		}
+	}
+
+	respondAutocomplete(s, i, choices)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"30m", 30 * time.Minute},
+		{"2h", 2 * time.Hour},
+		{"7d", 7 * day},
+		{"1w2d", 9 * day},
+		{"1h30m", 90 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"1 day", day},
+		{"2 weeks, 3 days", 17 * day},
+		{"3 дня", 3 * day},
+		{" 10S ", 10 * time.Second},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, %v, ожидалось %v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseDurationErrors(t *testing.T) {
+	tests := []struct {
+		value string
+		want  error
+	}{
+		{"", errDurationFormat},
+		{"d7", errDurationFormat},
+		{"7x", errDurationUnit},
+		{"100", errDurationNoUnit},
+		{"0d", errDurationZero},
+		{"20y", errDurationTooLong},
+		{"permanent", errDurationPermanent},
+	}
+	for _, tt := range tests {
+		if _, err := parseDuration(tt.value); !errors.Is(err, tt.want) {
+			t.Errorf("parseDuration(%q): ожидалась ошибка %v, получено %v", tt.value, tt.want, err)
+		}
+	}
+}
+
+func TestSplitBanDuration(t *testing.T) {
+	tests := []struct {
+		args     []string
+		reason   string
+		duration time.Duration // 0 - навсегда
+	}{
+		{[]string{"spam", "7d"}, "spam", 7 * 24 * time.Hour},
+		{[]string{"spam", "and", "flood", "1", "day"}, "spam and flood", 24 * time.Hour},
+		{[]string{"spam", "permanent"}, "spam", 0},
+		{[]string{"spam", "2", "times"}, "spam 2 times", 0},
+		{[]string{"violated", "rule", "3"}, "violated rule 3", 0},
+		{[]string{"7d"}, "", 7 * 24 * time.Hour},
+		{[]string{""}, "", 0},
+		{[]string{"spam", ""}, "spam", 0},
+		{[]string{"spam", "", "7d", ""}, "spam", 7 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		reason, duration, err := splitBanDuration(tt.args)
+		if err != nil || reason != tt.reason {
+			t.Errorf("splitBanDuration(%q) = %q, %v, ожидалась причина %q", tt.args, reason, err, tt.reason)
+			continue
+		}
+		if (duration == nil) != (tt.duration == 0) || (duration != nil && *duration != tt.duration) {
+			t.Errorf("splitBanDuration(%q): неверная длительность %v, ожидалось %v", tt.args, duration, tt.duration)
+		}
+	}
+
+	if _, _, err := splitBanDuration([]string{"spam", "7dd"}); !errors.Is(err, errDurationUnit) {
+		t.Errorf("Опечатка в длительности должна давать ошибку, а не бессрочный бан, получено %v", err)
+	}
+	if _, _, err := splitBanDuration([]string{"100"}); !errors.Is(err, errDurationNoUnit) {
+		t.Errorf("Число без единицы и без причины должно давать ошибку, получено %v", err)
+	}
+}
+
+func TestSplitTimeoutDuration(t *testing.T) {
+	tests := []struct {
+		args     []string
+		duration time.Duration
+		reason   string
+	}{
+		{[]string{"30m", "spam"}, 30 * time.Minute, "spam"},
+		{[]string{"1", "day", "spam"}, 24 * time.Hour, "spam"},
+		{[]string{"2", "hours", "30", "min", "flood", "again"}, 150 * time.Minute, "flood again"},
+		{[]string{"1h", "2", "times"}, time.Hour, "2 times"},
+		{[]string{"1h"}, time.Hour, ""},
+	}
+	for _, tt := range tests {
+		duration, reason, err := splitTimeoutDuration(tt.args)
+		if err != nil || duration != tt.duration || reason != tt.reason {
+			t.Errorf("splitTimeoutDuration(%q) = %v, %q, %v, ожидалось %v, %q", tt.args, duration, reason, err, tt.duration, tt.reason)
+		}
+	}
+
+	if _, _, err := splitTimeoutDuration([]string{"1", "dya", "spam"}); !errors.Is(err, errDurationNoUnit) {
+		t.Errorf("Неверная длительность должна давать ошибку, получено %v", err)
+	}
+	if _, _, err := splitTimeoutDuration([]string{"permanent", "spam"}); !errors.Is(err, errDurationPermanent) {
+		t.Errorf("Тайм-аут не может быть бессрочным, получено %v", err)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	// В тестах переводы не загружены, поэтому GetText возвращает ключи единиц
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{7 * 24 * time.Hour, "duration_days"},
+		{90 * time.Minute, "duration_hours duration_minutes"},
+		{24*time.Hour + 30*time.Second, "duration_days duration_seconds"},
+		{0, "duration_seconds"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.duration); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, ожидалось %q", tt.duration, got, tt.want)
+		}
+	}
+}
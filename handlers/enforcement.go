@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"discord-bot/config"
+	"discord-bot/db"
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	maxTimeoutDuration   = 28 * 24 * time.Hour // Максимальная длительность тайм-аута в Discord
+	maxDeleteMessageDays = 7                   // Максимальное окно удаления сообщений при бане на сервере
+	maxAuditLogReason    = 512                 // Максимальная длина причины в журнале аудита Discord
+)
+
+// enforceBan применяет бан в Discord согласно настройкам, сохраняет его в базе данных
+// и записывает модерационный случай. c описывает бан: сервер, пользователь, модератор, причина,
+// длительность и связи с репортом и доказательствами. Если Discord отклонил бан (например, у бота
+// нет прав), бан применяется удалением сообщений, а fallback содержит ошибку Discord для предупреждения модератора
+func enforceBan(s *discordgo.Session, c db.ModerationCase) (result db.ModerationCase, fallback error, err error) {
+	ban := db.Ban{
+		UserID:      c.UserID,
+		Reason:      c.Reason,
+		AdminID:     c.ModeratorID,
+		GuildID:     c.GuildID,
+		Enforcement: db.BanEnforcementMessages,
+	}
+	if c.Duration != nil {
+		expires := time.Now().Add(*c.Duration)
+		ban.ExpiresAt = &expires
+	}
+
+	if cfg.BanEnforcement.Mode == config.BanModeDiscord && c.GuildID != "" {
+		enforcement, discordErr := applyDiscordBan(s, c.GuildID, c.UserID, c.Reason, ban.ExpiresAt)
+		if discordErr != nil {
+			fmt.Printf("Не удалось применить бан пользователя %s в Discord, используется удаление сообщений: %v\n", c.UserID, discordErr)
+			fallback = discordErr
+		} else {
+			ban.Enforcement = enforcement
+		}
+	}
+
+	c.BanID, err = db.RecordBan(ban)
+	if err != nil {
+		return c, fallback, err
+	}
+
+	c.Action = db.CaseBan
+	result, err = recordCase(s, c)
+	if err != nil {
+		fmt.Printf("Ошибка сохранения случая бана #%d: %v\n", c.BanID, err)
+	}
+	return result, fallback, nil
+}
+
+// applyDiscordBan банит пользователя на сервере или выдает ему тайм-аут до expiresAt (nil - навсегда)
+func applyDiscordBan(s *discordgo.Session, guildID, userID, reason string, expiresAt *time.Time) (db.BanEnforcement, error) {
+	auditReason := discordgo.WithAuditLogReason(truncateRunes(reason, maxAuditLogReason))
+
+	if useTimeout(expiresAt) {
+		if err := s.GuildMemberTimeout(guildID, userID, expiresAt, auditReason); err != nil {
+			return "", fmt.Errorf("не удалось выдать тайм-аут: %w", err)
+		}
+		return db.BanEnforcementTimeout, nil
+	}
+
+	days := cfg.BanEnforcement.DeleteMessageDays
+	if days < 0 {
+		days = 0
+	}
+	if days > maxDeleteMessageDays {
+		days = maxDeleteMessageDays
+	}
+
+	if err := s.GuildBanCreateWithReason(guildID, userID, truncateRunes(reason, maxAuditLogReason), days, auditReason); err != nil {
+		return "", fmt.Errorf("не удалось забанить на сервере: %w", err)
+	}
+	return db.BanEnforcementGuildBan, nil
+}
+
+// useTimeout проверяет, применять ли временный бан тайм-аутом: тайм-аут в Discord ограничен 28 днями
+func useTimeout(expiresAt *time.Time) bool {
+	return cfg.BanEnforcement.TimeoutTemporary && expiresAt != nil && time.Until(*expiresAt) <= maxTimeoutDuration
+}
+
+// messagesBanned проверяет, нужно ли удалять сообщения пользователя на сервере: у него есть
+// действующий бан этого сервера, применяемый удалением сообщений. Баны, примененные баном
+// на сервере или тайм-аутом, и баны других серверов не учитываются, в личных сообщениях
+// сообщения не удаляются
+func messagesBanned(guildID, userID string) (bool, error) {
+	if guildID == "" {
+		return false, nil
+	}
+
+	bans, err := activeGuildBans(guildID, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, ban := range bans {
+		if deletesMessages(ban) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deletesMessages проверяет, применяется ли бан удалением сообщений. Баны без способа применения,
+// сохраненные до его появления, применяются удалением сообщений
+func deletesMessages(ban db.Ban) bool {
+	return ban.Enforcement == db.BanEnforcementMessages || ban.Enforcement == ""
+}
+
+// banFallbackText возвращает предупреждение модератору о применении бана удалением сообщений
+func banFallbackText(err error) string {
+	if isMissingPermissions(err) {
+		return localization.GetText("ban_enforcement_no_permission")
+	}
+	return localization.GetText("ban_enforcement_fallback", err.Error())
+}
+
+// isMissingPermissions проверяет, отклонил ли Discord запрос из-за нехватки прав бота
+func isMissingPermissions(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeMissingPermissions
+}
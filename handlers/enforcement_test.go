@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"discord-bot/db"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestUseTimeout(t *testing.T) {
+	saved := cfg.BanEnforcement
+	defer func() { cfg.BanEnforcement = saved }()
+
+	week := time.Now().Add(7 * 24 * time.Hour)
+	month := time.Now().Add(30 * 24 * time.Hour)
+
+	cfg.BanEnforcement.TimeoutTemporary = true
+	if !useTimeout(&week) {
+		t.Error("Временный бан до 28 дней должен применяться тайм-аутом")
+	}
+	if useTimeout(&month) {
+		t.Error("Бан длиннее 28 дней не может быть тайм-аутом")
+	}
+	if useTimeout(nil) {
+		t.Error("Бессрочный бан не может быть тайм-аутом")
+	}
+
+	cfg.BanEnforcement.TimeoutTemporary = false
+	if useTimeout(&week) {
+		t.Error("Без timeout_temporary временный бан должен применяться баном на сервере")
+	}
+}
+
+func TestIsMissingPermissions(t *testing.T) {
+	missing := &discordgo.RESTError{Message: &discordgo.APIErrorMessage{Code: discordgo.ErrCodeMissingPermissions}}
+	if !isMissingPermissions(fmt.Errorf("не удалось забанить на сервере: %w", missing)) {
+		t.Error("Ожидалась ошибка нехватки прав")
+	}
+
+	unknown := &discordgo.RESTError{Message: &discordgo.APIErrorMessage{Code: discordgo.ErrCodeUnknownMember}}
+	if isMissingPermissions(unknown) {
+		t.Error("Неизвестный участник не является нехваткой прав")
+	}
+	if isMissingPermissions(fmt.Errorf("сеть недоступна")) {
+		t.Error("Обычная ошибка не является нехваткой прав")
+	}
+}
+
+func TestDeletesMessages(t *testing.T) {
+	if !deletesMessages(db.Ban{Enforcement: db.BanEnforcementMessages}) || !deletesMessages(db.Ban{}) {
+		t.Error("Баны удалением сообщений и старые баны без способа применения должны удалять сообщения")
+	}
+	if deletesMessages(db.Ban{Enforcement: db.BanEnforcementGuildBan}) || deletesMessages(db.Ban{Enforcement: db.BanEnforcementTimeout}) {
+		t.Error("Бан на сервере и тайм-аут не должны удалять сообщения")
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"discord-bot/config"
+	"discord-bot/db"
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Действия ступеней лестницы наказаний
+const (
+	escalationTimeout = "timeout"
+	escalationKick    = "kick"
+	escalationBan     = "ban"
+)
+
+// defaultEscalationBan - длительность бана по умолчанию при достижении report_threshold предупреждений
+const defaultEscalationBan = "7d"
+
+// escalationSteps возвращает лестницу наказаний из настроек. Если лестница не настроена,
+// пользователь банится на 7 дней при достижении report_threshold предупреждений
+func escalationSteps() []config.EscalationStep {
+	if len(cfg.Escalation) > 0 {
+		return cfg.Escalation
+	}
+	if cfg.ReportThreshold <= 0 {
+		return nil
+	}
+	return []config.EscalationStep{{Warnings: cfg.ReportThreshold, Action: escalationBan, Duration: defaultEscalationBan}}
+}
+
+// escalationStep возвращает ступень, срабатывающую ровно при данном числе предупреждений.
+// Поэтому каждая ступень применяется один раз, а не при каждом следующем предупреждении
+func escalationStep(warnings int) *config.EscalationStep {
+	steps := escalationSteps()
+	for n := range steps {
+		if steps[n].Warnings == warnings {
+			return &steps[n]
+		}
+	}
+	return nil
+}
+
+// escalateWarnings применяет ступень лестницы наказаний, соответствующую числу предупреждений
+// пользователя. Возвращает сообщение для модератора (пусто - ступень не сработала)
+func escalateWarnings(s *discordgo.Session, guildID, userID string, warnings int) string {
+	step := escalationStep(warnings)
+	if step == nil {
+		return ""
+	}
+
+	var duration *time.Duration
+	if step.Duration != "" {
+		var err error
+		if duration, err = parseBanDuration(step.Duration); err != nil {
+			fmt.Printf("Неверная длительность ступени наказания за %d предупреждений %q: %v\n", warnings, step.Duration, err)
+			return ""
+		}
+	}
+
+	c := db.ModerationCase{
+		GuildID:     guildID,
+		UserID:      userID,
+		ModeratorID: s.State.User.ID,
+		Reason:      localization.GetText("escalation_reason", warnings),
+		Duration:    duration,
+	}
+	applied := localization.GetText("escalation_applied", userID, warnings, escalationActionText(step))
+
+	var err error
+	switch step.Action {
+	case escalationTimeout:
+		if duration == nil || *duration > maxTimeoutDuration {
+			fmt.Printf("Тайм-аут за %d предупреждений требует длительность до 28 дней\n", warnings)
+			return ""
+		}
+		c.Action = db.CaseTimeout
+		c, err = applyModAction(s, c)
+	case escalationKick:
+		c.Action = db.CaseKick
+		c, err = applyModAction(s, c)
+	case escalationBan:
+		var fallback error
+		c, fallback, err = enforceBan(s, c)
+		if err == nil && fallback != nil {
+			return applied + caseSuffix(c) + "\n" + banFallbackText(fallback)
+		}
+	default:
+		fmt.Printf("Неизвестное действие ступени наказания: %q\n", step.Action)
+		return ""
+	}
+
+	if err != nil {
+		fmt.Printf("Ошибка автоматического наказания пользователя %s: %v\n", userID, err)
+		return modActionErrorText("escalation_error", err)
+	}
+	return applied + caseSuffix(c)
+}
+
+// escalationActionText возвращает локализованное описание действия ступени с длительностью
+func escalationActionText(step *config.EscalationStep) string {
+	text := localization.GetText("escalation_action_" + step.Action)
+	if step.Duration != "" {
+		text += " (" + step.Duration + ")"
+	}
+	return text
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"testing"
+
+	"discord-bot/config"
+)
+
+func TestEscalationStepDefault(t *testing.T) {
+	savedSteps, savedThreshold := cfg.Escalation, cfg.ReportThreshold
+	defer func() { cfg.Escalation, cfg.ReportThreshold = savedSteps, savedThreshold }()
+
+	cfg.Escalation = nil
+	cfg.ReportThreshold = 3
+
+	step := escalationStep(3)
+	if step == nil || step.Action != escalationBan || step.Duration != defaultEscalationBan {
+		t.Fatalf("Без лестницы ожидался бан на 7 дней при report_threshold предупреждений, получено %+v", step)
+	}
+	if escalationStep(2) != nil || escalationStep(4) != nil {
+		t.Error("Ступень по умолчанию должна срабатывать только при report_threshold предупреждений")
+	}
+
+	cfg.ReportThreshold = 0
+	if escalationStep(0) != nil {
+		t.Error("Без report_threshold автоматических наказаний быть не должно")
+	}
+}
+
+func TestEscalationStepLadder(t *testing.T) {
+	saved := cfg.Escalation
+	defer func() { cfg.Escalation = saved }()
+
+	cfg.Escalation = []config.EscalationStep{
+		{Warnings: 3, Action: escalationTimeout, Duration: "1h"},
+		{Warnings: 5, Action: escalationBan},
+	}
+
+	if step := escalationStep(3); step == nil || step.Action != escalationTimeout {
+		t.Errorf("При 3 предупреждениях ожидался тайм-аут, получено %+v", step)
+	}
+	if step := escalationStep(5); step == nil || step.Action != escalationBan {
+		t.Errorf("При 5 предупреждениях ожидался бан, получено %+v", step)
+	}
+	if step := escalationStep(4); step != nil {
+		t.Errorf("При 4 предупреждениях ступень не должна срабатывать, получено %+v", step)
+	}
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"discord-bot/config"
 	"discord-bot/db"
@@ -15,17 +17,11 @@ import (
 
 var cfg *config.Config
 
-func init() {
-	var err error
-	cfg, err = config.Load()
-	if err != nil {
-		fmt.Println("Ошибка загрузки конфигурации в обработчиках:", err)
-	}
-
-	// Инициализируем систему локализации
-	if err := localization.Initialize(); err != nil {
-		fmt.Println("Ошибка инициализации системы локализации:", err)
-	}
+// Init передает обработчикам загруженную конфигурацию бота. Вызывается при запуске до регистрации
+// обработчиков: загрузка при инициализации пакета создавала config.json и файлы локализации
+// в текущей директории, в том числе в директории пакета при запуске тестов
+func Init(c *config.Config) {
+	cfg = c
 }
 
 // MessageCreate обрабатывает входящие сообщения
@@ -36,8 +32,8 @@ func MessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return
 	}
 
-	// Проверяем, забанен ли пользователь
-	banned, err := db.IsUserBanned(m.Author.ID)
+	// Проверяем, действует ли на сервере бан пользователя, применяемый удалением сообщений
+	banned, err := messagesBanned(m.GuildID, m.Author.ID)
 	if err != nil {
 		fmt.Println("Ошибка при проверке бана:", err)
 	}
@@ -50,8 +46,13 @@ func MessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return
 	}
 
+	// Проверяем содержимое сообщения AI классификатором, если модерация включена для канала
+	ModerateMessage(s, m)
+
 	// Проверяем, начинается ли сообщение с префикса команды
 	if !strings.HasPrefix(m.Content, cfg.Prefix) {
+		// Ответ на сообщение AI продолжает диалог
+		HandleAIReply(s, m)
 		return
 	}
 
@@ -59,269 +60,183 @@ func MessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 	args := strings.Split(strings.TrimPrefix(m.Content, cfg.Prefix), " ")
 	command := strings.ToLower(args[0])
 
-	// Обработка команд
-	switch command {
-	case "report":
-		handleReportCommand(s, m, args[1:])
-	case "ban":
-		handleBanCommand(s, m, args[1:])
-	case "help":
-		HandleHelpCommand(s, m)
-	case "ai":
-		HandleAICommand(s, m, args[1:])
-	case "gemini":
-		HandleAICommand(s, m, append([]string{"gemini"}, args[1:]...))
-	case "grok":
-		HandleAICommand(s, m, append([]string{"grok"}, args[1:]...))
-	case "chatgpt":
-		HandleAICommand(s, m, append([]string{"chatgpt"}, args[1:]...))
-	case "qwen":
-		HandleAICommand(s, m, append([]string{"qwen"}, args[1:]...))
-	case "claude":
-		HandleAICommand(s, m, append([]string{"claude"}, args[1:]...))
-	case "language", "lang":
-		HandleLanguageCommand(s, m, args[1:])
-	case "play":
-		HandlePlayCommand(s, m, args[1:])
-	case "stop":
-		HandleStopCommand(s, m)
-	case "leave":
-		HandleLeaveCommand(s, m)
-	case "nickname", "nick":
-		HandleNicknameCommand(s, m, args[1:])
-	case "dm", "message":
-		HandleDMCommand(s, m, args[1:])
-	}
+	// Выполняем команду из реестра
+	dispatchPrefixCommand(s, m, command, args[1:])
 }
 
-// ReactionAdd обрабатывает добавление реакций
-func ReactionAdd(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
-	// Игнорируем реакции от самого бота
-	if r.UserID == s.State.User.ID {
-		return
-	}
-
-	// Проверяем, является ли сообщение репортом
-	reportMsg, exists := reports.GetReportMessage(r.MessageID)
-	if !exists {
+// handleReportCommand обрабатывает команду репорта: создание репорта или просмотр (report view ID)
+func handleReportCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
+	if len(args) > 0 && strings.EqualFold(args[0], reportViewSubcommand) {
+		reportID, err := strconv.ParseInt(strings.TrimPrefix(strings.Join(args[1:], ""), "#"), 10, 64)
+		if err != nil || reportID <= 0 {
+			reply.Reply(localization.GetText("report_usage", cfg.Prefix))
+			return
+		}
+		viewReport(reply, m.GuildID, memberRoles(m.Member), reportID)
 		return
 	}
 
-	// Проверяем, имеет ли пользователь права администратора или модератора
-	member, err := s.GuildMember(r.GuildID, r.UserID)
-	if err != nil {
-		fmt.Println("Ошибка при получении информации о пользователе:", err)
+	if len(args) < 2 {
+		reply.Reply(localization.GetText("report_usage", cfg.Prefix))
 		return
 	}
 
-	isAdmin := false
-	for _, roleID := range member.Roles {
-		if roleID == cfg.AdminRoleID || roleID == cfg.ModRoleID {
-			isAdmin = true
-			break
-		}
-	}
+	// Извлекаем ID пользователя из упоминания
+	userID := extractUserID(args[0])
+	reason := strings.Join(args[1:], " ")
 
-	if !isAdmin {
-		return
+	// Команду видят все участники канала, включая обвиняемого, поэтому она удаляется,
+	// а подтверждение отправляется автору в личные сообщения
+	if err := s.ChannelMessageDelete(m.ChannelID, m.ID); err != nil {
+		fmt.Printf("Ошибка удаления команды репорта: %v\n", err)
 	}
+	private := &directResponder{s: s, userID: m.Author.ID, channel: *reply}
 
-	// Обрабатываем реакции на репорт
-	switch r.Emoji.Name {
-	case "✅": // Подтверждение репорта
-		handleReportConfirmation(s, r, reportMsg)
-	case "❌": // Отклонение репорта
-		handleReportRejection(s, r, reportMsg)
-	}
+	reportUser(s, private, m.GuildID, m.ChannelID, m.Author.ID, userID, reason)
 }
 
-// handleReportCommand обрабатывает команду репорта
-func handleReportCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	if len(args) < 2 {
-		s.ChannelMessageSend(m.ChannelID, localization.GetText("report_usage", cfg.Prefix))
+// handleReportInteraction обрабатывает слеш-команду /report с подкомандами create и view
+func handleReportInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	subcommand, options := subcommandOptions(i)
+	reply := &interactionResponder{s: s, i: i}
+
+	if subcommand == reportViewSubcommand {
+		viewReport(reply, i.GuildID, memberRoles(i.Member), intOption(options, reportIDOption))
 		return
 	}
+	reportUser(s, reply, i.GuildID, i.ChannelID, interactionUserID(i), idOption(options, userOption), stringOption(options, reasonOption))
+}
 
-	// Извлекаем ID пользователя из упоминания
-	userID := strings.Trim(args[0], "<@!>")
-	reason := strings.Join(args[1:], " ")
+// reportUser создает репорт в очереди модерации сервера и сообщает автору его номер.
+// Если очередь не настроена, репорт отправляется в канал команды
+func reportUser(s *discordgo.Session, reply responder, guildID, channelID, reporterID, userID, reason string) {
+	// Создание репорта требует нескольких запросов к Discord
+	reply.Defer(true)
 
-	// Создаем репорт
-	reportID, err := reports.CreateReport(s, m.ChannelID, userID, m.Author.ID, reason)
+	queueID := reportQueueChannel(guildID, channelID)
+	reportID, err := reports.CreateReport(s, guildID, queueID, userID, reporterID, reason)
 	if err != nil {
-		s.ChannelMessageSend(m.ChannelID, localization.GetText("report_error", err.Error()))
+		reply.Private(localization.GetText("report_error", err.Error()))
 		return
 	}
 
-	s.ChannelMessageSend(m.ChannelID, localization.GetText("report_created", reportID))
+	reply.Private(localization.GetText("report_created", reportID))
 }
 
-// handleBanCommand обрабатывает команду бана
+// handleBanCommand обрабатывает команду бана. Права модератора проверяются реестром команд
 func handleBanCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	// Проверяем права пользователя
-	member, err := s.GuildMember(m.GuildID, m.Author.ID)
-	if err != nil {
-		s.ChannelMessageSend(m.ChannelID, "Ошибка при получении информации о пользователе.")
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
+	if len(args) == 2 && strings.EqualFold(args[0], banInfoSubcommand) {
+		banInfo(reply, m.GuildID, extractUserID(args[1]))
 		return
 	}
-
-	isAdmin := false
-	for _, roleID := range member.Roles {
-		if roleID == cfg.AdminRoleID || roleID == cfg.ModRoleID {
-			isAdmin = true
-			break
-		}
-	}
-
-	if !isAdmin {
-		s.ChannelMessageSend(m.ChannelID, localization.GetText("ban_no_permission"))
-		return
-	}
-
 	if len(args) < 2 {
-		s.ChannelMessageSend(m.ChannelID, localization.GetText("ban_usage", cfg.Prefix))
+		reply.Reply(localization.GetText("ban_usage", cfg.Prefix))
 		return
 	}
 
 	// Извлекаем ID пользователя из упоминания
-	userID := strings.Trim(args[0], "<@!>")
-
-	// Определяем причину и длительность
-	var duration *time.Duration
-	reason := strings.Join(args[1:], " ")
-
-	// Если указана длительность
-	if len(args) > 2 && (strings.HasSuffix(args[len(args)-1], "d") ||
-		strings.HasSuffix(args[len(args)-1], "h") ||
-		strings.HasSuffix(args[len(args)-1], "m")) {
-
-		durationStr := args[len(args)-1]
-		reason = strings.Join(args[1:len(args)-1], " ")
+	userID := extractUserID(args[0])
 
-		// Парсим длительность
-		dur, err := time.ParseDuration(durationStr)
-		if err == nil {
-			duration = &dur
-		}
-	}
-
-	// Баним пользователя
-	err = db.AddBan(userID, reason, m.Author.ID, duration)
+	words := strings.Fields(strings.Join(args[1:], " "))
+	reason, duration, err := splitBanDuration(words)
 	if err != nil {
-		s.ChannelMessageSend(m.ChannelID, localization.GetText("ban_error", err.Error()))
+		reply.Reply(durationErrorText(words[len(words)-1], err))
 		return
 	}
-
-	// Отправляем сообщение о бане
-	durationText := localization.GetText("ban_duration_forever")
-	if duration != nil {
-		durationText = localization.GetText("ban_duration_for", duration.String())
+	if reason == "" {
+		reply.Reply(localization.GetText("ban_usage", cfg.Prefix))
+		return
 	}
 
-	s.ChannelMessageSend(m.ChannelID, localization.GetText("ban_success", userID, durationText, reason))
+	banUser(s, reply, db.ModerationCase{
+		GuildID:     m.GuildID,
+		UserID:      userID,
+		ModeratorID: m.Author.ID,
+		Reason:      reason,
+		Duration:    duration,
+		Evidence:    messageEvidence(m.Message),
+	})
 }
 
-// Используем новый обработчик команды help из help_handler.go
+// handleBanInteraction обрабатывает слеш-команду /ban
+func handleBanInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	subcommand, options := subcommandOptions(i)
+	reply := &interactionResponder{s: s, i: i}
 
-// handleReportConfirmation обрабатывает подтверждение репорта
-func handleReportConfirmation(s *discordgo.Session, r *discordgo.MessageReactionAdd, reportMsg reports.ReportMessage) {
-	// Подтверждаем репорт в базе данных
-	err := db.ConfirmReport(reportMsg.ReportID, r.UserID)
-	if err != nil {
-		fmt.Println("Ошибка при подтверждении репорта:", err)
+	if subcommand == banInfoSubcommand {
+		banInfo(reply, i.GuildID, idOption(options, userOption))
 		return
 	}
 
-	// Получаем количество подтвержденных репортов
-	count, err := db.GetReportCount(reportMsg.ReportedUserID)
-	if err != nil {
-		fmt.Println("Ошибка при получении количества репортов:", err)
-		return
+	var duration *time.Duration
+	if value := stringOption(options, durationOption); value != "" {
+		var err error
+		if duration, err = parseBanDuration(value); err != nil {
+			reply.Private(durationErrorText(value, err))
+			return
+		}
 	}
 
-	// Обновляем сообщение репорта
-	embed := &discordgo.MessageEmbed{
-		Title: fmt.Sprintf("Репорт #%d (Подтвержден)", reportMsg.ReportID),
-		Color: 0x00FF00,
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:  "Пользователь",
-				Value: fmt.Sprintf("<@%s>", reportMsg.ReportedUserID),
-			},
-			{
-				Name:  "Причина",
-				Value: reportMsg.Reason,
-			},
-			{
-				Name:  "Отправитель",
-				Value: fmt.Sprintf("<@%s>", reportMsg.ReporterID),
-			},
-			{
-				Name:  "Подтвержден",
-				Value: fmt.Sprintf("<@%s>", r.UserID),
-			},
-			{
-				Name:  "Всего репортов",
-				Value: fmt.Sprintf("%d/%d", count, cfg.ReportThreshold),
-			},
-		},
-		Timestamp: time.Now().Format(time.RFC3339),
-	}
+	banUser(s, reply, db.ModerationCase{
+		GuildID:     i.GuildID,
+		UserID:      idOption(options, userOption),
+		ModeratorID: interactionUserID(i),
+		Reason:      stringOption(options, reasonOption),
+		Duration:    duration,
+	})
+}
 
-	if _, err := s.ChannelMessageEditEmbed(r.ChannelID, r.MessageID, embed); err != nil {
-		fmt.Printf("Ошибка при редактировании сообщения: %v\n", err)
+// splitBanDuration отделяет длительность от причины бана в аргументах текстовой команды.
+// Длительностью считаются последние слова, если они ее описывают: "спам 7d", "спам 1 day",
+// "спам permanent". Если последнее слово начинается с цифры, но не разбирается как длительность,
+// возвращается ошибка, чтобы опечатка не превратила временный бан в бессрочный. Число без единицы
+// после слов причины считается частью причины. Пустая причина означает, что аргументы пусты
+// или содержат только длительность
+func splitBanDuration(args []string) (string, *time.Duration, error) {
+	// Пустые слова появляются в аргументах при нескольких пробелах подряд или в конце команды
+	args = strings.Fields(strings.Join(args, " "))
+	if len(args) == 0 {
+		return "", nil, nil
 	}
 
-	// Если достигнут порог репортов, баним пользователя
-	if count >= cfg.ReportThreshold {
-		reason := fmt.Sprintf("Автоматический бан по достижению порога репортов (%d)", cfg.ReportThreshold)
-		var duration time.Duration = 7 * 24 * time.Hour // Бан на 7 дней
-
-		err := db.AddBan(reportMsg.ReportedUserID, reason, s.State.User.ID, &duration)
-		if err != nil {
-			fmt.Println("Ошибка при автоматическом бане:", err)
-			return
+	var duration *time.Duration
+	words := durationWords(args, false, func(value string) (err error) {
+		duration, err = parseBanDuration(value)
+		return err
+	})
+	if words > 0 {
+		return strings.Join(args[:len(args)-words], " "), duration, nil
+	}
+
+	last := args[len(args)-1]
+	if unicode.IsDigit([]rune(last)[0]) {
+		// Число без единицы после слов причины относится к причине: "нарушил правило 3"
+		if _, err := strconv.ParseFloat(last, 64); err == nil && len(args) > 1 {
+			return strings.Join(args, " "), nil, nil
 		}
-
-		// Отправляем сообщение о бане
-		s.ChannelMessageSend(r.ChannelID, fmt.Sprintf(
-			"Пользователь <@%s> автоматически забанен на 7 дней по достижению порога репортов (%d).",
-			reportMsg.ReportedUserID, cfg.ReportThreshold,
-		))
+		_, err := parseBanDuration(last)
+		return "", nil, err
 	}
+	return strings.Join(args, " "), nil, nil
 }
 
-// handleReportRejection обрабатывает отклонение репорта
-func handleReportRejection(s *discordgo.Session, r *discordgo.MessageReactionAdd, reportMsg reports.ReportMessage) {
-	// Обновляем сообщение репорта
-	embed := &discordgo.MessageEmbed{
-		Title: fmt.Sprintf("Репорт #%d (Отклонен)", reportMsg.ReportID),
-		Color: 0xFF0000,
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:  "Пользователь",
-				Value: fmt.Sprintf("<@%s>", reportMsg.ReportedUserID),
-			},
-			{
-				Name:  "Причина",
-				Value: reportMsg.Reason,
-			},
-			{
-				Name:  "Отправитель",
-				Value: fmt.Sprintf("<@%s>", reportMsg.ReporterID),
-			},
-			{
-				Name:  "Отклонен",
-				Value: fmt.Sprintf("<@%s>", r.UserID),
-			},
-		},
-		Timestamp: time.Now().Format(time.RFC3339),
+// banUser банит пользователя и сообщает об этом в канал. Бан записывается как модерационный случай
+func banUser(s *discordgo.Session, reply responder, c db.ModerationCase) {
+	c, fallback, err := enforceBan(s, c)
+	if err != nil {
+		reply.Private(localization.GetText("ban_error", err.Error()))
+		return
+	}
+	if fallback != nil {
+		reply.Private(banFallbackText(fallback))
 	}
 
-	if _, err := s.ChannelMessageEditEmbed(r.ChannelID, r.MessageID, embed); err != nil {
-		fmt.Printf("Ошибка при редактировании сообщения: %v\n", err)
+	durationText := localization.GetText("ban_duration_forever")
+	if c.Duration != nil {
+		durationText = localization.GetText("ban_duration_for", formatDuration(*c.Duration))
 	}
 
-	// Удаляем репорт из кэша
-	reports.RemoveReportMessage(r.MessageID)
+	reply.Reply(localization.GetText("ban_success", c.UserID, durationText, c.Reason) + caseSuffix(c))
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"discord-bot/localization"
@@ -9,6 +10,9 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
+// maxEmbedFieldValue - максимальная длина значения поля эмбеда в Discord
+const maxEmbedFieldValue = 1024
+
 // HandleHelpCommand обрабатывает команду /help и отображает информацию о командах через вебхук
 func HandleHelpCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
 	// Создаем вебхук в текущем канале
@@ -46,61 +50,39 @@ func HandleHelpCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
 	}
 }
 
-// createHelpEmbed создает эмбед с информацией о командах
+// createHelpEmbed создает эмбед с информацией о командах из реестра, сгруппированных по категориям
 func createHelpEmbed() *discordgo.MessageEmbed {
+	var fields []*discordgo.MessageEmbedField
+	for _, category := range Categories {
+		var lines []string
+		for _, cmd := range Commands() {
+			if cmd.Category != category {
+				continue
+			}
+			line := fmt.Sprintf("`%s` - %s", cmd.Usage(cfg.Prefix), cmd.Description())
+			if cmd.Permission == PermissionModerator {
+				line += " " + localization.GetText("help_moderator_only")
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		// Значение поля эмбеда ограничено, длинные категории разбиваются на несколько полей
+		for n, chunk := range splitMessage(strings.Join(lines, "\n"), maxEmbedFieldValue) {
+			name := category.Name()
+			if n > 0 {
+				name += " " + localization.GetText("help_continued")
+			}
+			fields = append(fields, &discordgo.MessageEmbedField{Name: name, Value: chunk})
+		}
+	}
+
 	return &discordgo.MessageEmbed{
-		Title: localization.GetText("help_title"),
-		Color: 0x00BFFF,
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:  fmt.Sprintf("%sreport @пользователь причина", cfg.Prefix),
-				Value: localization.GetText("report_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%sban @пользователь причина [длительность]", cfg.Prefix),
-				Value: localization.GetText("ban_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%sai ваш запрос", cfg.Prefix),
-				Value: localization.GetText("ai_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%sgemini ваш запрос", cfg.Prefix),
-				Value: localization.GetText("gemini_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%sgrok ваш запрос", cfg.Prefix),
-				Value: localization.GetText("grok_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%schatgpt ваш запрос", cfg.Prefix),
-				Value: localization.GetText("chatgpt_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%sqwen ваш запрос", cfg.Prefix),
-				Value: localization.GetText("qwen_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%sclaude ваш запрос", cfg.Prefix),
-				Value: localization.GetText("claude_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%shelp", cfg.Prefix),
-				Value: localization.GetText("help_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%slanguage [ru|en|uk|de|zh]", cfg.Prefix),
-				Value: localization.GetText("language_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%splay URL-YouTube", cfg.Prefix),
-				Value: localization.GetText("play_command_desc"),
-			},
-			{
-				Name:  fmt.Sprintf("%sstop", cfg.Prefix),
-				Value: localization.GetText("stop_command_desc"),
-			},
-		},
+		Title:  localization.GetText("help_title"),
+		Color:  0x00BFFF,
+		Fields: fields,
 		Footer: &discordgo.MessageEmbedFooter{
 			Text: "Lapidar Bot",
 		},
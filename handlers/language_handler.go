@@ -8,21 +8,39 @@ import (
 
 // HandleLanguageCommand обрабатывает команду смены языка
 func HandleLanguageCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
+
 	// Проверяем, что пользователь указал язык
 	if len(args) < 1 {
-		s.ChannelMessageSend(m.ChannelID, localization.GetText("language_usage", cfg.Prefix))
+		reply.Reply(localization.GetText("language_usage", cfg.Prefix))
 		return
 	}
 
-	// Получаем код языка
-	langCode := args[0]
+	changeLanguage(reply, args[0])
+}
+
+// handleLanguageInteraction обрабатывает слеш-команду /language
+func handleLanguageInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	changeLanguage(&interactionResponder{s: s, i: i}, stringOption(interactionOptions(i), languageOption))
+}
 
-	// Устанавливаем новый язык
+// changeLanguage устанавливает язык бота
+func changeLanguage(reply responder, langCode string) {
 	if success := localization.SetLanguage(langCode); !success {
-		s.ChannelMessageSend(m.ChannelID, localization.GetText("language_invalid"))
+		reply.Private(localization.GetText("language_invalid"))
 		return
 	}
 
-	// Отправляем сообщение об успешной смене языка
-	s.ChannelMessageSend(m.ChannelID, localization.GetText("language_changed"))
+	// Сообщение об успешной смене языка отправляется уже на новом языке
+	reply.Reply(localization.GetText("language_changed"))
+}
+
+// languageChoices возвращает варианты параметра языка для слеш-команды
+func languageChoices() []*discordgo.ApplicationCommandOptionChoice {
+	languages := localization.GetAvailableLanguages()
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(languages))
+	for _, lang := range languages {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: lang, Value: lang})
+	}
+	return choices
 }
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"os"
+	"testing"
+
+	"discord-bot/config"
+)
+
+// TestMain задает конфигурацию по умолчанию вместо загрузки config.json, чтобы тесты
+// не создавали файлы в директории пакета
+func TestMain(m *testing.M) {
+	cfg = &config.Config{Prefix: "/", ReportThreshold: 3, DefaultLanguage: "ru"}
+	os.Exit(m.Run())
+}
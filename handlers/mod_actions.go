@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"discord-bot/db"
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleWarnCommand обрабатывает команду предупреждения (warn @пользователь причина)
+func handleWarnCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
+	if len(args) < 2 {
+		reply.Reply(localization.GetText("warn_usage", cfg.Prefix))
+		return
+	}
+
+	warnUser(s, reply, db.ModerationCase{
+		GuildID:     m.GuildID,
+		UserID:      extractUserID(args[0]),
+		ModeratorID: m.Author.ID,
+		Reason:      strings.Join(args[1:], " "),
+		Evidence:    messageEvidence(m.Message),
+	})
+}
+
+// handleWarnInteraction обрабатывает слеш-команду /warn
+func handleWarnInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := interactionOptions(i)
+	reply := &interactionResponder{s: s, i: i}
+
+	warnUser(s, reply, db.ModerationCase{
+		GuildID:     i.GuildID,
+		UserID:      idOption(options, userOption),
+		ModeratorID: interactionUserID(i),
+		Reason:      stringOption(options, reasonOption),
+	})
+}
+
+// warnUser выносит пользователю предупреждение и применяет ступень лестницы наказаний,
+// если число предупреждений ее достигло
+func warnUser(s *discordgo.Session, reply responder, c db.ModerationCase) {
+	c.Action = db.CaseWarn
+	c, err := applyModAction(s, c)
+	if err != nil {
+		reply.Private(localization.GetText("warn_error", err.Error()))
+		return
+	}
+
+	warnings, err := countWarnings(c.GuildID, c.UserID)
+	if err != nil {
+		fmt.Printf("Ошибка подсчета предупреждений: %v\n", err)
+	}
+	reply.Reply(localization.GetText("warn_success", c.UserID, warnings, c.Reason) + caseSuffix(c))
+
+	if text := escalateWarnings(s, c.GuildID, c.UserID, warnings); text != "" {
+		reply.Private(text)
+	}
+}
+
+// warningActions - действия случаев, которые считаются предупреждениями лестницы наказаний
+var warningActions = []db.CaseAction{db.CaseWarn, db.CaseReportConfirm}
+
+// countWarnings возвращает количество предупреждений пользователя на сервере, включая подтвержденные репорты
+func countWarnings(guildID, userID string) (int, error) {
+	total := 0
+	for _, action := range warningActions {
+		count, err := db.CountCases(guildID, userID, action)
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// handleTimeoutCommand обрабатывает команду тайм-аута (timeout @пользователь длительность причина)
+func handleTimeoutCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
+	// Пустые слова появляются в аргументах при нескольких пробелах подряд
+	args = strings.Fields(strings.Join(args, " "))
+	if len(args) < 3 {
+		reply.Reply(localization.GetText("timeout_usage", cfg.Prefix))
+		return
+	}
+
+	duration, reason, err := splitTimeoutDuration(args[1:])
+	if err != nil {
+		reply.Reply(durationErrorText(args[1], err))
+		return
+	}
+	if reason == "" {
+		reply.Reply(localization.GetText("timeout_usage", cfg.Prefix))
+		return
+	}
+
+	timeoutUser(s, reply, db.ModerationCase{
+		GuildID:     m.GuildID,
+		UserID:      extractUserID(args[0]),
+		ModeratorID: m.Author.ID,
+		Reason:      reason,
+		Duration:    &duration,
+		Evidence:    messageEvidence(m.Message),
+	})
+}
+
+// splitTimeoutDuration отделяет длительность в начале аргументов текстовой команды от причины
+// тайм-аута. Длительность может состоять из нескольких слов: "1 day спам", "2 hours 30 min спам"
+func splitTimeoutDuration(args []string) (time.Duration, string, error) {
+	if len(args) == 0 {
+		return 0, "", errDurationFormat
+	}
+
+	var duration time.Duration
+	words := durationWords(args, true, func(value string) (err error) {
+		duration, err = parseDuration(value)
+		return err
+	})
+	if words == 0 {
+		_, err := parseDuration(args[0])
+		return 0, "", err
+	}
+	return duration, strings.Join(args[words:], " "), nil
+}
+
+// handleTimeoutInteraction обрабатывает слеш-команду /timeout
+func handleTimeoutInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := interactionOptions(i)
+	reply := &interactionResponder{s: s, i: i}
+
+	value := stringOption(options, durationOption)
+	duration, err := parseDuration(value)
+	if err != nil {
+		reply.Private(durationErrorText(value, err))
+		return
+	}
+
+	timeoutUser(s, reply, db.ModerationCase{
+		GuildID:     i.GuildID,
+		UserID:      idOption(options, userOption),
+		ModeratorID: interactionUserID(i),
+		Reason:      stringOption(options, reasonOption),
+		Duration:    &duration,
+	})
+}
+
+// timeoutUser выдает пользователю тайм-аут в Discord. Тайм-аут не может быть длиннее 28 дней
+func timeoutUser(s *discordgo.Session, reply responder, c db.ModerationCase) {
+	if *c.Duration > maxTimeoutDuration {
+		reply.Private(localization.GetText("timeout_too_long"))
+		return
+	}
+
+	c.Action = db.CaseTimeout
+	c, err := applyModAction(s, c)
+	if err != nil {
+		reply.Private(modActionErrorText("timeout_error", err))
+		return
+	}
+	reply.Reply(localization.GetText("timeout_success", c.UserID, formatDuration(*c.Duration), c.Reason) + caseSuffix(c))
+}
+
+// handleKickCommand обрабатывает команду исключения с сервера (kick @пользователь причина)
+func handleKickCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
+	if len(args) < 2 {
+		reply.Reply(localization.GetText("kick_usage", cfg.Prefix))
+		return
+	}
+
+	kickUser(s, reply, db.ModerationCase{
+		GuildID:     m.GuildID,
+		UserID:      extractUserID(args[0]),
+		ModeratorID: m.Author.ID,
+		Reason:      strings.Join(args[1:], " "),
+		Evidence:    messageEvidence(m.Message),
+	})
+}
+
+// handleKickInteraction обрабатывает слеш-команду /kick
+func handleKickInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := interactionOptions(i)
+	reply := &interactionResponder{s: s, i: i}
+
+	kickUser(s, reply, db.ModerationCase{
+		GuildID:     i.GuildID,
+		UserID:      idOption(options, userOption),
+		ModeratorID: interactionUserID(i),
+		Reason:      stringOption(options, reasonOption),
+	})
+}
+
+// kickUser исключает пользователя с сервера
+func kickUser(s *discordgo.Session, reply responder, c db.ModerationCase) {
+	c.Action = db.CaseKick
+	c, err := applyModAction(s, c)
+	if err != nil {
+		reply.Private(modActionErrorText("kick_error", err))
+		return
+	}
+	reply.Reply(localization.GetText("kick_success", c.UserID, c.Reason) + caseSuffix(c))
+}
+
+// applyModAction применяет предупреждение, тайм-аут или кик из c.Action и записывает модерационный случай.
+// Возвращает случай с присвоенным номером
+func applyModAction(s *discordgo.Session, c db.ModerationCase) (db.ModerationCase, error) {
+	auditReason := discordgo.WithAuditLogReason(truncateRunes(c.Reason, maxAuditLogReason))
+
+	switch c.Action {
+	case db.CaseTimeout:
+		until := time.Now().Add(*c.Duration)
+		if err := s.GuildMemberTimeout(c.GuildID, c.UserID, &until, auditReason); err != nil {
+			return c, fmt.Errorf("не удалось выдать тайм-аут: %w", err)
+		}
+	case db.CaseKick:
+		if err := s.GuildMemberDelete(c.GuildID, c.UserID, auditReason); err != nil {
+			return c, fmt.Errorf("не удалось исключить с сервера: %w", err)
+		}
+	}
+
+	// Тайм-аут и кик уже применены в Discord, поэтому ошибка сохранения случая для них не считается ошибкой действия
+	recorded, err := recordCase(s, c)
+	if err != nil {
+		fmt.Printf("Ошибка сохранения модерационного случая: %v\n", err)
+		if c.Action == db.CaseWarn {
+			return c, err
+		}
+	}
+	return recorded, nil
+}
+
+// modActionErrorText возвращает текст ошибки действия модератора. Для нехватки прав бота
+// выводится понятное сообщение вместо ответа Discord
+func modActionErrorText(key string, err error) string {
+	if isMissingPermissions(err) {
+		return localization.GetText("mod_action_no_permission")
+	}
+	return localization.GetText(key, err.Error())
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"discord-bot/config"
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Действия модераторов, записываемые в журнал
+const (
+	modActionConfirm = "confirm"
+	modActionReject  = "reject"
+	modActionBan     = "ban"
+	modActionUnban   = "unban"
+	modActionWarn    = "warn"
+	modActionTimeout = "timeout"
+	modActionKick    = "kick"
+)
+
+// modActionColors - цвета embed журнала по действиям
+var modActionColors = map[string]int{
+	modActionConfirm: 0x00FF00,
+	modActionReject:  0x808080,
+	modActionBan:     0xFF0000,
+	modActionUnban:   0x3498DB,
+	modActionWarn:    0xFFD700,
+	modActionTimeout: 0xFFA500,
+	modActionKick:    0xE67E22,
+}
+
+// modLogEntry описывает действие модератора для журнала
+type modLogEntry struct {
+	Action      string
+	TargetID    string // Пользователь, к которому применено действие
+	ModeratorID string
+	Reason      string
+	ReportID    int64          // Репорт, по которому выполнено действие (0 - без репорта)
+	Duration    *time.Duration // Длительность бана или тайм-аута (nil - навсегда или не применимо)
+	CaseNumber  int64          // Номер модерационного случая (0 - без случая)
+}
+
+// moderationChannels возвращает каналы модерации сервера.
+// Если для сервера нет настроек, используются настройки default
+func moderationChannels(guildID string) config.ModerationConfig {
+	if settings, ok := cfg.Moderation[guildID]; ok {
+		return settings
+	}
+	return cfg.Moderation[config.DefaultGuildKey]
+}
+
+// reportQueueChannel возвращает канал очереди репортов сервера или fallback, если очередь не настроена
+func reportQueueChannel(guildID, fallback string) string {
+	if channelID := moderationChannels(guildID).QueueChannelID; channelID != "" {
+		return channelID
+	}
+	return fallback
+}
+
+// logModAction отправляет действие модератора в журнал модерации сервера
+func logModAction(s *discordgo.Session, guildID string, entry modLogEntry) {
+	channelID := moderationChannels(guildID).LogChannelID
+	if channelID == "" {
+		return
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(channelID, modLogEmbed(entry)); err != nil {
+		fmt.Printf("Ошибка отправки записи в журнал модерации: %v\n", err)
+	}
+}
+
+// modLogEmbed формирует запись журнала модерации
+func modLogEmbed(entry modLogEntry) *discordgo.MessageEmbed {
+	fields := []*discordgo.MessageEmbedField{
+		{Name: localization.GetText("modlog_field_target"), Value: fmt.Sprintf("<@%s>", entry.TargetID), Inline: true},
+		{Name: localization.GetText("modlog_field_moderator"), Value: fmt.Sprintf("<@%s>", entry.ModeratorID), Inline: true},
+	}
+	if entry.CaseNumber != 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name: localization.GetText("modlog_field_case"), Value: fmt.Sprintf("#%d", entry.CaseNumber), Inline: true,
+		})
+	}
+	if entry.ReportID != 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name: localization.GetText("modlog_field_report"), Value: fmt.Sprintf("#%d", entry.ReportID), Inline: true,
+		})
+	}
+	if entry.Action == modActionBan || entry.Action == modActionTimeout {
+		duration := localization.GetText("modlog_permanent")
+		if entry.Duration != nil {
+			duration = formatDuration(*entry.Duration)
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name: localization.GetText("modlog_field_duration"), Value: duration, Inline: true,
+		})
+	}
+	if entry.Reason != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name: localization.GetText("modlog_field_reason"), Value: truncateRunes(entry.Reason, maxEmbedFieldValue),
+		})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:     localization.GetText("modlog_title_" + entry.Action),
+		Color:     modActionColors[entry.Action],
+		Fields:    fields,
+		Footer:    &discordgo.MessageEmbedFooter{Text: "ID: " + entry.TargetID},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"discord-bot/config"
+)
+
+func TestModerationChannelsFallback(t *testing.T) {
+	saved := cfg.Moderation
+	defer func() { cfg.Moderation = saved }()
+
+	cfg.Moderation = map[string]config.ModerationConfig{
+		config.DefaultGuildKey: {QueueChannelID: "default-queue", LogChannelID: "default-log"},
+		"guild":                {QueueChannelID: "guild-queue"},
+	}
+
+	if got := reportQueueChannel("guild", "command"); got != "guild-queue" {
+		t.Errorf("Ожидалась очередь сервера, получено %q", got)
+	}
+	if got := reportQueueChannel("other", "command"); got != "default-queue" {
+		t.Errorf("Ожидалась очередь default, получено %q", got)
+	}
+	if got := moderationChannels("guild").LogChannelID; got != "" {
+		t.Errorf("Настройки сервера не должны смешиваться с default, получено %q", got)
+	}
+
+	cfg.Moderation = nil
+	if got := reportQueueChannel("guild", "command"); got != "command" {
+		t.Errorf("Без настроек репорт должен оставаться в канале команды, получено %q", got)
+	}
+}
+
+func TestModLogEmbedFields(t *testing.T) {
+	duration := 2 * time.Hour
+	embed := modLogEmbed(modLogEntry{
+		Action:      modActionBan,
+		TargetID:    "target",
+		ModeratorID: "mod",
+		Reason:      "spam",
+		ReportID:    7,
+		Duration:    &duration,
+	})
+
+	if embed.Color != modActionColors[modActionBan] {
+		t.Errorf("Неверный цвет записи: %x", embed.Color)
+	}
+	// Пользователь, модератор, репорт, срок и причина
+	if len(embed.Fields) != 5 {
+		t.Fatalf("Ожидалось 5 полей, получено %d", len(embed.Fields))
+	}
+	if embed.Fields[2].Value != "#7" || embed.Fields[3].Value != formatDuration(duration) || embed.Fields[4].Value != "spam" {
+		t.Errorf("Неверные поля записи: %+v %+v %+v", embed.Fields[2], embed.Fields[3], embed.Fields[4])
+	}
+
+	reject := modLogEmbed(modLogEntry{Action: modActionReject, TargetID: "target", ModeratorID: "mod"})
+	if len(reject.Fields) != 2 {
+		t.Errorf("Запись без репорта и причины должна содержать 2 поля, получено %d", len(reject.Fields))
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import "github.com/bwmarrin/discordgo"
+
+// hasModeratorRole проверяет, есть ли среди ролей роль администратора или модератора
+func hasModeratorRole(roles []string) bool {
+	for _, roleID := range roles {
+		if (cfg.AdminRoleID != "" && roleID == cfg.AdminRoleID) || (cfg.ModRoleID != "" && roleID == cfg.ModRoleID) {
+			return true
+		}
+	}
+	return false
+}
+
+// memberRoles возвращает роли участника сервера (nil для личных сообщений)
+func memberRoles(member *discordgo.Member) []string {
+	if member == nil {
+		return nil
+	}
+	return member.Roles
+}
+
+// interactionUser возвращает пользователя, вызвавшего интеракцию, на сервере или в личных сообщениях
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+// interactionUserID возвращает ID пользователя, вызвавшего интеракцию (пусто, если он неизвестен)
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if user := interactionUser(i); user != nil {
+		return user.ID
+	}
+	return ""
+}
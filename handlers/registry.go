@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Permission - уровень доступа к команде
+type Permission int
+
+const (
+	PermissionEveryone  Permission = iota // Команда доступна всем
+	PermissionModerator                   // Команда доступна модераторам и администраторам
+)
+
+// String возвращает название уровня доступа для API
+func (p Permission) String() string {
+	if p == PermissionModerator {
+		return "moderator"
+	}
+	return "everyone"
+}
+
+// Category - категория команды в справке
+type Category string
+
+// Категории команд в порядке вывода в справке
+const (
+	CategoryGeneral    Category = "general"
+	CategoryModeration Category = "moderation"
+	CategoryAI         Category = "ai"
+	CategoryMusic      Category = "music"
+)
+
+// Categories - порядок категорий в справке
+var Categories = []Category{CategoryGeneral, CategoryModeration, CategoryAI, CategoryMusic}
+
+// Name возвращает локализованное название категории
+func (c Category) Name() string {
+	return localization.GetText("category_" + string(c))
+}
+
+// maxSlashDescription - максимальная длина описания слеш-команды и ее параметров в Discord
+const maxSlashDescription = 100
+
+// discordLocales сопоставляет языки бота с локалями Discord
+var discordLocales = map[string][]discordgo.Locale{
+	localization.Russian:     {discordgo.Russian},
+	localization.English:     {discordgo.EnglishUS, discordgo.EnglishGB},
+	localization.Ukrainian:   {discordgo.Ukrainian},
+	localization.German:      {discordgo.German},
+	localization.ChineseSimp: {discordgo.ChineseCN},
+}
+
+// CommandOption описывает параметр или подкоманду слеш-команды.
+// Название и описание берутся из локализации по ключам <Key>_option_name и <Key>_option_desc
+type CommandOption struct {
+	Name         string // Базовое название параметра (латиницей), по нему обработчик находит значение
+	Key          string // Префикс ключей локализации (пусто - <команда>_<параметр>)
+	Type         discordgo.ApplicationCommandOptionType
+	Required     bool
+	Autocomplete bool
+	MinValue     *float64
+	MaxValue     float64
+	MaxLength    int
+	Choices      []*discordgo.ApplicationCommandOptionChoice
+	ChannelTypes []discordgo.ChannelType // Допустимые типы каналов для параметра-канала
+	Options      []*CommandOption        // Параметры подкоманды
+}
+
+// Command описывает команду бота. Одно описание используется для разбора текстовых команд,
+// регистрации слеш-команды, справки и API веб-интерфейса.
+// Переводы названия слеш-команды и описание команды берутся из локализации
+// по ключам <Name>_command_name и <Name>_command_desc
+type Command struct {
+	Name         string
+	Aliases      []string // Дополнительные названия текстовой команды
+	Category     Category
+	Permission   Permission
+	GuildOnly    bool             // Слеш-команда недоступна в личных сообщениях
+	ArgsKey      string           // Ключ локализации синтаксиса аргументов текстовой команды (пусто - без аргументов)
+	Options      []*CommandOption // Параметры слеш-команды
+	Run          func(s *discordgo.Session, m *discordgo.MessageCreate, args []string)
+	Slash        func(s *discordgo.Session, i *discordgo.InteractionCreate) // nil - команда доступна только с префиксом
+	Autocomplete func(s *discordgo.Session, i *discordgo.InteractionCreate)
+}
+
+// Description возвращает локализованное описание команды
+func (c *Command) Description() string {
+	return localization.GetText(c.Name + "_command_desc")
+}
+
+// Usage возвращает синтаксис текстовой команды с префиксом
+func (c *Command) Usage(prefix string) string {
+	if c.ArgsKey == "" {
+		return prefix + c.Name
+	}
+	return prefix + c.Name + " " + localization.GetText(c.ArgsKey)
+}
+
+// commands - реестр команд бота, заполняется в init
+var commands []*Command
+
+func init() {
+	commands = builtinCommands()
+}
+
+// Commands возвращает зарегистрированные команды
+func Commands() []*Command {
+	return commands
+}
+
+// findCommand ищет команду по названию или псевдониму
+func findCommand(name string) *Command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+		for _, alias := range cmd.Aliases {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// commandAllowed проверяет, может ли пользователь с указанными ролями выполнить команду
+func commandAllowed(cmd *Command, roles []string) bool {
+	return cmd.Permission == PermissionEveryone || hasModeratorRole(roles)
+}
+
+// dispatchPrefixCommand выполняет текстовую команду, если она зарегистрирована
+func dispatchPrefixCommand(s *discordgo.Session, m *discordgo.MessageCreate, name string, args []string) {
+	cmd := findCommand(name)
+	if cmd == nil || cmd.Run == nil {
+		return
+	}
+
+	if !commandAllowed(cmd, memberRoles(m.Member)) {
+		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("no_permission")); err != nil {
+			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
+		}
+		return
+	}
+
+	cmd.Run(s, m, args)
+}
+
+// handleInteraction направляет интеракции слеш-команд и автодополнения в обработчики реестра,
+// а нажатия кнопок и отправку модальных окон репортов - в обработчик рассмотрения репортов
+func handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		cmd := findCommand(i.ApplicationCommandData().Name)
+		if cmd == nil || cmd.Slash == nil {
+			return
+		}
+		if !commandAllowed(cmd, memberRoles(i.Member)) {
+			respondEphemeral(s, i, localization.GetText("no_permission"))
+			return
+		}
+		cmd.Slash(s, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		if cmd := findCommand(i.ApplicationCommandData().Name); cmd != nil && cmd.Autocomplete != nil {
+			cmd.Autocomplete(s, i)
+		}
+	case discordgo.InteractionMessageComponent:
+		handleReportComponent(s, i, i.MessageComponentData().CustomID)
+	case discordgo.InteractionModalSubmit:
+		handleReportComponent(s, i, i.ModalSubmitData().CustomID)
+	}
+}
+
+// respondAutocomplete отправляет варианты автодополнения параметра слеш-команды
+func respondAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, choices []*discordgo.ApplicationCommandOptionChoice) {
+	if len(choices) > maxAutocompleteChoices {
+		choices = choices[:maxAutocompleteChoices]
+	}
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	}); err != nil {
+		fmt.Printf("Ошибка отправки вариантов автодополнения: %v\n", err)
+	}
+}
+
+// Области регистрации слеш-команд
+const (
+	commandScopeGlobal = "global"
+	commandScopeGuild  = "guild"
+)
+
+// InitCommands подключает обработчик интеракций и регистрацию слеш-команд.
+// Команды регистрируются после события Ready, когда известен ID приложения
+func InitCommands(s *discordgo.Session) {
+	s.AddHandler(handleInteraction)
+	s.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
+		if err := registerCommands(s, r); err != nil {
+			fmt.Printf("Ошибка регистрации слеш-команд: %v\n", err)
+		}
+	})
+}
+
+// registerCommands перезаписывает слеш-команды приложения списком из реестра, поэтому команды,
+// удаленные из кода, удаляются и в Discord. В режиме guild команды регистрируются на серверах
+// и обновляются сразу, а глобальные команды удаляются, чтобы не дублировать их.
+// В режиме global удаляются команды серверов, оставшиеся после разработки
+func registerCommands(s *discordgo.Session, r *discordgo.Ready) error {
+	appID := r.User.ID
+	if r.Application != nil && r.Application.ID != "" {
+		appID = r.Application.ID
+	}
+
+	slash := slashCommands()
+	settings := cfg.Commands
+
+	switch settings.Scope {
+	case "", commandScopeGlobal:
+		if _, err := s.ApplicationCommandBulkOverwrite(appID, "", slash); err != nil {
+			return fmt.Errorf("не удалось зарегистрировать глобальные команды: %w", err)
+		}
+		for _, guildID := range settings.GuildIDs {
+			if _, err := s.ApplicationCommandBulkOverwrite(appID, guildID, []*discordgo.ApplicationCommand{}); err != nil {
+				fmt.Printf("Не удалось удалить команды сервера %s: %v\n", guildID, err)
+			}
+		}
+		fmt.Printf("Зарегистрировано глобальных слеш-команд: %d\n", len(slash))
+
+	case commandScopeGuild:
+		guildIDs := settings.GuildIDs
+		if len(guildIDs) == 0 {
+			for _, guild := range r.Guilds {
+				guildIDs = append(guildIDs, guild.ID)
+			}
+		}
+
+		for _, guildID := range guildIDs {
+			if _, err := s.ApplicationCommandBulkOverwrite(appID, guildID, slash); err != nil {
+				fmt.Printf("Не удалось зарегистрировать команды сервера %s: %v\n", guildID, err)
+			}
+		}
+		if _, err := s.ApplicationCommandBulkOverwrite(appID, "", []*discordgo.ApplicationCommand{}); err != nil {
+			return fmt.Errorf("не удалось удалить глобальные команды: %w", err)
+		}
+		fmt.Printf("Зарегистрировано слеш-команд: %d на серверах: %d\n", len(slash), len(guildIDs))
+
+	default:
+		return fmt.Errorf("неизвестная область регистрации команд: '%s' (ожидается global или guild)", settings.Scope)
+	}
+
+	return nil
+}
+
+// slashCommands возвращает описания всех слеш-команд реестра
+func slashCommands() []*discordgo.ApplicationCommand {
+	slash := []*discordgo.ApplicationCommand{}
+	for _, cmd := range commands {
+		if cmd.Slash != nil {
+			slash = append(slash, slashCommand(cmd))
+		}
+	}
+	return slash
+}
+
+// slashCommand строит описание слеш-команды для Discord с переводами на все языки бота
+func slashCommand(cmd *Command) *discordgo.ApplicationCommand {
+	names := localizedTexts(cmd.Name+"_command_name", 0)
+	descriptionKey := cmd.Name + "_command_desc"
+	descriptions := localizedTexts(descriptionKey, maxSlashDescription)
+
+	command := &discordgo.ApplicationCommand{
+		Name:                     cmd.Name,
+		NameLocalizations:        &names,
+		Description:              baseText(descriptionKey, maxSlashDescription),
+		DescriptionLocalizations: &descriptions,
+		Options:                  slashOptions(cmd.Name, cmd.Options),
+	}
+	if cmd.GuildOnly {
+		dmPermission := false
+		command.DMPermission = &dmPermission
+	}
+	return command
+}
+
+// slashOptions строит параметры слеш-команды. parentKey - префикс ключей локализации родителя
+func slashOptions(parentKey string, options []*CommandOption) []*discordgo.ApplicationCommandOption {
+	if len(options) == 0 {
+		return nil
+	}
+
+	result := make([]*discordgo.ApplicationCommandOption, 0, len(options))
+	for _, option := range options {
+		key := option.Key
+		if key == "" {
+			key = parentKey + "_" + option.Name
+		}
+
+		result = append(result, &discordgo.ApplicationCommandOption{
+			Type:                     option.Type,
+			Name:                     option.Name,
+			NameLocalizations:        localizedTexts(key+"_option_name", 0),
+			Description:              baseText(key+"_option_desc", maxSlashDescription),
+			DescriptionLocalizations: localizedTexts(key+"_option_desc", maxSlashDescription),
+			Required:                 option.Required,
+			Autocomplete:             option.Autocomplete,
+			MinValue:                 option.MinValue,
+			MaxValue:                 option.MaxValue,
+			MaxLength:                option.MaxLength,
+			Choices:                  option.Choices,
+			ChannelTypes:             option.ChannelTypes,
+			Options:                  slashOptions(key, option.Options),
+		})
+	}
+	return result
+}
+
+// baseText возвращает английский текст ключа, который Discord показывает для остальных локалей
+func baseText(key string, limit int) string {
+	text, ok := localization.Lookup(localization.English, key)
+	if !ok {
+		text = key
+	}
+	return truncateText(text, limit)
+}
+
+// localizedTexts собирает переводы ключа для локалей Discord. Языки без перевода пропускаются
+func localizedTexts(key string, limit int) map[discordgo.Locale]string {
+	texts := make(map[discordgo.Locale]string)
+	for lang, locales := range discordLocales {
+		text, ok := localization.Lookup(lang, key)
+		if !ok {
+			continue
+		}
+		for _, locale := range locales {
+			texts[locale] = truncateText(text, limit)
+		}
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+	return texts
+}
+
+// truncateText обрезает текст до limit символов (0 - без ограничения)
+func truncateText(text string, limit int) string {
+	text = strings.TrimSpace(text)
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return truncateRunes(text, limit)
+}
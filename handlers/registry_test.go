@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// slashNamePattern - допустимые базовые названия слеш-команд и параметров
+var slashNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+func TestCommandNamesUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, cmd := range Commands() {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			if seen[name] {
+				t.Errorf("Название команды %q зарегистрировано повторно", name)
+			}
+			seen[name] = true
+		}
+		if cmd.Run == nil {
+			t.Errorf("У команды %q нет обработчика текстовой команды", cmd.Name)
+		}
+	}
+}
+
+func TestFindCommandByAlias(t *testing.T) {
+	if cmd := findCommand("lang"); cmd == nil || cmd.Name != "language" {
+		t.Errorf("Псевдоним lang должен указывать на команду language, получено %+v", cmd)
+	}
+	if findCommand("unknown") != nil {
+		t.Error("Неизвестная команда не должна находиться")
+	}
+}
+
+func TestSlashCommandNames(t *testing.T) {
+	var check func(command string, options []*CommandOption)
+	check = func(command string, options []*CommandOption) {
+		for _, option := range options {
+			if !slashNamePattern.MatchString(option.Name) {
+				t.Errorf("Недопустимое название параметра %q команды %s", option.Name, command)
+			}
+			check(command, option.Options)
+		}
+	}
+
+	for _, cmd := range Commands() {
+		if cmd.Slash == nil {
+			continue
+		}
+		if !slashNamePattern.MatchString(cmd.Name) {
+			t.Errorf("Недопустимое название слеш-команды %q", cmd.Name)
+		}
+		check(cmd.Name, cmd.Options)
+
+		if command := slashCommand(cmd); len(command.Options) != len(cmd.Options) {
+			t.Errorf("Команда %s: ожидалось %d параметров, получено %d", cmd.Name, len(cmd.Options), len(command.Options))
+		}
+	}
+}
+
+func TestCommandAllowed(t *testing.T) {
+	cfg.ModRoleID = "mod"
+	defer func() { cfg.ModRoleID = "" }()
+
+	ban := findCommand("ban")
+	if commandAllowed(ban, []string{"member"}) || !commandAllowed(ban, []string{"mod"}) {
+		t.Error("Команда ban должна быть доступна только модераторам")
+	}
+	if !commandAllowed(findCommand("help"), nil) {
+		t.Error("Команда help должна быть доступна всем")
+	}
+}
+
+// localizedNamePattern - допустимые локализованные названия слеш-команд в Discord
+var localizedNamePattern = regexp.MustCompile(`^[-_\p{L}\p{N}]{1,32}$`)
+
+func TestSlashCommandNameLocalizations(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "localization", "translations", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Не найдены файлы локализации: %v", err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Не удалось прочитать %s: %v", file, err)
+		}
+		var texts map[string]string
+		if err := json.Unmarshal(data, &texts); err != nil {
+			t.Fatalf("Не удалось разобрать %s: %v", file, err)
+		}
+
+		seen := make(map[string]string)
+		for _, cmd := range Commands() {
+			if cmd.Slash == nil {
+				continue
+			}
+			name, ok := texts[cmd.Name+"_command_name"]
+			if !ok {
+				t.Errorf("%s: нет перевода названия команды %s", filepath.Base(file), cmd.Name)
+				continue
+			}
+			if !localizedNamePattern.MatchString(name) || name != strings.ToLower(name) {
+				t.Errorf("%s: недопустимое название команды %s: %q", filepath.Base(file), cmd.Name, name)
+			}
+			if other, exists := seen[name]; exists {
+				t.Errorf("%s: команды %s и %s переведены одинаково: %q", filepath.Base(file), other, cmd.Name, name)
+			}
+			seen[name] = cmd.Name
+		}
+	}
+}
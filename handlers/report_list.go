@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"discord-bot/db"
+	"discord-bot/localization"
+	"discord-bot/reports"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	maxReportListReason = 80 // Максимальная длина причины в списке репортов
+	reportHistoryPage   = 10 // Репортов на странице истории пользователя
+)
+
+// handleReportsCommand обрабатывает команду репортов: без аргументов выводит нерассмотренные репорты,
+// с упоминанием пользователя - историю репортов на него (reports @пользователь [страница])
+func handleReportsCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
+	if len(args) == 0 {
+		listOpenReports(reply, m.GuildID)
+		return
+	}
+
+	page := 1
+	if len(args) > 1 {
+		value, err := strconv.Atoi(args[1])
+		if err != nil || value < 1 {
+			reply.Reply(localization.GetText("reports_usage", cfg.Prefix))
+			return
+		}
+		page = value
+	}
+
+	listUserReports(reply, m.GuildID, extractUserID(args[0]), page)
+}
+
+// handleReportsInteraction обрабатывает слеш-команду /reports
+func handleReportsInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := interactionOptions(i)
+	reply := &interactionResponder{s: s, i: i}
+
+	userID := idOption(options, userOption)
+	if userID == "" {
+		listOpenReports(reply, i.GuildID)
+		return
+	}
+
+	page := int(intOption(options, pageOption))
+	if page < 1 {
+		page = 1
+	}
+	listUserReports(reply, i.GuildID, userID, page)
+}
+
+// listOpenReports отправляет модератору список нерассмотренных репортов сервера со ссылками на их сообщения
+func listOpenReports(reply responder, guildID string) {
+	open, err := reports.OpenReports(guildID)
+	if err != nil {
+		fmt.Printf("Ошибка получения списка репортов: %v\n", err)
+		reply.Private(localization.GetText("reports_open_error", err.Error()))
+		return
+	}
+	if len(open) == 0 {
+		reply.Private(localization.GetText("reports_open_empty"))
+		return
+	}
+
+	reply.Private(formatOpenReports(open))
+}
+
+// formatOpenReports формирует список репортов, не превышающий лимит длины сообщения Discord
+func formatOpenReports(open []reports.ReportMessage) string {
+	var b strings.Builder
+	b.WriteString(localization.GetText("reports_open_title", len(open)))
+
+	for index, report := range open {
+		line := fmt.Sprintf("\n**#%d** <@%s> %s - [%s](https://discord.com/channels/%s/%s/%s) <t:%d:R>",
+			report.ReportID, report.ReportedUserID, reportListReason(report.Reason),
+			localization.GetText("reports_open_link"), report.GuildID, report.ChannelID, report.MessageID,
+			report.CreatedAt.Unix(),
+		)
+
+		more := localization.GetText("reports_open_more", len(open)-index)
+		if utf8.RuneCountInString(b.String()+line)+utf8.RuneCountInString(more)+1 > discordMessageLimit {
+			b.WriteString("\n" + more)
+			break
+		}
+		b.WriteString(line)
+	}
+
+	return b.String()
+}
+
+// listUserReports отправляет модератору страницу истории репортов на пользователя, созданных на сервере
+func listUserReports(reply responder, guildID, userID string, page int) {
+	history, err := db.GetReportsByUser(guildID, userID)
+	if err != nil {
+		fmt.Printf("Ошибка получения истории репортов: %v\n", err)
+		reply.Private(localization.GetText("reports_open_error", err.Error()))
+		return
+	}
+	if len(history) == 0 {
+		reply.Private(localization.GetText("reports_history_empty", userID))
+		return
+	}
+
+	reply.Private(formatReportHistory(userID, history, page))
+}
+
+// formatReportHistory формирует страницу истории репортов. Номер страницы ограничивается последней страницей
+func formatReportHistory(userID string, history []db.Report, page int) string {
+	pages := (len(history) + reportHistoryPage - 1) / reportHistoryPage
+	if page > pages {
+		page = pages
+	}
+	start := (page - 1) * reportHistoryPage
+	end := start + reportHistoryPage
+	if end > len(history) {
+		end = len(history)
+	}
+
+	var b strings.Builder
+	b.WriteString(localization.GetText("reports_history_title", userID, len(history), page, pages))
+	for _, report := range history[start:end] {
+		b.WriteString(fmt.Sprintf("\n**#%d** %s <t:%d:d> %s - <@%s>",
+			report.ID, reportStatusText(report.Status), report.Timestamp.Unix(),
+			reportListReason(report.Reason), report.ReporterID,
+		))
+	}
+	if page < pages {
+		b.WriteString("\n" + localization.GetText("reports_history_next", cfg.Prefix, userID, page+1))
+	}
+
+	return b.String()
+}
+
+// viewReport отправляет модератору подробности репорта. Репорты других серверов не показываются
+func viewReport(reply responder, guildID string, roles []string, reportID int64) {
+	if !hasModeratorRole(roles) {
+		reply.Private(localization.GetText("no_permission"))
+		return
+	}
+
+	report, err := db.GetReport(reportID)
+	if err != nil {
+		fmt.Printf("Ошибка получения репорта #%d: %v\n", reportID, err)
+		reply.Private(localization.GetText("reports_open_error", err.Error()))
+		return
+	}
+	if report == nil || !reportInGuild(*report, guildID) {
+		reply.Private(localization.GetText("report_not_found", reportID))
+		return
+	}
+
+	reply.Private(formatReport(report))
+}
+
+// reportInGuild проверяет, создан ли репорт на сервере. Репорт без сервера не относится ни к одному серверу
+func reportInGuild(report db.Report, guildID string) bool {
+	return report.GuildID != "" && report.GuildID == guildID
+}
+
+// formatReport формирует подробности репорта: участники, причина, решение и заметка модератора
+func formatReport(report *db.Report) string {
+	lines := []string{
+		localization.GetText("report_view_title", report.ID, reportStatusText(report.Status)),
+		localization.GetText("report_view_users", report.ReportedUserID, report.ReporterID),
+		localization.GetText("report_view_created", report.Timestamp.Unix()),
+		localization.GetText("report_view_reason", truncateRunes(report.Reason, maxEmbedFieldValue)),
+	}
+	if report.DecidedAt != nil {
+		lines = append(lines, localization.GetText("report_view_decided", report.DecidedBy, report.DecidedAt.Unix()))
+	}
+	if report.Note != "" {
+		lines = append(lines, localization.GetText("report_view_note", truncateRunes(report.Note, maxEmbedFieldValue)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reportStatusText возвращает локализованное название статуса репорта
+func reportStatusText(status db.ReportStatus) string {
+	return localization.GetText("report_status_" + string(status))
+}
+
+// reportListReason сворачивает причину репорта в одну короткую строку для списков
+func reportListReason(reason string) string {
+	return truncateRunes(strings.Join(strings.Fields(reason), " "), maxReportListReason)
+}
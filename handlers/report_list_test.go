@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"discord-bot/db"
+)
+
+func TestFormatReportHistoryPages(t *testing.T) {
+	history := make([]db.Report, reportHistoryPage+3)
+	for index := range history {
+		history[index] = db.Report{
+			ID:        int64(len(history) - index),
+			Reason:    "spam\nflood",
+			Status:    db.ReportStatusPending,
+			Timestamp: time.Unix(1700000000, 0),
+		}
+	}
+
+	first := formatReportHistory("user", history, 1)
+	if got := strings.Count(first, "\n**#"); got != reportHistoryPage {
+		t.Errorf("На первой странице ожидалось %d репортов, получено %d", reportHistoryPage, got)
+	}
+	if strings.Contains(first, "spam\nflood") {
+		t.Error("Причина в списке должна быть свернута в одну строку")
+	}
+
+	// Номер страницы за пределами истории ограничивается последней страницей
+	last := formatReportHistory("user", history, 5)
+	if got := strings.Count(last, "\n**#"); got != 3 {
+		t.Errorf("На последней странице ожидалось 3 репорта, получено %d", got)
+	}
+	if !strings.Contains(last, "**#1**") || strings.Contains(last, "**#4**") {
+		t.Errorf("Последняя страница содержит неверные репорты:\n%s", last)
+	}
+}
+
+func TestReportInGuild(t *testing.T) {
+	if !reportInGuild(db.Report{GuildID: "guild"}, "guild") {
+		t.Error("Репорт сервера должен быть виден на сервере")
+	}
+	if reportInGuild(db.Report{GuildID: "other"}, "guild") {
+		t.Error("Репорт другого сервера не должен быть виден")
+	}
+	if reportInGuild(db.Report{}, "guild") || reportInGuild(db.Report{}, "") {
+		t.Error("Репорт без сервера не должен быть виден ни на одном сервере")
+	}
+}
+
+func TestGetReportsByUserScopedToGuild(t *testing.T) {
+	useTestDB(t)
+	for _, guildID := range []string{"guild", "other", ""} {
+		if _, err := db.RecordReport(db.Report{GuildID: guildID, ReportedUserID: "user", ReporterID: "reporter", Reason: "спам"}); err != nil {
+			t.Fatalf("Не удалось сохранить репорт: %v", err)
+		}
+	}
+
+	history, err := db.GetReportsByUser("guild", "user")
+	if err != nil {
+		t.Fatalf("Не удалось получить историю репортов: %v", err)
+	}
+	if len(history) != 1 || history[0].GuildID != "guild" {
+		t.Errorf("История должна содержать только репорт сервера, получено %+v", history)
+	}
+}
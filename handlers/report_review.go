@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"discord-bot/db"
+	"discord-bot/localization"
+	"discord-bot/reports"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Цвета embed репорта после рассмотрения
+const (
+	reportConfirmedColor = 0x00FF00
+	reportRejectedColor  = 0xFF0000
+	reportBannedColor    = 0x8B0000
+	reportInfoColor      = 0x3498DB
+)
+
+// handleReportComponent обрабатывает нажатия кнопок репорта и отправку окна запроса информации
+func handleReportComponent(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	action, reportID, ok := reports.ParseComponentID(customID)
+	if !ok {
+		return
+	}
+
+	if !hasModeratorRole(memberRoles(i.Member)) {
+		respondEphemeral(s, i, localization.GetText("no_permission"))
+		return
+	}
+
+	if i.Message == nil {
+		respondEphemeral(s, i, localization.GetText("report_not_found", reportID))
+		return
+	}
+	reportMsg, exists := reports.GetReportMessage(i.Message.ID)
+	if !exists || reportMsg.ReportID != reportID {
+		respondEphemeral(s, i, localization.GetText("report_not_found", reportID))
+		return
+	}
+
+	moderatorID := interactionUserID(i)
+	switch action {
+	case reports.ActionConfirm:
+		confirmReport(s, i, reportMsg, moderatorID)
+	case reports.ActionReject:
+		rejectReport(s, i, reportMsg, moderatorID)
+	case reports.ActionBan:
+		banFromReport(s, i, reportMsg, moderatorID)
+	case reports.ActionInfo:
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseModal,
+			Data: reports.InfoModal(reportID),
+		}); err != nil {
+			fmt.Printf("Ошибка открытия окна запроса информации: %v\n", err)
+		}
+	case reports.ActionNote:
+		requestReportInfo(s, i, reportMsg, moderatorID)
+	}
+}
+
+// confirmReport подтверждает репорт и выносит пользователю предупреждение.
+// При достижении ступени лестницы наказаний пользователь наказывается автоматически
+func confirmReport(s *discordgo.Session, i *discordgo.InteractionCreate, reportMsg reports.ReportMessage, moderatorID string) {
+	if !claimReport(s, i, reportMsg, db.ReportStatusConfirmed, moderatorID) {
+		return
+	}
+
+	// Подтвержденный репорт считается предупреждением, поэтому записывается одним случаем
+	if _, err := recordCase(s, db.ModerationCase{
+		GuildID:     i.GuildID,
+		UserID:      reportMsg.ReportedUserID,
+		ModeratorID: moderatorID,
+		Action:      db.CaseReportConfirm,
+		Reason:      reportMsg.Reason,
+		ReportID:    reportMsg.ReportID,
+		Evidence:    []string{evidenceRef(i.ChannelID, i.Message.ID)},
+	}); err != nil {
+		fmt.Println("Ошибка сохранения случая подтверждения репорта:", err)
+	}
+
+	warnings, err := countWarnings(i.GuildID, reportMsg.ReportedUserID)
+	if err != nil {
+		fmt.Println("Ошибка при подсчете предупреждений:", err)
+	}
+
+	embed := reviewedReportEmbed(i.Message, localization.GetText("report_review_confirmed_title", reportMsg.ReportID), reportConfirmedColor,
+		&discordgo.MessageEmbedField{Name: localization.GetText("report_review_confirmed_by"), Value: fmt.Sprintf("<@%s>", moderatorID)},
+		&discordgo.MessageEmbedField{Name: localization.GetText("report_review_warnings"), Value: fmt.Sprintf("%d", warnings)},
+	)
+	updateReportMessage(s, i, embed, []discordgo.MessageComponent{})
+	reports.CloseReportMessage(i.Message.ID, db.ReportStatusConfirmed)
+	followupEphemeral(s, i, localization.GetText("report_confirmed_ack", reportMsg.ReportID))
+
+	if text := escalateWarnings(s, i.GuildID, reportMsg.ReportedUserID, warnings); text != "" {
+		followupEphemeral(s, i, text)
+	}
+}
+
+// rejectReport отклоняет репорт
+func rejectReport(s *discordgo.Session, i *discordgo.InteractionCreate, reportMsg reports.ReportMessage, moderatorID string) {
+	if !claimReport(s, i, reportMsg, db.ReportStatusRejected, moderatorID) {
+		return
+	}
+
+	embed := reviewedReportEmbed(i.Message, localization.GetText("report_review_rejected_title", reportMsg.ReportID), reportRejectedColor,
+		&discordgo.MessageEmbedField{Name: localization.GetText("report_review_rejected_by"), Value: fmt.Sprintf("<@%s>", moderatorID)},
+	)
+	updateReportMessage(s, i, embed, []discordgo.MessageComponent{})
+	reports.CloseReportMessage(i.Message.ID, db.ReportStatusRejected)
+	followupEphemeral(s, i, localization.GetText("report_rejected_ack", reportMsg.ReportID))
+	logModAction(s, i.GuildID, modLogEntry{
+		Action:      modActionReject,
+		TargetID:    reportMsg.ReportedUserID,
+		ModeratorID: moderatorID,
+		Reason:      reportMsg.Reason,
+		ReportID:    reportMsg.ReportID,
+	})
+}
+
+// banFromReport эскалирует репорт и сразу банит пользователя без срока
+func banFromReport(s *discordgo.Session, i *discordgo.InteractionCreate, reportMsg reports.ReportMessage, moderatorID string) {
+	if !claimReport(s, i, reportMsg, db.ReportStatusEscalated, moderatorID) {
+		return
+	}
+
+	var fallback error
+	err := banOrReopen(reportMsg.ReportID, func() (err error) {
+		_, fallback, err = enforceBan(s, db.ModerationCase{
+			GuildID:     i.GuildID,
+			UserID:      reportMsg.ReportedUserID,
+			ModeratorID: moderatorID,
+			Reason:      localization.GetText("report_ban_reason", reportMsg.ReportID, reportMsg.Reason),
+			ReportID:    reportMsg.ReportID,
+			Evidence:    []string{evidenceRef(i.ChannelID, i.Message.ID)},
+		})
+		return err
+	})
+	if err != nil {
+		fmt.Println("Ошибка при бане пользователя:", err)
+		respondEphemeral(s, i, localization.GetText("ban_error", err.Error()))
+		return
+	}
+
+	embed := reviewedReportEmbed(i.Message, localization.GetText("report_review_banned_title", reportMsg.ReportID), reportBannedColor,
+		&discordgo.MessageEmbedField{Name: localization.GetText("report_review_banned_by"), Value: fmt.Sprintf("<@%s>", moderatorID)},
+	)
+	updateReportMessage(s, i, embed, []discordgo.MessageComponent{})
+	reports.CloseReportMessage(i.Message.ID, db.ReportStatusEscalated)
+	followupEphemeral(s, i, localization.GetText("report_banned_ack", reportMsg.ReportedUserID, reportMsg.ReportID))
+	if fallback != nil {
+		followupEphemeral(s, i, banFallbackText(fallback))
+	}
+}
+
+// banOrReopen применяет бан по уже эскалированному репорту. Если бан не удался, репорт
+// возвращается к рассмотрению, чтобы модераторы могли снова нажать кнопки
+func banOrReopen(reportID int64, ban func() error) error {
+	err := ban()
+	if err != nil {
+		if reopenErr := db.ReopenReport(reportID); reopenErr != nil {
+			fmt.Printf("Ошибка возврата репорта #%d к рассмотрению: %v\n", reportID, reopenErr)
+		}
+	}
+	return err
+}
+
+// claimReport сохраняет решение модератора по репорту. Обработчики интеракций выполняются
+// параллельно, поэтому из нескольких нажатий кнопок решение сохраняет только первое,
+// а остальным отвечается, что репорт уже рассмотрен
+func claimReport(s *discordgo.Session, i *discordgo.InteractionCreate, reportMsg reports.ReportMessage, status db.ReportStatus, moderatorID string) bool {
+	err := db.SetReportStatus(reportMsg.ReportID, status, moderatorID)
+	switch {
+	case errors.Is(err, db.ErrReportAlreadyReviewed):
+		respondEphemeral(s, i, localization.GetText("report_not_found", reportMsg.ReportID))
+		return false
+	case err != nil:
+		fmt.Printf("Ошибка сохранения решения по репорту #%d: %v\n", reportMsg.ReportID, err)
+		respondEphemeral(s, i, localization.GetText("report_review_error", err))
+		return false
+	}
+	return true
+}
+
+// requestReportInfo отправляет автору репорта вопрос модератора из окна запроса информации.
+// Репорт остается открытым, кнопки рассмотрения сохраняются
+func requestReportInfo(s *discordgo.Session, i *discordgo.InteractionCreate, reportMsg reports.ReportMessage, moderatorID string) {
+	note := strings.TrimSpace(modalTextValue(i.ModalSubmitData(), reports.NoteInputID))
+	if note == "" {
+		respondEphemeral(s, i, localization.GetText("report_info_note_empty"))
+		return
+	}
+
+	if err := db.SetReportNote(reportMsg.ReportID, note); err != nil {
+		fmt.Printf("Ошибка сохранения заметки к репорту #%d: %v\n", reportMsg.ReportID, err)
+	}
+
+	ack := localization.GetText("report_info_requested_ack")
+	if err := sendReportInfoRequest(s, reportMsg, note); err != nil {
+		fmt.Printf("Ошибка отправки запроса информации автору репорта: %v\n", err)
+		ack = localization.GetText("report_info_dm_failed", reportMsg.ReporterID)
+	}
+
+	embed := reviewedReportEmbed(i.Message, "", reportInfoColor,
+		&discordgo.MessageEmbedField{Name: localization.GetText("report_review_info_requested"), Value: fmt.Sprintf("<@%s>: %s", moderatorID, note)},
+	)
+	updateReportMessage(s, i, embed, reports.ReviewComponents(reportMsg.ReportID))
+	followupEphemeral(s, i, ack)
+}
+
+// sendReportInfoRequest отправляет автору репорта личное сообщение с вопросом модератора
+func sendReportInfoRequest(s *discordgo.Session, reportMsg reports.ReportMessage, note string) error {
+	channel, err := s.UserChannelCreate(reportMsg.ReporterID)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть личный канал: %w", err)
+	}
+	if _, err := s.ChannelMessageSend(channel.ID, localization.GetText("report_info_dm", reportMsg.ReportID, note)); err != nil {
+		return fmt.Errorf("не удалось отправить личное сообщение: %w", err)
+	}
+	return nil
+}
+
+// reviewedReportEmbed копирует embed сообщения репорта, меняя заголовок (пусто - без изменений) и цвет
+// и добавляя поля с результатом рассмотрения
+func reviewedReportEmbed(message *discordgo.Message, title string, color int, fields ...*discordgo.MessageEmbedField) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{}
+	if len(message.Embeds) > 0 {
+		original := *message.Embeds[0]
+		embed = &original
+		embed.Fields = append([]*discordgo.MessageEmbedField(nil), original.Fields...)
+	}
+
+	if title != "" {
+		embed.Title = title
+	}
+	embed.Color = color
+	embed.Fields = append(embed.Fields, fields...)
+	embed.Timestamp = time.Now().Format(time.RFC3339)
+	return embed
+}
+
+// updateReportMessage обновляет сообщение репорта в ответ на интеракцию
+func updateReportMessage(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	}); err != nil {
+		fmt.Printf("Ошибка при редактировании сообщения: %v\n", err)
+	}
+}
+
+// followupEphemeral отправляет дополнительный ответ, видимый только автору интеракции
+func followupEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	}); err != nil {
+		fmt.Printf("Ошибка отправки дополнительного ответа на взаимодействие: %v\n", err)
+	}
+}
+
+// modalTextValue возвращает значение текстового поля модального окна
+func modalTextValue(data discordgo.ModalSubmitInteractionData, customID string) string {
+	for _, row := range data.Components {
+		actions, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, component := range actions.Components {
+			if input, ok := component.(*discordgo.TextInput); ok && input.CustomID == customID {
+				return input.Value
+			}
+		}
+	}
+	return ""
+}
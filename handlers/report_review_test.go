@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"discord-bot/db"
+)
+
+// useTestDB подключает временную базу SQLite на время теста
+func useTestDB(t *testing.T) {
+	t.Helper()
+	config := db.DatabaseConfig{Type: "sqlite", Database: filepath.Join(t.TempDir(), "bot.db")}
+	if err := db.Initialize(config); err != nil {
+		t.Fatalf("Не удалось открыть тестовую базу данных: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		db.CurrentProvider = nil
+	})
+}
+
+func TestBanOrReopenRestoresReportOnFailure(t *testing.T) {
+	useTestDB(t)
+	reportID, err := db.RecordReport(db.Report{GuildID: "guild", ReportedUserID: "user", ReporterID: "reporter", Reason: "спам"})
+	if err != nil {
+		t.Fatalf("Не удалось сохранить репорт: %v", err)
+	}
+	if err := db.SetReportStatus(reportID, db.ReportStatusEscalated, "mod"); err != nil {
+		t.Fatalf("Не удалось эскалировать репорт: %v", err)
+	}
+
+	banErr := errors.New("Discord недоступен")
+	if err := banOrReopen(reportID, func() error { return banErr }); !errors.Is(err, banErr) {
+		t.Fatalf("Ожидалась ошибка бана, получено %v", err)
+	}
+
+	report, err := db.GetReport(reportID)
+	if err != nil || report.Status != db.ReportStatusPending || report.DecidedBy != "" {
+		t.Fatalf("После неудачного бана репорт должен вернуться к рассмотрению: %+v, %v", report, err)
+	}
+	if err := db.SetReportStatus(reportID, db.ReportStatusConfirmed, "mod"); err != nil {
+		t.Errorf("Возвращенный к рассмотрению репорт должен снова приниматься кнопками: %v", err)
+	}
+}
+
+func TestBanOrReopenKeepsEscalatedReport(t *testing.T) {
+	useTestDB(t)
+	reportID, err := db.RecordReport(db.Report{GuildID: "guild", ReportedUserID: "user", ReporterID: "reporter", Reason: "спам"})
+	if err != nil {
+		t.Fatalf("Не удалось сохранить репорт: %v", err)
+	}
+	if err := db.SetReportStatus(reportID, db.ReportStatusEscalated, "mod"); err != nil {
+		t.Fatalf("Не удалось эскалировать репорт: %v", err)
+	}
+
+	if err := banOrReopen(reportID, func() error { return nil }); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if err := db.SetReportStatus(reportID, db.ReportStatusRejected, "mod"); !errors.Is(err, db.ErrReportAlreadyReviewed) {
+		t.Errorf("Репорт с примененным баном должен оставаться рассмотренным, получено %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// responder отправляет ответы команды так, как это принято для способа ее вызова:
+// сообщением в канал для текстовой команды или ответом на интеракцию для слеш-команды
+type responder interface {
+	// Reply отправляет ответ, видимый всем в канале
+	Reply(text string)
+	// Private отправляет ответ, видимый только автору слеш-команды (ошибки, подтверждения)
+	Private(text string)
+	// Defer сообщает Discord, что ответ на слеш-команду займет больше трех секунд.
+	// private определяет видимость отложенного ответа
+	Defer(private bool)
+}
+
+// channelResponder отвечает на текстовую команду сообщениями в канал
+type channelResponder struct {
+	s         *discordgo.Session
+	channelID string
+}
+
+func (r *channelResponder) Reply(text string) {
+	if _, err := r.s.ChannelMessageSend(r.channelID, text); err != nil {
+		fmt.Printf("Ошибка отправки сообщения: %v\n", err)
+	}
+}
+
+func (r *channelResponder) Private(text string) {
+	r.Reply(text)
+}
+
+func (r *channelResponder) Defer(private bool) {
+	if err := r.s.ChannelTyping(r.channelID); err != nil {
+		fmt.Printf("Ошибка отправки статуса набора: %v\n", err)
+	}
+}
+
+// directResponder отвечает на текстовую команду личным сообщением автору, чтобы ответ не видели
+// остальные участники канала. Если личное сообщение отправить не удалось, ответ уходит в канал
+type directResponder struct {
+	s       *discordgo.Session
+	userID  string
+	channel channelResponder
+}
+
+func (r *directResponder) Reply(text string) {
+	channel, err := r.s.UserChannelCreate(r.userID)
+	if err == nil {
+		_, err = r.s.ChannelMessageSend(channel.ID, text)
+	}
+	if err != nil {
+		fmt.Printf("Ошибка отправки личного сообщения: %v\n", err)
+		r.channel.Reply(text)
+	}
+}
+
+func (r *directResponder) Private(text string) {
+	r.Reply(text)
+}
+
+func (r *directResponder) Defer(private bool) {}
+
+// interactionResponder отвечает на слеш-команду. Первый ответ становится ответом на интеракцию,
+// последующие отправляются дополнительными сообщениями
+type interactionResponder struct {
+	s         *discordgo.Session
+	i         *discordgo.InteractionCreate
+	responded bool // Ответ на интеракцию отправлен
+	deferred  bool // Ответ отложен и еще не заполнен
+}
+
+func (r *interactionResponder) Reply(text string) {
+	r.respond(text, 0)
+}
+
+func (r *interactionResponder) Private(text string) {
+	r.respond(text, discordgo.MessageFlagsEphemeral)
+}
+
+func (r *interactionResponder) Defer(private bool) {
+	if r.responded {
+		return
+	}
+
+	var flags discordgo.MessageFlags
+	if private {
+		flags = discordgo.MessageFlagsEphemeral
+	}
+	r.responded, r.deferred = true, true
+	if err := r.s.InteractionRespond(r.i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: flags},
+	}); err != nil {
+		fmt.Printf("Ошибка отправки ответа на взаимодействие: %v\n", err)
+	}
+}
+
+// respond отправляет ответ на интеракцию или дополнительное сообщение.
+// Отложенный ответ заполняется первым сообщением, его видимость задана при откладывании
+func (r *interactionResponder) respond(text string, flags discordgo.MessageFlags) {
+	if r.deferred {
+		r.deferred = false
+		if _, err := r.s.InteractionResponseEdit(r.i.Interaction, &discordgo.WebhookEdit{Content: &text}); err != nil {
+			fmt.Printf("Ошибка отправки ответа на взаимодействие: %v\n", err)
+		}
+		return
+	}
+
+	if !r.responded {
+		r.responded = true
+		if err := r.s.InteractionRespond(r.i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: text, Flags: flags},
+		}); err != nil {
+			fmt.Printf("Ошибка отправки ответа на взаимодействие: %v\n", err)
+		}
+		return
+	}
+
+	if _, err := r.s.FollowupMessageCreate(r.i.Interaction, true, &discordgo.WebhookParams{Content: text, Flags: flags}); err != nil {
+		fmt.Printf("Ошибка отправки дополнительного ответа на взаимодействие: %v\n", err)
+	}
+}
+
+// interactionOptions возвращает параметры слеш-команды по названиям
+func interactionOptions(i *discordgo.InteractionCreate) map[string]*discordgo.ApplicationCommandInteractionDataOption {
+	options := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, option := range i.ApplicationCommandData().Options {
+		options[option.Name] = option
+	}
+	return options
+}
+
+// subcommandOptions возвращает название подкоманды слеш-команды и ее параметры по названиям
+func subcommandOptions(i *discordgo.InteractionCreate) (string, map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	options := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 || data.Options[0].Type != discordgo.ApplicationCommandOptionSubCommand {
+		return "", options
+	}
+
+	for _, option := range data.Options[0].Options {
+		options[option.Name] = option
+	}
+	return data.Options[0].Name, options
+}
+
+// intOption возвращает целочисленный параметр слеш-команды (0, если он не передан)
+func intOption(options map[string]*discordgo.ApplicationCommandInteractionDataOption, name string) int64 {
+	if option, ok := options[name]; ok {
+		return option.IntValue()
+	}
+	return 0
+}
+
+// stringOption возвращает строковый параметр слеш-команды (пусто, если он не передан)
+func stringOption(options map[string]*discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	if option, ok := options[name]; ok {
+		return option.StringValue()
+	}
+	return ""
+}
+
+// idOption возвращает ID пользователя, канала или роли из параметра слеш-команды (пусто, если он не передан)
+func idOption(options map[string]*discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	if option, ok := options[name]; ok {
+		if id, ok := option.Value.(string); ok {
+			return id
+		}
+	}
+	return ""
+}
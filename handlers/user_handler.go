@@ -1,99 +1,92 @@
 package handlers
 
 import (
+	"strings"
+
 	"discord-bot/localization"
-	"fmt"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 // HandleNicknameCommand обрабатывает команду для изменения никнейма пользователя
 func HandleNicknameCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
 	if len(args) < 2 {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("nickname_usage", "!")); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+		reply.Reply(localization.GetText("nickname_usage", cfg.Prefix))
 		return
 	}
 
-	// Получаем ID пользователя из упоминания или первого аргумента
-	userID := args[0]
-	// Удаляем форматирование упоминания, если оно есть
-	userID = extractUserID(userID)
+	// Проверяем, имеет ли пользователь права на изменение никнеймов
+	permissions, err := s.State.UserChannelPermissions(m.Author.ID, m.ChannelID)
+	allowed := err == nil && permissions&discordgo.PermissionManageNicknames != 0
 
-	// Получаем новый никнейм из оставшихся аргументов
-	newNickname := args[1]
+	changeNickname(s, reply, m.GuildID, extractUserID(args[0]), strings.Join(args[1:], " "), allowed)
+}
 
-	// Проверяем права бота на изменение никнеймов
-	_, err := s.State.Guild(m.GuildID)
-	if err != nil {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("nickname_guild_error")); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+// handleNicknameInteraction обрабатывает слеш-команду /nickname
+func handleNicknameInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := interactionOptions(i)
+	// Discord передает в интеракции итоговые права участника в канале
+	allowed := i.Member != nil && i.Member.Permissions&discordgo.PermissionManageNicknames != 0
+
+	changeNickname(s, &interactionResponder{s: s, i: i}, i.GuildID, idOption(options, userOption), stringOption(options, nicknameOption), allowed)
+}
+
+// changeNickname изменяет никнейм пользователя на сервере
+func changeNickname(s *discordgo.Session, reply responder, guildID, userID, nickname string, allowed bool) {
+	if _, err := s.State.Guild(guildID); err != nil {
+		reply.Private(localization.GetText("nickname_guild_error"))
 		return
 	}
 
-	// Проверяем, имеет ли пользователь права на изменение никнеймов
-	permissions, err := s.State.UserChannelPermissions(m.Author.ID, m.ChannelID)
-	if err != nil || (permissions&discordgo.PermissionManageNicknames) == 0 {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("nickname_no_permission")); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+	if !allowed {
+		reply.Private(localization.GetText("nickname_no_permission"))
 		return
 	}
 
-	// Изменяем никнейм пользователя
-	err = s.GuildMemberNickname(m.GuildID, userID, newNickname)
-	if err != nil {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("nickname_error", err.Error())); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+	if err := s.GuildMemberNickname(guildID, userID, nickname); err != nil {
+		reply.Private(localization.GetText("nickname_error", err.Error()))
 		return
 	}
 
-	if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("nickname_success")); err != nil {
-		fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-	}
+	reply.Reply(localization.GetText("nickname_success"))
 }
 
 // HandleDMCommand обрабатывает команду для отправки личного сообщения пользователю
 func HandleDMCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
 	if len(args) < 2 {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("dm_usage", "!")); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+		reply.Reply(localization.GetText("dm_usage", cfg.Prefix))
 		return
 	}
 
-	// Получаем ID пользователя из упоминания или первого аргумента
-	userID := args[0]
-	// Удаляем форматирование упоминания, если оно есть
-	userID = extractUserID(userID)
+	sendDirectMessage(s, reply, extractUserID(args[0]), strings.Join(args[1:], " "))
+}
+
+// handleDMInteraction обрабатывает слеш-команду /dm
+func handleDMInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := interactionOptions(i)
+	sendDirectMessage(s, &interactionResponder{s: s, i: i}, idOption(options, userOption), stringOption(options, messageOption))
+}
 
-	// Получаем сообщение из оставшихся аргументов
-	message := args[1]
+// sendDirectMessage отправляет пользователю личное сообщение от имени бота
+func sendDirectMessage(s *discordgo.Session, reply responder, userID, message string) {
+	reply.Defer(true)
 
 	// Создаем личный канал с пользователем
 	channel, err := s.UserChannelCreate(userID)
 	if err != nil {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("dm_channel_error", err.Error())); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+		reply.Private(localization.GetText("dm_channel_error", err.Error()))
 		return
 	}
 
 	// Отправляем сообщение в личный канал
-	_, err = s.ChannelMessageSend(channel.ID, message)
-	if err != nil {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("dm_send_error", err.Error())); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+	if _, err := s.ChannelMessageSend(channel.ID, message); err != nil {
+		reply.Private(localization.GetText("dm_send_error", err.Error()))
 		return
 	}
 
-	if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("dm_success")); err != nil {
-		fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-	}
+	reply.Private(localization.GetText("dm_success"))
 }
 
 // extractUserID извлекает ID пользователя из упоминания
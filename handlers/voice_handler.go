@@ -25,54 +25,57 @@ type VoiceInstance struct {
 var voiceInstances = make(map[string]*VoiceInstance)
 var voiceMutex sync.Mutex
 
+// HandlePlayCommand обрабатывает текстовую команду play
 func HandlePlayCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	reply := &channelResponder{s: s, channelID: m.ChannelID}
 	if len(args) < 1 {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("play_usage", "!")); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+		reply.Reply(localization.GetText("play_usage", cfg.Prefix))
 		return
 	}
 
-	url := args[0]
+	playAudio(s, reply, m.GuildID, findUserVoiceChannel(s, m.GuildID, m.Author.ID), args[0])
+}
+
+// handlePlayInteraction обрабатывает слеш-команду /play. Без указанного канала
+// используется голосовой канал, в котором находится пользователь
+func handlePlayInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := interactionOptions(i)
+
+	channelID := idOption(options, channelOption)
+	if channelID == "" {
+		channelID = findUserVoiceChannel(s, i.GuildID, interactionUserID(i))
+	}
+
+	playAudio(s, &interactionResponder{s: s, i: i}, i.GuildID, channelID, stringOption(options, urlOption))
+}
 
+// playAudio подключается к голосовому каналу и воспроизводит аудио с YouTube
+func playAudio(s *discordgo.Session, reply responder, guildID, voiceChannelID, url string) {
 	if !isValidYouTubeURL(url) {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("play_invalid_url")); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+		reply.Private(localization.GetText("play_invalid_url"))
 		return
 	}
 
-	voiceChannelID := findUserVoiceChannel(s, m.GuildID, m.Author.ID)
 	if voiceChannelID == "" {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("play_not_in_voice")); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+		reply.Private(localization.GetText("play_not_in_voice"))
 		return
 	}
 
-	if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("play_joining")); err != nil {
-		fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-	}
+	reply.Reply(localization.GetText("play_joining"))
 
-	vc, err := joinVoiceChannel(s, m.GuildID, voiceChannelID)
+	vc, err := joinVoiceChannel(s, guildID, voiceChannelID)
 	if err != nil {
-		if _, err2 := s.ChannelMessageSend(m.ChannelID, localization.GetText("play_error", err.Error())); err2 != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err2)
-		}
+		reply.Private(localization.GetText("play_error", err.Error()))
 		return
 	}
 
-	videoTitle, err := playYouTubeAudio(s, vc, url, m.GuildID)
+	videoTitle, err := playYouTubeAudio(s, vc, url, guildID)
 	if err != nil {
-		if _, err2 := s.ChannelMessageSend(m.ChannelID, localization.GetText("play_error", err.Error())); err2 != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err2)
-		}
+		reply.Private(localization.GetText("play_error", err.Error()))
 		return
 	}
 
-	if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("play_now_playing", videoTitle)); err != nil {
-		fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-	}
+	reply.Reply(localization.GetText("play_now_playing", videoTitle))
 }
 
 func findUserVoiceChannel(s *discordgo.Session, guildID, userID string) string {
@@ -185,15 +188,24 @@ func isValidYouTubeURL(url string) bool {
 	return youtubeRegex.MatchString(url)
 }
 
+// HandleStopCommand обрабатывает текстовую команду stop
 func HandleStopCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
+	stopPlayback(&channelResponder{s: s, channelID: m.ChannelID}, m.GuildID)
+}
+
+// handleStopInteraction обрабатывает слеш-команду /stop
+func handleStopInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	stopPlayback(&interactionResponder{s: s, i: i}, i.GuildID)
+}
+
+// stopPlayback останавливает воспроизведение на сервере
+func stopPlayback(reply responder, guildID string) {
 	voiceMutex.Lock()
-	vi, exists := voiceInstances[m.GuildID]
+	vi, exists := voiceInstances[guildID]
 	voiceMutex.Unlock()
 
 	if !exists {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("stop_not_playing")); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+		reply.Private(localization.GetText("stop_not_playing"))
 		return
 	}
 
@@ -201,20 +213,27 @@ func HandleStopCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
 	vi.stopped = true
 	vi.mutex.Unlock()
 
-	if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("stop_success")); err != nil {
-		fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-	}
+	reply.Reply(localization.GetText("stop_success"))
 }
 
+// HandleLeaveCommand обрабатывает текстовую команду leave
 func HandleLeaveCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
+	leaveVoice(&channelResponder{s: s, channelID: m.ChannelID}, m.GuildID)
+}
+
+// handleLeaveInteraction обрабатывает слеш-команду /leave
+func handleLeaveInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	leaveVoice(&interactionResponder{s: s, i: i}, i.GuildID)
+}
+
+// leaveVoice останавливает воспроизведение и отключается от голосового канала сервера
+func leaveVoice(reply responder, guildID string) {
 	voiceMutex.Lock()
 	defer voiceMutex.Unlock()
 
-	vi, exists := voiceInstances[m.GuildID]
+	vi, exists := voiceInstances[guildID]
 	if !exists {
-		if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("leave_not_in_voice")); err != nil {
-			fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-		}
+		reply.Private(localization.GetText("leave_not_in_voice"))
 		return
 	}
 
@@ -223,16 +242,12 @@ func HandleLeaveCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
 	vi.mutex.Unlock()
 
 	if err := vi.connection.Disconnect(); err != nil {
-		if _, err2 := s.ChannelMessageSend(m.ChannelID, localization.GetText("leave_error", err.Error())); err2 != nil {
-			fmt.Printf("Ошибка отправки сообщения об ошибке: %v\n", err2)
-		}
+		reply.Private(localization.GetText("leave_error", err.Error()))
 		return
 	}
-	delete(voiceInstances, m.GuildID)
+	delete(voiceInstances, guildID)
 
-	if _, err := s.ChannelMessageSend(m.ChannelID, localization.GetText("leave_success")); err != nil {
-		fmt.Printf("Ошибка отправки сообщения: %v\n", err)
-	}
+	reply.Reply(localization.GetText("leave_success"))
 }
 
 func DownloadYouTubeAudio(url string) (string, error) {
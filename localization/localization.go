@@ -344,6 +344,12 @@ func GetText(key string, args ...interface{}) string {
 	return text
 }
 
+// Lookup возвращает перевод ключа на указанный язык без подстановки английского текста
+func Lookup(lang, key string) (string, bool) {
+	text, exists := translations[lang][key]
+	return text, exists
+}
+
 // SetLanguage устанавливает текущий язык бота
 func SetLanguage(lang string) bool {
 	// Проверяем, что указанный язык поддерживается
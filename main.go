@@ -12,6 +12,7 @@ import (
 	"discord-bot/db"
 	"discord-bot/handlers"
 	"discord-bot/localization"
+	"discord-bot/reports"
 	"discord-bot/web"
 
 	"github.com/bwmarrin/discordgo"
@@ -19,17 +20,16 @@ import (
 
 var s *discordgo.Session
 
-func init() {
+func main() {
 	flag.Parse()
-}
 
-func main() {
 	// Загрузка конфигурации
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Println("Ошибка загрузки конфигурации:", err)
 		return
 	}
+	handlers.Init(cfg)
 
 	// Запуск веб-интерфейса, если он включен
 	if cfg.WebInterface.Enabled {
@@ -51,6 +51,13 @@ func main() {
 		return
 	}
 
+	// Восстановление нерассмотренных репортов, чтобы кнопки старых сообщений продолжали работать
+	if count, err := reports.LoadPendingReports(); err != nil {
+		fmt.Println("Ошибка восстановления репортов:", err)
+	} else {
+		fmt.Printf("Загружено нерассмотренных репортов: %d\n", count)
+	}
+
 	// Инициализация AI провайдеров
 	if err := ai.Initialize(); err != nil {
 		fmt.Println("Ошибка инициализации AI провайдеров:", err)
@@ -58,6 +65,9 @@ func main() {
 		fmt.Println("Бот будет работать с ограниченной функциональностью AI")
 	}
 
+	// Учет обращений к AI провайдерам
+	ai.UsageRecorder = handlers.RecordAIUsage
+
 	// Инициализация системы локализации
 	if err := localization.Initialize(); err != nil {
 		fmt.Println("Ошибка инициализации системы локализации:", err)
@@ -73,17 +83,12 @@ func main() {
 
 	// Регистрация обработчиков событий
 	s.AddHandler(handlers.MessageCreate)
-	s.AddHandler(handlers.ReactionAdd)
 
 	// Добавляем интенты для получения информации о пользователях
 	s.Identify.Intents |= discordgo.IntentsGuildMembers
 
-	// Инициализация слеш-команд AI
-	if err := handlers.InitAICommands(s); err != nil {
-		fmt.Println("Ошибка инициализации слеш-команд AI:", err)
-		// Продолжаем работу даже при ошибке инициализации команд
-		fmt.Println("Бот будет работать без слеш-команд AI")
-	}
+	// Слеш-команды регистрируются после подключения к Discord
+	handlers.InitCommands(s)
 
 	// Добавляем интенты для голосовых каналов
 	s.Identify.Intents |= discordgo.IntentsGuildVoiceStates
@@ -95,6 +100,9 @@ func main() {
 		return
 	}
 
+	// Снятие истекших банов, включая истекшие, пока бот был выключен
+	handlers.StartBanScheduler(s)
+
 	// Выводим красивое сообщение о запуске бота
 	fmt.Println("\n╔════════════════════════════════════════════════════════════╗")
 	fmt.Println("║                                                        ║")
@@ -106,7 +114,7 @@ func main() {
 	}
 	fmt.Println("║  ⚠️  Нажмите CTRL+C для завершения работы              ║")
 	fmt.Println("║                                                        ║")
-	fmt.Println("╚════════════════════════════════════════════════════════════╝\n")
+	fmt.Print("╚════════════════════════════════════════════════════════════╝\n\n")
 
 	// Ожидание сигнала для завершения
 	sc := make(chan os.Signal, 1)
@@ -0,0 +1,61 @@
+package moderation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseResult(t *testing.T) {
+	text := "```json\n{\"scores\": {\"Toxicity\": 0.92, \"spam\": 15, \"nsfw\": -1}, \"explanation\": \" оскорбление \"}\n```"
+
+	result, err := ParseResult(text)
+	if err != nil {
+		t.Fatalf("Ошибка разбора: %v", err)
+	}
+
+	want := map[string]float64{"toxicity": 0.92, "spam": 0.15, "nsfw": 0}
+	if !reflect.DeepEqual(result.Scores, want) {
+		t.Errorf("Оценки: ожидалось %v, получено %v", want, result.Scores)
+	}
+	if result.Explanation != "оскорбление" {
+		t.Errorf("Неожиданное объяснение: %q", result.Explanation)
+	}
+}
+
+func TestParseResultRejectsMissingScores(t *testing.T) {
+	for _, text := range []string{"не знаю", `{"explanation": "ok"}`, `{"scores": }`} {
+		if _, err := ParseResult(text); err == nil {
+			t.Errorf("Ответ %q должен быть отклонен", text)
+		}
+	}
+}
+
+func TestViolations(t *testing.T) {
+	classifier := NewClassifier("", map[string]float64{"Toxicity": 0.7, "spam": 0.9, "nsfw": 0}, "ru")
+
+	violations := classifier.Violations(Result{Scores: map[string]float64{
+		"toxicity": 0.75,
+		"spam":     0.95,
+		"nsfw":     0.5,
+		"violence": 1,
+	}})
+
+	want := []Violation{
+		{Category: "spam", Score: 0.95, Threshold: 0.9},
+		{Category: "toxicity", Score: 0.75, Threshold: 0.7},
+	}
+	if !reflect.DeepEqual(violations, want) {
+		t.Errorf("Нарушения: ожидалось %v, получено %v", want, violations)
+	}
+}
+
+func TestNewClassifierDefaults(t *testing.T) {
+	classifier := NewClassifier("", nil, "")
+
+	if got := classifier.Categories(); !reflect.DeepEqual(got, []string{"nsfw", "spam", "toxicity"}) {
+		t.Errorf("Неожиданные категории по умолчанию: %v", got)
+	}
+	if classifier.Thresholds["spam"] != DefaultThreshold {
+		t.Errorf("Неожиданный порог по умолчанию: %v", classifier.Thresholds["spam"])
+	}
+}
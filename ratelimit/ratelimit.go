@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// maxBuckets - количество корзин, после которого заполненные корзины удаляются
+const maxBuckets = 10000
+
+// Rule описывает ограничение: не более Capacity запросов за период Per для ключа Key.
+// Правило с Capacity <= 0 не ограничивает запросы.
+type Rule struct {
+	Key      string
+	Capacity int
+	Per      time.Duration
+}
+
+// bucket - корзина токенов, равномерно пополняемая до емкости
+type bucket struct {
+	tokens   float64
+	capacity float64
+	rate     float64 // Токенов в секунду
+	last     time.Time
+}
+
+// refill пополняет корзину за прошедшее время
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.last = now
+}
+
+// Limiter хранит корзины токенов по ключам
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// New создает ограничитель запросов
+func New() *Limiter {
+	return &Limiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow проверяет все правила и, если запрос разрешен каждым из них, списывает по токену.
+// При отказе токены не списываются, а возвращается время до появления свободного токена.
+func (l *Limiter) Allow(rules ...Rule) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	var wait time.Duration
+	var active []*bucket
+	for _, rule := range rules {
+		if rule.Capacity <= 0 || rule.Per <= 0 {
+			continue
+		}
+
+		b := l.bucket(rule, now)
+		b.refill(now)
+		if b.tokens < 1 {
+			if w := time.Duration((1 - b.tokens) / b.rate * float64(time.Second)); w > wait {
+				wait = w
+			}
+			continue
+		}
+		active = append(active, b)
+	}
+
+	if wait > 0 {
+		return false, wait
+	}
+	for _, b := range active {
+		b.tokens--
+	}
+	return true, 0
+}
+
+// Reset удаляет корзину ключа, восстанавливая полный лимит
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.buckets, key)
+}
+
+// bucket возвращает корзину правила, создавая заполненную корзину при первом обращении
+func (l *Limiter) bucket(rule Rule, now time.Time) *bucket {
+	capacity := float64(rule.Capacity)
+	rate := capacity / rule.Per.Seconds()
+
+	b, ok := l.buckets[rule.Key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[rule.Key] = b
+	}
+	// Настройки могли измениться с момента создания корзины
+	b.capacity = capacity
+	b.rate = rate
+	return b
+}
+
+// prune удаляет полностью пополненные корзины, чтобы память не росла с числом пользователей
+func (l *Limiter) prune(now time.Time) {
+	if len(l.buckets) < maxBuckets {
+		return
+	}
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= b.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestLimiter создает ограничитель с управляемыми часами
+func newTestLimiter() (*Limiter, *time.Time) {
+	now := time.Unix(0, 0)
+	l := New()
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiterRefills(t *testing.T) {
+	l, now := newTestLimiter()
+	rule := Rule{Key: "user", Capacity: 2, Per: time.Minute}
+
+	for n := 0; n < 2; n++ {
+		if ok, _ := l.Allow(rule); !ok {
+			t.Fatalf("Запрос %d должен быть разрешен", n+1)
+		}
+	}
+
+	ok, wait := l.Allow(rule)
+	if ok || wait != 30*time.Second {
+		t.Fatalf("Третий запрос должен быть отклонен с ожиданием 30s, получено: %v, %v", ok, wait)
+	}
+
+	*now = now.Add(30 * time.Second)
+	if ok, _ := l.Allow(rule); !ok {
+		t.Error("После пополнения запрос должен быть разрешен")
+	}
+}
+
+func TestLimiterDoesNotChargeOnReject(t *testing.T) {
+	l, _ := newTestLimiter()
+	user := Rule{Key: "user", Capacity: 5, Per: time.Minute}
+	global := Rule{Key: "global", Capacity: 1, Per: time.Minute}
+
+	if ok, _ := l.Allow(user, global); !ok {
+		t.Fatal("Первый запрос должен быть разрешен")
+	}
+	if ok, _ := l.Allow(user, global); ok {
+		t.Fatal("Глобальный лимит должен отклонить запрос")
+	}
+
+	// Отклоненный запрос не должен расходовать лимит пользователя
+	for n := 0; n < 4; n++ {
+		if ok, _ := l.Allow(user); !ok {
+			t.Fatalf("Запрос %d должен быть разрешен лимитом пользователя", n+1)
+		}
+	}
+}
+
+func TestLimiterDisabledRule(t *testing.T) {
+	l, _ := newTestLimiter()
+	for n := 0; n < 100; n++ {
+		if ok, _ := l.Allow(Rule{Key: "user", Capacity: 0, Per: time.Minute}); !ok {
+			t.Fatal("Правило с нулевой емкостью не должно ограничивать запросы")
+		}
+	}
+}
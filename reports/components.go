@@ -0,0 +1,96 @@
+package reports
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"discord-bot/localization"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ComponentPrefix - префикс идентификаторов кнопок и модальных окон репортов
+const ComponentPrefix = "report"
+
+// Действия над репортом. Идентификатор компонента имеет вид report:<действие>:<ID репорта>
+const (
+	ActionConfirm = "confirm" // Подтвердить репорт
+	ActionReject  = "reject"  // Отклонить репорт
+	ActionBan     = "ban"     // Забанить пользователя сразу
+	ActionInfo    = "info"    // Открыть окно запроса информации
+	ActionNote    = "note"    // Отправка окна запроса информации
+)
+
+// NoteInputID - идентификатор поля заметки в окне запроса информации
+const NoteInputID = "note"
+
+// ComponentID возвращает идентификатор компонента для действия над репортом
+func ComponentID(action string, reportID int64) string {
+	return fmt.Sprintf("%s:%s:%d", ComponentPrefix, action, reportID)
+}
+
+// ParseComponentID разбирает идентификатор компонента репорта
+func ParseComponentID(customID string) (action string, reportID int64, ok bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 3 || parts[0] != ComponentPrefix {
+		return "", 0, false
+	}
+
+	reportID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return parts[1], reportID, true
+}
+
+// ReviewComponents возвращает кнопки рассмотрения репорта
+func ReviewComponents(reportID int64) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    localization.GetText("report_button_confirm"),
+					Style:    discordgo.SuccessButton,
+					CustomID: ComponentID(ActionConfirm, reportID),
+				},
+				discordgo.Button{
+					Label:    localization.GetText("report_button_reject"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: ComponentID(ActionReject, reportID),
+				},
+				discordgo.Button{
+					Label:    localization.GetText("report_button_ban"),
+					Style:    discordgo.DangerButton,
+					CustomID: ComponentID(ActionBan, reportID),
+				},
+				discordgo.Button{
+					Label:    localization.GetText("report_button_info"),
+					Style:    discordgo.PrimaryButton,
+					CustomID: ComponentID(ActionInfo, reportID),
+				},
+			},
+		},
+	}
+}
+
+// InfoModal возвращает окно, в котором модератор описывает, что нужно уточнить у автора репорта
+func InfoModal(reportID int64) *discordgo.InteractionResponseData {
+	return &discordgo.InteractionResponseData{
+		CustomID: ComponentID(ActionNote, reportID),
+		Title:    localization.GetText("report_info_modal_title", reportID),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:  NoteInputID,
+						Label:     localization.GetText("report_info_note_label"),
+						Style:     discordgo.TextInputParagraph,
+						Required:  true,
+						MaxLength: 1000,
+					},
+				},
+			},
+		},
+	}
+}
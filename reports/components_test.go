@@ -0,0 +1,30 @@
+package reports
+
+import "testing"
+
+func TestComponentIDRoundTrip(t *testing.T) {
+	for _, action := range []string{ActionConfirm, ActionReject, ActionBan, ActionInfo, ActionNote} {
+		customID := ComponentID(action, 42)
+		gotAction, reportID, ok := ParseComponentID(customID)
+		if !ok || gotAction != action || reportID != 42 {
+			t.Errorf("ParseComponentID(%q) = %q, %d, %v, ожидалось %q, 42", customID, gotAction, reportID, ok, action)
+		}
+	}
+}
+
+func TestParseComponentIDRejectsForeignIDs(t *testing.T) {
+	tests := []string{
+		"",
+		"report",
+		"report:confirm",
+		"report:confirm:abc",
+		"report:confirm:1:2",
+		"ticket:confirm:1",
+		"help:page:2",
+	}
+	for _, customID := range tests {
+		if action, reportID, ok := ParseComponentID(customID); ok {
+			t.Errorf("ParseComponentID(%q) = %q, %d: ожидался отказ", customID, action, reportID)
+		}
+	}
+}
@@ -6,29 +6,39 @@ import (
 	"time"
 
 	"discord-bot/db"
+	"discord-bot/localization"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 // ReportMessage содержит информацию о сообщении репорта
 type ReportMessage struct {
-	ReportID       int64  // ID репорта в базе данных
-	MessageID      string // ID сообщения в Discord
-	ReportedUserID string // ID пользователя, на которого пожаловались
-	ReporterID     string // ID пользователя, который отправил жалобу
-	Reason         string // Причина жалобы
+	ReportID       int64     // ID репорта в базе данных
+	MessageID      string    // ID сообщения в Discord
+	GuildID        string    // ID сервера
+	ChannelID      string    // ID канала, в котором находится сообщение
+	ReportedUserID string    // ID пользователя, на которого пожаловались
+	ReporterID     string    // ID пользователя, который отправил жалобу
+	Reason         string    // Причина жалобы
+	CreatedAt      time.Time // Время создания репорта
 }
 
 var (
-	// reportMessages хранит информацию о сообщениях репортов
+	// reportMessages хранит нерассмотренные репорты по ID сообщения.
+	// Кэш заполняется из базы данных при запуске (LoadPendingReports)
 	reportMessages = make(map[string]ReportMessage)
 	reportMutex    sync.RWMutex
 )
 
 // CreateReport создает новый репорт и отправляет сообщение в канал модерации
-func CreateReport(s *discordgo.Session, channelID, reportedUserID, reporterID, reason string) (int64, error) {
+func CreateReport(s *discordgo.Session, guildID, channelID, reportedUserID, reporterID, reason string) (int64, error) {
 	// Добавляем репорт в базу данных
-	reportID, err := db.AddReport(reportedUserID, reporterID, reason)
+	reportID, err := db.RecordReport(db.Report{
+		GuildID:        guildID,
+		ReportedUserID: reportedUserID,
+		ReporterID:     reporterID,
+		Reason:         reason,
+	})
 	if err != nil {
 		return 0, fmt.Errorf("ошибка добавления репорта в базу данных: %w", err)
 	}
@@ -46,70 +56,152 @@ func CreateReport(s *discordgo.Session, channelID, reportedUserID, reporterID, r
 
 	// Создаем эмбед для репорта
 	embed := &discordgo.MessageEmbed{
-		Title: fmt.Sprintf("Репорт #%d", reportID),
+		Title: localization.GetText("report_embed_title", reportID),
 		Color: 0xFFA500, // Оранжевый цвет для непроверенных репортов
 		Fields: []*discordgo.MessageEmbedField{
 			{
-				Name:   "Пользователь",
+				Name:   localization.GetText("report_embed_user"),
 				Value:  fmt.Sprintf("%s#%s (%s)", reportedUser.Username, reportedUser.Discriminator, reportedUserID),
 				Inline: true,
 			},
 			{
-				Name:   "Отправитель",
+				Name:   localization.GetText("report_embed_reporter"),
 				Value:  fmt.Sprintf("%s#%s (%s)", reporter.Username, reporter.Discriminator, reporterID),
 				Inline: true,
 			},
 			{
-				Name:  "Причина",
+				Name:  localization.GetText("report_embed_reason"),
 				Value: reason,
 			},
 		},
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Используйте реакции ✅ для подтверждения или ❌ для отклонения",
+			Text: localization.GetText("report_embed_footer"),
 		},
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
 
-	// Отправляем сообщение
-	msg, err := s.ChannelMessageSendEmbed(channelID, embed)
+	// Отправляем сообщение с кнопками рассмотрения
+	msg, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: ReviewComponents(reportID),
+	})
 	if err != nil {
 		return 0, fmt.Errorf("ошибка отправки сообщения: %w", err)
 	}
 
-	// Добавляем реакции для модерации
-	if err := s.MessageReactionAdd(channelID, msg.ID, "✅"); err != nil {
-		fmt.Printf("Ошибка добавления реакции: %v\n", err)
-	}
-	if err := s.MessageReactionAdd(channelID, msg.ID, "❌"); err != nil {
-		fmt.Printf("Ошибка добавления реакции: %v\n", err)
-	}
-
-	// Сохраняем информацию о сообщении
-	reportMutex.Lock()
-	reportMessages[msg.ID] = ReportMessage{
+	// Сохраняем информацию о сообщении, чтобы кнопки работали и после перезапуска
+	report := ReportMessage{
 		ReportID:       reportID,
 		MessageID:      msg.ID,
+		GuildID:        guildID,
+		ChannelID:      channelID,
 		ReportedUserID: reportedUserID,
 		ReporterID:     reporterID,
 		Reason:         reason,
+		CreatedAt:      time.Now(),
+	}
+	if err := db.SaveReportMessage(toRecord(report, db.ReportStatusPending)); err != nil {
+		fmt.Printf("Ошибка сохранения сообщения репорта #%d: %v\n", reportID, err)
 	}
+
+	reportMutex.Lock()
+	reportMessages[msg.ID] = report
 	reportMutex.Unlock()
 
 	return reportID, nil
 }
 
-// GetReportMessage возвращает информацию о сообщении репорта по ID сообщения
+// LoadPendingReports загружает нерассмотренные репорты из базы данных после запуска бота
+func LoadPendingReports() (int, error) {
+	records, err := db.GetPendingReportMessages("")
+	if err != nil {
+		return 0, fmt.Errorf("ошибка загрузки нерассмотренных репортов: %w", err)
+	}
+
+	reportMutex.Lock()
+	defer reportMutex.Unlock()
+	for _, record := range records {
+		reportMessages[record.MessageID] = fromRecord(record)
+	}
+	return len(records), nil
+}
+
+// GetReportMessage возвращает нерассмотренный репорт по ID сообщения.
+// Если репорта нет в кэше, он ищется в базе данных
 func GetReportMessage(messageID string) (ReportMessage, bool) {
 	reportMutex.RLock()
-	defer reportMutex.RUnlock()
-
 	report, exists := reportMessages[messageID]
-	return report, exists
+	reportMutex.RUnlock()
+	if exists {
+		return report, true
+	}
+
+	record, err := db.GetReportMessage(messageID)
+	if err != nil {
+		fmt.Printf("Ошибка получения сообщения репорта: %v\n", err)
+		return ReportMessage{}, false
+	}
+	if record == nil || record.Status != db.ReportStatusPending {
+		return ReportMessage{}, false
+	}
+
+	report = fromRecord(*record)
+	reportMutex.Lock()
+	reportMessages[messageID] = report
+	reportMutex.Unlock()
+	return report, true
 }
 
-// RemoveReportMessage удаляет информацию о сообщении репорта
-func RemoveReportMessage(messageID string) {
+// CloseReportMessage сохраняет решение по репорту (db.ReportStatus*) и убирает его из нерассмотренных
+func CloseReportMessage(messageID string, status db.ReportStatus) {
 	reportMutex.Lock()
 	delete(reportMessages, messageID)
 	reportMutex.Unlock()
+
+	if err := db.SetReportMessageStatus(messageID, status); err != nil {
+		fmt.Printf("Ошибка сохранения статуса репорта: %v\n", err)
+	}
+}
+
+// OpenReports возвращает нерассмотренные репорты сервера в порядке создания
+func OpenReports(guildID string) ([]ReportMessage, error) {
+	records, err := db.GetPendingReportMessages(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения нерассмотренных репортов: %w", err)
+	}
+
+	open := make([]ReportMessage, 0, len(records))
+	for _, record := range records {
+		open = append(open, fromRecord(record))
+	}
+	return open, nil
+}
+
+// toRecord преобразует репорт в запись базы данных
+func toRecord(report ReportMessage, status db.ReportStatus) db.ReportMessage {
+	return db.ReportMessage{
+		MessageID:      report.MessageID,
+		ReportID:       report.ReportID,
+		GuildID:        report.GuildID,
+		ChannelID:      report.ChannelID,
+		ReportedUserID: report.ReportedUserID,
+		ReporterID:     report.ReporterID,
+		Reason:         report.Reason,
+		Status:         status,
+		Timestamp:      report.CreatedAt,
+	}
+}
+
+// fromRecord восстанавливает репорт из записи базы данных
+func fromRecord(record db.ReportMessage) ReportMessage {
+	return ReportMessage{
+		ReportID:       record.ReportID,
+		MessageID:      record.MessageID,
+		GuildID:        record.GuildID,
+		ChannelID:      record.ChannelID,
+		ReportedUserID: record.ReportedUserID,
+		ReporterID:     record.ReporterID,
+		Reason:         record.Reason,
+		CreatedAt:      record.Timestamp,
+	}
 }
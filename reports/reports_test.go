@@ -0,0 +1,108 @@
+package reports
+
+import (
+	"path/filepath"
+	"testing"
+
+	"discord-bot/db"
+)
+
+// useTestDB подключает временную базу SQLite и очищает кэш нерассмотренных репортов
+func useTestDB(t *testing.T) {
+	t.Helper()
+	config := db.DatabaseConfig{Type: "sqlite", Database: filepath.Join(t.TempDir(), "bot.db")}
+	if err := db.Initialize(config); err != nil {
+		t.Fatalf("Не удалось открыть тестовую базу данных: %v", err)
+	}
+	resetCache()
+	t.Cleanup(func() {
+		db.Close()
+		db.CurrentProvider = nil
+		resetCache()
+	})
+}
+
+// resetCache очищает кэш репортов, как при перезапуске бота
+func resetCache() {
+	reportMutex.Lock()
+	reportMessages = make(map[string]ReportMessage)
+	reportMutex.Unlock()
+}
+
+// saveReport сохраняет сообщение нерассмотренного репорта в базе данных
+func saveReport(t *testing.T, messageID string, reportID int64) {
+	t.Helper()
+	err := db.SaveReportMessage(db.ReportMessage{
+		MessageID:      messageID,
+		ReportID:       reportID,
+		GuildID:        "guild",
+		ChannelID:      "channel",
+		ReportedUserID: "user",
+		ReporterID:     "reporter",
+		Reason:         "спам",
+		Status:         db.ReportStatusPending,
+	})
+	if err != nil {
+		t.Fatalf("Не удалось сохранить сообщение репорта: %v", err)
+	}
+}
+
+func TestLoadPendingReportsAfterRestart(t *testing.T) {
+	useTestDB(t)
+	saveReport(t, "open", 1)
+	saveReport(t, "closed", 2)
+	CloseReportMessage("closed", db.ReportStatusConfirmed)
+
+	resetCache()
+	count, err := LoadPendingReports()
+	if err != nil || count != 1 {
+		t.Fatalf("Ожидался один нерассмотренный репорт, получено %d, %v", count, err)
+	}
+
+	report, ok := GetReportMessage("open")
+	if !ok || report.ReportID != 1 || report.GuildID != "guild" || report.Reason != "спам" {
+		t.Errorf("Нерассмотренный репорт должен восстанавливаться после перезапуска: %+v, %v", report, ok)
+	}
+	if _, ok := GetReportMessage("closed"); ok {
+		t.Error("Рассмотренный репорт не должен восстанавливаться")
+	}
+}
+
+func TestGetReportMessageFallsBackToDB(t *testing.T) {
+	useTestDB(t)
+	saveReport(t, "message", 7)
+
+	report, ok := GetReportMessage("message")
+	if !ok || report.ReportID != 7 {
+		t.Fatalf("Репорт, которого нет в кэше, должен находиться в базе данных: %+v, %v", report, ok)
+	}
+
+	reportMutex.RLock()
+	_, cached := reportMessages["message"]
+	reportMutex.RUnlock()
+	if !cached {
+		t.Error("Найденный в базе данных репорт должен попадать в кэш")
+	}
+
+	if _, ok := GetReportMessage("unknown"); ok {
+		t.Error("Неизвестное сообщение не должно считаться репортом")
+	}
+}
+
+func TestCloseReportMessage(t *testing.T) {
+	useTestDB(t)
+	saveReport(t, "message", 3)
+	if _, ok := GetReportMessage("message"); !ok {
+		t.Fatal("Репорт должен быть нерассмотренным до закрытия")
+	}
+
+	CloseReportMessage("message", db.ReportStatusRejected)
+	if _, ok := GetReportMessage("message"); ok {
+		t.Error("Закрытый репорт не должен находиться ни в кэше, ни в базе данных")
+	}
+
+	record, err := db.GetReportMessage("message")
+	if err != nil || record == nil || record.Status != db.ReportStatusRejected {
+		t.Errorf("Решение по репорту должно сохраняться в базе данных: %+v, %v", record, err)
+	}
+}
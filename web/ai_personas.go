@@ -0,0 +1,153 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"discord-bot/ai"
+	"discord-bot/db"
+
+	"github.com/gorilla/mux"
+)
+
+// Ограничения параметров персоны AI
+const (
+	maxPersonaNameLength   = 32
+	maxPersonaPromptLength = 8000
+	maxPersonaTemperature  = 2.0
+)
+
+// AIPersona представляет персону AI сервера
+type AIPersona struct {
+	Name            string   `json:"name"`
+	SystemPrompt    string   `json:"system_prompt"`
+	Provider        string   `json:"provider,omitempty"`
+	Temperature     *float64 `json:"temperature,omitempty"`
+	AllowedChannels []string `json:"allowed_channels"`
+	IsDefault       bool     `json:"is_default"`
+}
+
+// handleGetAIPersonas возвращает персоны AI сервера
+func (api *APIServer) handleGetAIPersonas(w http.ResponseWriter, r *http.Request) {
+	guildID := mux.Vars(r)["guildID"]
+
+	personas, err := db.GetAIPersonas(guildID)
+	if err != nil {
+		http.Error(w, "Ошибка получения персон AI: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]AIPersona, 0, len(personas))
+	for _, p := range personas {
+		result = append(result, AIPersona{
+			Name:            p.Name,
+			SystemPrompt:    p.SystemPrompt,
+			Provider:        p.Provider,
+			Temperature:     p.Temperature,
+			AllowedChannels: append([]string{}, p.AllowedChannels...),
+			IsDefault:       p.IsDefault,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleSaveAIPersona создает или обновляет персону AI сервера
+func (api *APIServer) handleSaveAIPersona(w http.ResponseWriter, r *http.Request) {
+	guildID := mux.Vars(r)["guildID"]
+
+	var persona AIPersona
+	if err := json.NewDecoder(r.Body).Decode(&persona); err != nil {
+		http.Error(w, "Ошибка декодирования JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	persona.Name = strings.ToLower(strings.TrimSpace(persona.Name))
+	persona.SystemPrompt = strings.TrimSpace(persona.SystemPrompt)
+	if err := validateAIPersona(persona); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := db.SaveAIPersona(db.AIPersona{
+		GuildID:         guildID,
+		Name:            persona.Name,
+		SystemPrompt:    persona.SystemPrompt,
+		Provider:        persona.Provider,
+		Temperature:     persona.Temperature,
+		AllowedChannels: persona.AllowedChannels,
+		IsDefault:       persona.IsDefault,
+	})
+	if err != nil {
+		http.Error(w, "Ошибка сохранения персоны AI: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// handleDeleteAIPersona удаляет персону AI сервера
+func (api *APIServer) handleDeleteAIPersona(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	guildID, name := vars["guildID"], strings.ToLower(vars["name"])
+
+	persona, err := db.GetAIPersona(guildID, name)
+	if err != nil {
+		http.Error(w, "Ошибка получения персоны AI: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if persona == nil {
+		http.Error(w, "Персона AI не найдена", http.StatusNotFound)
+		return
+	}
+
+	if err := db.DeleteAIPersona(guildID, name); err != nil {
+		http.Error(w, "Ошибка удаления персоны AI: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// validateAIPersona проверяет параметры персоны перед сохранением
+func validateAIPersona(persona AIPersona) error {
+	nameLength := utf8.RuneCountInString(persona.Name)
+	if nameLength == 0 || nameLength > maxPersonaNameLength {
+		return fmt.Errorf("имя персоны должно содержать от 1 до %d символов", maxPersonaNameLength)
+	}
+	if strings.IndexFunc(persona.Name, unicode.IsSpace) >= 0 || strings.Contains(persona.Name, "/") {
+		return fmt.Errorf("имя персоны не должно содержать пробелов и символа /")
+	}
+
+	if persona.SystemPrompt == "" {
+		return fmt.Errorf("системный промпт персоны не может быть пустым")
+	}
+	if utf8.RuneCountInString(persona.SystemPrompt) > maxPersonaPromptLength {
+		return fmt.Errorf("системный промпт персоны не должен превышать %d символов", maxPersonaPromptLength)
+	}
+
+	if persona.Provider != "" {
+		if _, err := ai.GetProvider(persona.Provider); err != nil {
+			return err
+		}
+	}
+
+	if t := persona.Temperature; t != nil && (*t < 0 || *t > maxPersonaTemperature) {
+		return fmt.Errorf("температура должна быть в диапазоне от 0 до %.0f", maxPersonaTemperature)
+	}
+
+	for _, channelID := range persona.AllowedChannels {
+		if channelID == "" || strings.IndexFunc(channelID, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0 {
+			return fmt.Errorf("неверный ID канала: '%s'", channelID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"discord-bot/config"
+	"discord-bot/db"
+)
+
+// Периоды статистики использования AI по умолчанию
+const (
+	defaultAIUsageDays   = 30
+	defaultAIUsageMonths = 12
+	maxAIUsageDays       = 366
+	maxAIUsageMonths     = 36
+)
+
+// AIUsageEntry представляет использование провайдера AI за период
+type AIUsageEntry struct {
+	Period           string  `json:"period"`
+	Provider         string  `json:"provider"`
+	Requests         int64   `json:"requests"`
+	Failures         int64   `json:"failures"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	AvgLatencyMs     int64   `json:"avg_latency_ms"`
+	EstimatedCost    float64 `json:"estimated_cost"`
+	totalLatencyMs   int64
+}
+
+// AIUsageReport представляет статистику использования AI по дням и месяцам
+type AIUsageReport struct {
+	Currency string          `json:"currency"`
+	Daily    []*AIUsageEntry `json:"daily"`
+	Monthly  []*AIUsageEntry `json:"monthly"`
+}
+
+// handleGetAIUsage возвращает статистику использования AI провайдеров и оценку расходов.
+// Параметры days и months задают глубину дневной и месячной статистики.
+func (api *APIServer) handleGetAIUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	days, ok := usagePeriod(r, "days", defaultAIUsageDays, maxAIUsageDays)
+	if !ok {
+		http.Error(w, "Неверное значение параметра days", http.StatusBadRequest)
+		return
+	}
+	months, ok := usagePeriod(r, "months", defaultAIUsageMonths, maxAIUsageMonths)
+	if !ok {
+		http.Error(w, "Неверное значение параметра months", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	daySince := now.AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+	monthSince := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+
+	since := monthSince
+	if daySince < since {
+		since = daySince
+	}
+
+	summaries, err := db.GetAIUsageSummary(since)
+	if err != nil {
+		http.Error(w, "Ошибка получения статистики AI: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report := buildAIUsageReport(summaries, daySince, monthSince, api.config.AIProviders)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// usagePeriod читает количество дней или месяцев из параметров запроса.
+// Значения больше max ограничиваются, отрицательные и нечисловые значения считаются ошибкой.
+func usagePeriod(r *http.Request, name string, def, max int) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, true
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	if n > max {
+		n = max
+	}
+	return n, true
+}
+
+// buildAIUsageReport группирует дневную статистику по дням и месяцам и оценивает расходы
+// по ценам провайдеров из конфигурации
+func buildAIUsageReport(summaries []db.AIUsageSummary, daySince, monthSince string, prices map[string]config.AIProviderConfig) AIUsageReport {
+	daily := make(map[string]*AIUsageEntry)
+	monthly := make(map[string]*AIUsageEntry)
+
+	for _, s := range summaries {
+		if s.Day >= daySince {
+			addAIUsage(daily, s.Day, s)
+		}
+		if s.Day >= monthSince {
+			addAIUsage(monthly, s.Day[:7], s)
+		}
+	}
+
+	return AIUsageReport{
+		Currency: "USD",
+		Daily:    finishAIUsage(daily, prices),
+		Monthly:  finishAIUsage(monthly, prices),
+	}
+}
+
+// addAIUsage добавляет дневную статистику провайдера к итогам периода
+func addAIUsage(entries map[string]*AIUsageEntry, period string, s db.AIUsageSummary) {
+	key := period + "/" + s.Provider
+	entry, ok := entries[key]
+	if !ok {
+		entry = &AIUsageEntry{Period: period, Provider: s.Provider}
+		entries[key] = entry
+	}
+
+	entry.Requests += s.Requests
+	entry.Failures += s.Failures
+	entry.PromptTokens += s.PromptTokens
+	entry.CompletionTokens += s.CompletionTokens
+	entry.totalLatencyMs += s.TotalLatencyMs
+}
+
+// finishAIUsage рассчитывает средние значения и стоимость и сортирует итоги по периоду и провайдеру
+func finishAIUsage(entries map[string]*AIUsageEntry, prices map[string]config.AIProviderConfig) []*AIUsageEntry {
+	result := make([]*AIUsageEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Requests > 0 {
+			entry.AvgLatencyMs = entry.totalLatencyMs / entry.Requests
+		}
+
+		price := prices[entry.Provider]
+		entry.EstimatedCost = (float64(entry.PromptTokens)*price.PromptPrice +
+			float64(entry.CompletionTokens)*price.CompletionPrice) / 1_000_000
+
+		result = append(result, entry)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Period != result[j].Period {
+			return result[i].Period < result[j].Period
+		}
+		return result[i].Provider < result[j].Provider
+	})
+	return result
+}
@@ -7,11 +7,11 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"strings"
 	"sync"
 	"time"
 
 	"discord-bot/config"
+	"discord-bot/handlers"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 )
@@ -48,11 +48,14 @@ type BotStats struct {
 
 // Command представляет команду бота
 type Command struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Usage       string `json:"usage"`
-	Category    string `json:"category"`
-	Enabled     bool   `json:"enabled"`
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases"`
+	Description string   `json:"description"`
+	Usage       string   `json:"usage"`
+	Category    string   `json:"category"`
+	Permission  string   `json:"permission"` // everyone или moderator
+	Slash       bool     `json:"slash"`      // Команда доступна как слеш-команда
+	Enabled     bool     `json:"enabled"`
 }
 
 // NewCSRFManager создает новый менеджер CSRF токенов
@@ -202,11 +205,15 @@ func (api *APIServer) Start() error {
 	r.HandleFunc("/api/config", api.handleSaveConfig).Methods("POST")
 	r.HandleFunc("/api/stats", api.handleGetStats).Methods("GET")
 	r.HandleFunc("/api/commands", api.handleGetCommands).Methods("GET")
-	r.HandleFunc("/api/commands", api.handleUpdateCommands).Methods("POST")
+	r.HandleFunc("/api/commands", api.handleUpdateCommand).Methods("POST")
+	r.HandleFunc("/api/ai/usage", AuthMiddleware(api.handleGetAIUsage)).Methods("GET")
+	r.HandleFunc("/api/guilds/{guildID}/ai/personas", AuthMiddleware(api.handleGetAIPersonas)).Methods("GET")
+	r.HandleFunc("/api/guilds/{guildID}/ai/personas", AuthMiddleware(api.handleSaveAIPersona)).Methods("POST")
+	r.HandleFunc("/api/guilds/{guildID}/ai/personas/{name}", AuthMiddleware(api.handleDeleteAIPersona)).Methods("DELETE")
 
 	// Регистрируем обработчики аутентификации
-	r.HandleFunc("/api/login", handleLogin).Methods("POST")
-	r.HandleFunc("/api/verify-totp", handleVerifyTOTP).Methods("POST")
+	r.HandleFunc("/api/login", api.handleLogin).Methods("POST")
+	r.HandleFunc("/api/verify-totp", api.handleVerifyTOTP).Methods("POST")
 
 	// Обслуживаем фронтенд
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir("web/frontend/build")))
@@ -331,7 +338,7 @@ func (api *APIServer) handleGetStats(w http.ResponseWriter, r *http.Request) {
 		Servers:     15,
 		Users:       1250,
 		Channels:    87,
-		Commands:    42,
+		Commands:    len(handlers.Commands()),
 		Uptime:      "3 дня 7 часов",
 		MemoryUsage: "128 MB",
 	}
@@ -347,44 +354,20 @@ func (api *APIServer) handleGetCommands(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// В реальном приложении здесь будет получение списка команд из бота
-	// Пока используем тестовые данные
-	commands := []Command{
-		{
-			Name:        "help",
-			Description: "Показывает список доступных команд",
-			Usage:       "!help [команда]",
-			Category:    "Основные",
-			Enabled:     true,
-		},
-		{
-			Name:        "ping",
-			Description: "Проверяет задержку бота",
-			Usage:       "!ping",
-			Category:    "Утилиты",
-			Enabled:     true,
-		},
-		{
-			Name:        "ban",
-			Description: "Банит пользователя на сервере",
-			Usage:       "!ban @пользователь [причина]",
-			Category:    "Модерация",
-			Enabled:     true,
-		},
-		{
-			Name:        "play",
-			Description: "Воспроизводит музыку в голосовом канале",
-			Usage:       "!play [ссылка или название]",
-			Category:    "Музыка",
-			Enabled:     true,
-		},
-		{
-			Name:        "stats",
-			Description: "Показывает статистику бота",
-			Usage:       "!stats",
-			Category:    "Информация",
+	// Список строится из реестра команд бота
+	registered := handlers.Commands()
+	commands := make([]Command, 0, len(registered))
+	for _, cmd := range registered {
+		commands = append(commands, Command{
+			Name:        cmd.Name,
+			Aliases:     append([]string{}, cmd.Aliases...),
+			Description: cmd.Description(),
+			Usage:       cmd.Usage(api.config.Prefix),
+			Category:    cmd.Category.Name(),
+			Permission:  cmd.Permission.String(),
+			Slash:       cmd.Slash != nil,
 			Enabled:     true,
-		},
+		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
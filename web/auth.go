@@ -9,7 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/pquerna/otp/totp"
+	"github.com/rs/cors"
 )
 
 // LoginRequest представляет запрос на вход
@@ -474,7 +476,11 @@ func (api *APIServer) UpdateAPIServerForAuth() {
 	r.HandleFunc("/api/config", AuthMiddleware(api.handleSaveConfig)).Methods("POST")
 	r.HandleFunc("/api/stats", AuthMiddleware(api.handleGetStats)).Methods("GET")
 	r.HandleFunc("/api/commands", AuthMiddleware(api.handleGetCommands)).Methods("GET")
-	r.HandleFunc("/api/commands", AuthMiddleware(api.handleUpdateCommands)).Methods("POST")
+	r.HandleFunc("/api/commands", AuthMiddleware(api.handleUpdateCommand)).Methods("POST")
+	r.HandleFunc("/api/ai/usage", AuthMiddleware(api.handleGetAIUsage)).Methods("GET")
+	r.HandleFunc("/api/guilds/{guildID}/ai/personas", AuthMiddleware(api.handleGetAIPersonas)).Methods("GET")
+	r.HandleFunc("/api/guilds/{guildID}/ai/personas", AuthMiddleware(api.handleSaveAIPersona)).Methods("POST")
+	r.HandleFunc("/api/guilds/{guildID}/ai/personas/{name}", AuthMiddleware(api.handleDeleteAIPersona)).Methods("DELETE")
 
 	// Обслуживаем фронтенд
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir("web/frontend/build")))
@@ -87,7 +87,7 @@ func (ws *WebServer) Start() error {
 
 	fmt.Println("║  ⚙️  Откройте любую из этих ссылок для настройки бота   ║")
 	fmt.Println("║                                                        ║")
-	fmt.Println("╚════════════════════════════════════════════════════════════╝\n")
+	fmt.Print("╚════════════════════════════════════════════════════════════╝\n\n")
 
 	// Запускаем основной сервер в отдельной горутине
 	go func() {